@@ -0,0 +1,384 @@
+// Package bincodec 把结构体编码为紧凑的二进制格式，布局规则和 data/struct.go 中 mem() 讲的一致
+//
+// 默认按内存布局对齐：每个字段的编码位置按其对齐要求填充，结构体末尾补齐到结构体的对齐，
+// 全部是定长字段时编码结果和内存中的字节完全相同（v1 为 8 字节：a b ? ? c c c c）；
+// Packed 去掉所有填充（v1 为 6 字节），和 encoding/binary 的格式相同。
+//
+// 字段标签 bin 控制顺序和宽度：
+//
+//	A uint64 `bin:"order=2,size=4"` // 第 2 个编码，只占 4 字节，超出范围时编码报错
+//	S string `bin:"size=16"`         // 定长 16 字节，不足补 0，解码时去掉末尾的 0
+//	B []byte `bin:"prefix=1"`        // 变长，1 字节的长度前缀（默认 4 字节）
+//	X int    `bin:"-"`               // 不编码
+//
+// 一个字段指定了 order，所有字段都要指定。int、uint、uintptr 按 8 字节编码，与平台无关。
+// 定长的切片解码后长度总是 size，不足的部分为零值。
+// 全部是定长字段、按内存布局对齐且字节序和本机相同时，View 直接把缓冲区当作 *T 使用，不复制
+package bincodec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+var (
+	ErrShort    = errors.New("bincodec: data too short")
+	ErrRange    = errors.New("bincodec: value out of range")
+	ErrZeroCopy = errors.New("bincodec: layout differs from memory")
+)
+
+// Config 编码选项
+type Config struct {
+	Order  binary.ByteOrder // 默认 binary.LittleEndian
+	Packed bool             // 不填充对齐
+}
+
+type kind uint8
+
+const (
+	kBool kind = iota
+	kInt
+	kUint
+	kFloat
+	kString
+	kSlice
+	kArray
+	kStruct
+)
+
+// node 一个类型的编码方式
+type node struct {
+	kind   kind
+	typ    reflect.Type
+	width  int // 数值的编码宽度；string、slice 的定长长度，0 为变长
+	prefix int // 变长的长度前缀宽度
+	align  int // 编码时的对齐，Packed 时为 1
+	n      int // 数组长度
+	elem   *node
+	fields []member
+	fixed  int // 定长的编码长度，变长为 -1
+}
+
+// member 结构体中的一个字段
+type member struct {
+	name  string
+	off   uintptr // 内存中的偏移
+	enc   int     // 定长结构体中编码的相对偏移
+	blank bool    // _ 字段按填充处理，编码为 0，解码时跳过
+	node  *node
+}
+
+// Codec T 的编解码器，创建时分析布局，可以并发使用
+type Codec[T any] struct {
+	cfg      Config
+	order    binary.AppendByteOrder
+	root     *node
+	zeroCopy bool
+	bools    []uintptr // View 需要检查的 bool 字段偏移
+	pads     [][2]int  // 内存中的填充和 _ 字段，直接复制内存编码时清零
+}
+
+// New 分析 T 的布局，T 必须是结构体，不能包含指针、map、接口等字段
+func New[T any](cfg Config) (*Codec[T], error) {
+	if cfg.Order == nil {
+		cfg.Order = binary.LittleEndian
+	}
+	var zero T
+	t := reflect.TypeOf(&zero).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("bincodec: %s is not a struct", t)
+	}
+	c := &Codec[T]{cfg: cfg}
+	var ok bool
+	if c.order, ok = cfg.Order.(binary.AppendByteOrder); !ok {
+		c.order = appendOrder{cfg.Order}
+	}
+	root, err := c.build(t, tag{})
+	if err != nil {
+		return nil, err
+	}
+	c.root = root
+	if !cfg.Packed && cfg.Order == nativeOrder() && root.fixed == int(t.Size()) {
+		used := make([]bool, root.fixed)
+		c.zeroCopy = c.sameLayout(root, 0, used)
+		for i := 0; c.zeroCopy && i < len(used); i++ {
+			if j := i; !used[i] {
+				for i < len(used) && !used[i] {
+					i++
+				}
+				c.pads = append(c.pads, [2]int{j, i})
+			}
+		}
+	}
+	return c, nil
+}
+
+// Size 定长结构体的编码长度，变长时为 -1
+func (c *Codec[T]) Size() int {
+	return c.root.fixed
+}
+
+// ZeroCopy View 是否可用
+func (c *Codec[T]) ZeroCopy() bool {
+	return c.zeroCopy
+}
+
+// tag 字段标签
+type tag struct {
+	skip   bool
+	order  int // 0 为未指定
+	size   int
+	prefix int
+}
+
+func parseTag(s string) (tag, error) {
+	var t tag
+	if s == "-" {
+		t.skip = true
+		return t, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		if kv == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		n, err := strconv.Atoi(v)
+		if !ok || err != nil || n <= 0 {
+			return t, fmt.Errorf("bincodec: bad tag %q", s)
+		}
+		switch k {
+		case "order":
+			t.order = n
+		case "size":
+			t.size = n
+		case "prefix":
+			if n != 1 && n != 2 && n != 4 && n != 8 {
+				return t, fmt.Errorf("bincodec: prefix must be 1, 2, 4 or 8 in %q", s)
+			}
+			t.prefix = n
+		default:
+			return t, fmt.Errorf("bincodec: unknown tag key %q in %q", k, s)
+		}
+	}
+	return t, nil
+}
+
+func (c *Codec[T]) alignOf(a int) int {
+	if c.cfg.Packed {
+		return 1
+	}
+	return a
+}
+
+// build 生成类型 t 的编码方式，tg 为字段的标签
+func (c *Codec[T]) build(t reflect.Type, tg tag) (*node, error) {
+	n := &node{typ: t, align: c.alignOf(t.Align()), fixed: -1}
+	switch t.Kind() {
+	case reflect.Bool:
+		n.kind, n.width = kBool, 1
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n.kind, n.width = kInt, int(t.Size())
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n.kind, n.width = kUint, int(t.Size())
+	case reflect.Int, reflect.Uint, reflect.Uintptr:
+		// 宽度和平台无关，都按 8 字节编码；32 位平台上解码时检查范围，也不能直接复制内存
+		n.kind, n.width, n.align = kInt, 8, c.alignOf(8)
+		if t.Kind() != reflect.Int {
+			n.kind = kUint
+		}
+	case reflect.Float32, reflect.Float64:
+		n.kind, n.width = kFloat, int(t.Size())
+	case reflect.String, reflect.Slice:
+		n.kind, n.width, n.prefix = kString, tg.size, tg.prefix
+		if n.prefix == 0 {
+			n.prefix = 4
+		}
+		if t.Kind() == reflect.Slice {
+			n.kind = kSlice
+			elem, err := c.build(t.Elem(), tag{})
+			if err != nil {
+				return nil, err
+			}
+			if elem.fixed < 0 && tg.size > 0 {
+				return nil, fmt.Errorf("bincodec: size on %s needs fixed-size elements", t)
+			}
+			n.elem = elem
+		}
+		n.align = 1
+		switch {
+		case n.width > 0 && n.kind == kString:
+			n.fixed = n.width
+		case n.width > 0:
+			n.align = n.elem.align
+			n.fixed = n.width * padded(n.elem.fixed, n.elem.align)
+		default:
+			n.align = c.alignOf(n.prefix) // 长度前缀按其宽度对齐
+		}
+		return n, nil
+	case reflect.Array:
+		elem, err := c.build(t.Elem(), tag{})
+		if err != nil {
+			return nil, err
+		}
+		n.kind, n.n, n.elem = kArray, t.Len(), elem
+		if elem.fixed >= 0 {
+			n.fixed = n.n * padded(elem.fixed, elem.align)
+		}
+		return n, nil
+	case reflect.Struct:
+		return c.buildStruct(n, t)
+	default:
+		return nil, fmt.Errorf("bincodec: unsupported type %s", t)
+	}
+
+	if tg.size > 0 && n.kind != kFloat && n.kind != kBool {
+		if tg.size != 1 && tg.size != 2 && tg.size != 4 && tg.size != 8 {
+			return nil, fmt.Errorf("bincodec: size of %s must be 1, 2, 4 or 8", t)
+		}
+		n.width = tg.size
+		n.align = c.alignOf(tg.size)
+	} else if tg.size > 0 {
+		return nil, fmt.Errorf("bincodec: size is not allowed on %s", t)
+	}
+	n.fixed = n.width
+	return n, nil
+}
+
+func (c *Codec[T]) buildStruct(n *node, t reflect.Type) (*node, error) {
+	n.kind = kStruct
+	ordered := 0
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tg, err := parseTag(f.Tag.Get("bin"))
+		if err != nil {
+			return nil, fmt.Errorf("%w (field %s.%s)", err, t, f.Name)
+		}
+		if tg.skip {
+			continue
+		}
+		fn, err := c.build(f.Type, tg)
+		if err != nil {
+			return nil, fmt.Errorf("%w (field %s.%s)", err, t, f.Name)
+		}
+		if f.Name == "_" && fn.fixed < 0 {
+			return nil, fmt.Errorf("bincodec: blank field %s in %s must be fixed-size", f.Type, t)
+		}
+		if tg.order > 0 {
+			ordered++
+		}
+		n.fields = append(n.fields, member{name: f.Name, off: f.Offset, enc: tg.order, blank: f.Name == "_", node: fn})
+	}
+	if ordered > 0 {
+		if ordered != len(n.fields) {
+			return nil, fmt.Errorf("bincodec: %s: order must be set on all fields or none", t)
+		}
+		sort.SliceStable(n.fields, func(i, j int) bool { return n.fields[i].enc < n.fields[j].enc })
+		for i := 1; i < len(n.fields); i++ {
+			if n.fields[i].enc == n.fields[i-1].enc {
+				return nil, fmt.Errorf("bincodec: %s: duplicate order %d", t, n.fields[i].enc)
+			}
+		}
+	}
+
+	// 对齐取字段的最大对齐；全部定长时计算每个字段的编码偏移
+	n.align = 1
+	pos, fixed := 0, true
+	for i := range n.fields {
+		fn := n.fields[i].node
+		if fn.align > n.align {
+			n.align = fn.align
+		}
+		if fn.fixed < 0 {
+			fixed = false
+		}
+		pos = padded(pos, fn.align)
+		n.fields[i].enc = pos
+		pos += fn.fixed
+	}
+	if fixed {
+		n.fixed = padded(pos, n.align)
+	}
+	return n, nil
+}
+
+// sameLayout 编码和内存布局完全相同，off 为 n 在 T 中的内存偏移，used 标记数据占用的字节
+func (c *Codec[T]) sameLayout(n *node, off uintptr, used []bool) bool {
+	if n.fixed != int(n.typ.Size()) {
+		return false
+	}
+	switch n.kind {
+	case kBool, kInt, kUint, kFloat:
+		if n.kind == kBool {
+			c.bools = append(c.bools, off)
+		}
+		for i := 0; i < n.width; i++ {
+			used[int(off)+i] = true
+		}
+	case kArray:
+		for i := 0; i < n.n; i++ {
+			if !c.sameLayout(n.elem, off+uintptr(i)*n.elem.typ.Size(), used) {
+				return false
+			}
+		}
+	case kStruct:
+		if len(n.fields) != n.typ.NumField() {
+			return false // 有跳过的字段
+		}
+		for _, m := range n.fields {
+			if uintptr(m.enc) != m.off {
+				return false
+			}
+			if m.blank {
+				continue // 按填充处理
+			}
+			if !c.sameLayout(m.node, off+m.off, used) {
+				return false
+			}
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// padded n 向上对齐到 a
+func padded(n, a int) int {
+	return (n + a - 1) / a * a
+}
+
+// appendOrder 自定义的 ByteOrder 没有 Append 方法时使用
+type appendOrder struct {
+	binary.ByteOrder
+}
+
+func (o appendOrder) AppendUint16(b []byte, x uint16) []byte {
+	var s [2]byte
+	o.PutUint16(s[:], x)
+	return append(b, s[:]...)
+}
+
+func (o appendOrder) AppendUint32(b []byte, x uint32) []byte {
+	var s [4]byte
+	o.PutUint32(s[:], x)
+	return append(b, s[:]...)
+}
+
+func (o appendOrder) AppendUint64(b []byte, x uint64) []byte {
+	var s [8]byte
+	o.PutUint64(s[:], x)
+	return append(b, s[:]...)
+}
+
+func nativeOrder() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}
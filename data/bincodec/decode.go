@@ -0,0 +1,251 @@
+package bincodec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"unsafe"
+
+	"yuhen/data/memview"
+)
+
+// Unmarshal 从 data 解码到 v，返回读取的字节数；长度为 0 的变长切片解码为 nil
+func (c *Codec[T]) Unmarshal(data []byte, v *T) (int, error) {
+	if c.zeroCopy {
+		if err := c.check(data); err != nil {
+			return 0, err
+		}
+		copy(unsafe.Slice((*byte)(unsafe.Pointer(v)), c.root.fixed), data)
+		return c.root.fixed, nil
+	}
+	d := decoder{order: c.cfg.Order, data: data}
+	if err := d.decode(c.root, unsafe.Pointer(v)); err != nil {
+		return d.pos, err
+	}
+	return d.pos, nil
+}
+
+// View 零复制解码：data 本身就是 T 的内存，修改 *T 即修改 data
+// 要求 ZeroCopy()，data 的起始地址按 T 对齐，其中的 bool 只能是 0 或 1
+func (c *Codec[T]) View(data []byte) (*T, error) {
+	if !c.zeroCopy {
+		return nil, ErrZeroCopy
+	}
+	// 和 memview.View.Ptr 的检查相同；New 已经排除了指针，不必每次用反射检查
+	p := unsafe.Pointer(unsafe.SliceData(data))
+	if a := c.root.typ.Align(); uintptr(p)%uintptr(a) != 0 {
+		return nil, fmt.Errorf("%w: %p is not %d-byte aligned", memview.ErrAlign, p, a)
+	}
+	if err := c.check(data); err != nil {
+		return nil, err
+	}
+	return (*T)(p), nil
+}
+
+// check 定长数据的长度和其中的 bool
+func (c *Codec[T]) check(data []byte) error {
+	if len(data) < c.root.fixed {
+		return fmt.Errorf("%w: %d bytes, need %d", memview.ErrBounds, len(data), c.root.fixed)
+	}
+	for _, off := range c.bools {
+		if data[off] > 1 {
+			return fmt.Errorf("bincodec: invalid bool %d at offset %d", data[off], off)
+		}
+	}
+	return nil
+}
+
+type decoder struct {
+	order binary.ByteOrder
+	data  []byte
+	pos   int
+}
+
+func (d *decoder) align(a int) error {
+	pos := padded(d.pos, a)
+	if pos > len(d.data) {
+		return ErrShort
+	}
+	d.pos = pos
+	return nil
+}
+
+func (d *decoder) take(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data)-d.pos {
+		return nil, fmt.Errorf("%w: need %d bytes at offset %d, have %d", ErrShort, n, d.pos, len(d.data)-d.pos)
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) uint(w int) (uint64, error) {
+	b, err := d.take(w)
+	if err != nil {
+		return 0, err
+	}
+	switch w {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(d.order.Uint16(b)), nil
+	case 4:
+		return uint64(d.order.Uint32(b)), nil
+	}
+	return d.order.Uint64(b), nil
+}
+
+// length 长度前缀，按每个元素至少 least 字节检查剩余数据，避免伪造的长度导致巨大的分配
+func (d *decoder) length(w, least int) (int, error) {
+	x, err := d.uint(w)
+	if err != nil {
+		return 0, err
+	}
+	if x > uint64(len(d.data)-d.pos)/uint64(least) {
+		return 0, fmt.Errorf("%w: length %d at offset %d", ErrShort, x, d.pos-w)
+	}
+	return int(x), nil
+}
+
+func (d *decoder) decode(n *node, p unsafe.Pointer) error {
+	if err := d.align(n.align); err != nil {
+		return err
+	}
+	switch n.kind {
+	case kBool:
+		b, err := d.take(1)
+		if err != nil {
+			return err
+		}
+		if b[0] > 1 {
+			return fmt.Errorf("bincodec: invalid bool %d at offset %d", b[0], d.pos-1)
+		}
+		*(*bool)(p) = b[0] == 1
+	case kInt:
+		x, err := d.uint(n.width)
+		if err != nil {
+			return err
+		}
+		shift := 64 - 8*n.width
+		v := int64(x<<shift) >> shift // 符号扩展
+		size := n.typ.Size()
+		if size < 8 && (v < -1<<(8*size-1) || v >= 1<<(8*size-1)) {
+			return fmt.Errorf("%w: %d does not fit in %s", ErrRange, v, n.typ)
+		}
+		storeUint(p, size, uint64(v))
+	case kUint:
+		x, err := d.uint(n.width)
+		if err != nil {
+			return err
+		}
+		size := n.typ.Size()
+		if size < 8 && x>>(8*size) != 0 {
+			return fmt.Errorf("%w: %d does not fit in %s", ErrRange, x, n.typ)
+		}
+		storeUint(p, size, x)
+	case kFloat:
+		x, err := d.uint(n.width)
+		if err != nil {
+			return err
+		}
+		if n.width == 4 {
+			*(*float32)(p) = math.Float32frombits(uint32(x))
+		} else {
+			*(*float64)(p) = math.Float64frombits(x)
+		}
+	case kString:
+		l := n.width
+		if l == 0 {
+			var err error
+			if l, err = d.length(n.prefix, 1); err != nil {
+				return err
+			}
+		}
+		b, err := d.take(l)
+		if err != nil {
+			return err
+		}
+		if n.width > 0 {
+			b = bytes.TrimRight(b, "\x00")
+		}
+		*(*string)(p) = string(b)
+	case kSlice:
+		return d.slice(n, p)
+	case kArray:
+		size := n.elem.typ.Size()
+		for i := 0; i < n.n; i++ {
+			if err := d.decode(n.elem, unsafe.Add(p, uintptr(i)*size)); err != nil {
+				return err
+			}
+		}
+	case kStruct:
+		for _, m := range n.fields {
+			if m.blank {
+				if err := d.align(m.node.align); err != nil {
+					return err
+				}
+				if _, err := d.take(m.node.fixed); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := d.decode(m.node, unsafe.Add(p, m.off)); err != nil {
+				return fmt.Errorf("%s: %w", m.name, err)
+			}
+		}
+		return d.align(n.align)
+	}
+	return nil
+}
+
+func (d *decoder) slice(n *node, p unsafe.Pointer) error {
+	l := n.width
+	if l == 0 {
+		least := n.elem.fixed
+		if least < 1 {
+			least = 1
+		}
+		var err error
+		if l, err = d.length(n.prefix, least); err != nil {
+			return err
+		}
+	}
+	v := reflect.NewAt(n.typ, p).Elem()
+	if l == 0 {
+		v.SetZero()
+		return nil
+	}
+	s := reflect.MakeSlice(n.typ, l, l)
+	if n.elem.kind == kUint && n.elem.width == 1 && n.elem.typ.Size() == 1 {
+		b, err := d.take(l)
+		if err != nil {
+			return err
+		}
+		copy(unsafe.Slice((*byte)(s.UnsafePointer()), l), b)
+	} else {
+		size := n.elem.typ.Size()
+		data := s.UnsafePointer()
+		for i := 0; i < l; i++ {
+			if err := d.decode(n.elem, unsafe.Add(data, uintptr(i)*size)); err != nil {
+				return err
+			}
+		}
+	}
+	v.Set(s)
+	return nil
+}
+
+func storeUint(p unsafe.Pointer, size uintptr, x uint64) {
+	switch size {
+	case 1:
+		*(*uint8)(p) = uint8(x)
+	case 2:
+		*(*uint16)(p) = uint16(x)
+	case 4:
+		*(*uint32)(p) = uint32(x)
+	default:
+		*(*uint64)(p) = x
+	}
+}
@@ -0,0 +1,202 @@
+package bincodec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"unsafe"
+)
+
+// Marshal 编码 v
+func (c *Codec[T]) Marshal(v *T) ([]byte, error) {
+	size := c.root.fixed
+	if size < 0 {
+		size = 64
+	}
+	return c.Append(make([]byte, 0, size), v)
+}
+
+// Append 把 v 编码追加到 buf，对齐相对于 v 的编码起始位置
+func (c *Codec[T]) Append(buf []byte, v *T) ([]byte, error) {
+	if c.zeroCopy {
+		// 布局和内存相同：直接复制，再把填充清零
+		base := len(buf)
+		buf = append(buf, unsafe.Slice((*byte)(unsafe.Pointer(v)), c.root.fixed)...)
+		for _, p := range c.pads {
+			for i := base + p[0]; i < base+p[1]; i++ {
+				buf[i] = 0
+			}
+		}
+		return buf, nil
+	}
+	e := encoder{order: c.order, buf: buf, base: len(buf)}
+	if err := e.encode(c.root, unsafe.Pointer(v)); err != nil {
+		return buf, err
+	}
+	return e.buf, nil
+}
+
+type encoder struct {
+	order binary.AppendByteOrder
+	buf   []byte
+	base  int
+}
+
+// pad 填充 0 到相对于 base 对齐 a
+func (e *encoder) pad(a int) {
+	for (len(e.buf)-e.base)%a != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *encoder) zeros(n int) {
+	for ; n > 0; n-- {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+// putUint 以 w 字节写入 x
+func (e *encoder) putUint(x uint64, w int) {
+	switch w {
+	case 1:
+		e.buf = append(e.buf, byte(x))
+	case 2:
+		e.buf = e.order.AppendUint16(e.buf, uint16(x))
+	case 4:
+		e.buf = e.order.AppendUint32(e.buf, uint32(x))
+	default:
+		e.buf = e.order.AppendUint64(e.buf, x)
+	}
+}
+
+// putLen 写入长度前缀
+func (e *encoder) putLen(n, w int) error {
+	if w < 8 && uint64(n)>>(8*w) != 0 {
+		return fmt.Errorf("%w: length %d does not fit in a %d-byte prefix", ErrRange, n, w)
+	}
+	e.putUint(uint64(n), w)
+	return nil
+}
+
+func (e *encoder) encode(n *node, p unsafe.Pointer) error {
+	e.pad(n.align)
+	switch n.kind {
+	case kBool:
+		var b byte
+		if *(*bool)(p) {
+			b = 1
+		}
+		e.buf = append(e.buf, b)
+	case kInt:
+		x := loadInt(p, n.typ.Size())
+		if n.width < 8 && (x < -1<<(8*n.width-1) || x >= 1<<(8*n.width-1)) {
+			return fmt.Errorf("%w: %d does not fit in %d bytes", ErrRange, x, n.width)
+		}
+		e.putUint(uint64(x), n.width)
+	case kUint:
+		x := loadUint(p, n.typ.Size())
+		if n.width < 8 && x>>(8*n.width) != 0 {
+			return fmt.Errorf("%w: %d does not fit in %d bytes", ErrRange, x, n.width)
+		}
+		e.putUint(x, n.width)
+	case kFloat:
+		if n.width == 4 {
+			e.putUint(uint64(math.Float32bits(*(*float32)(p))), 4)
+		} else {
+			e.putUint(math.Float64bits(*(*float64)(p)), 8)
+		}
+	case kString:
+		s := *(*string)(p)
+		if n.width > 0 {
+			if len(s) > n.width {
+				return fmt.Errorf("%w: string of %d bytes exceeds size %d", ErrRange, len(s), n.width)
+			}
+			e.buf = append(e.buf, s...)
+			e.zeros(n.width - len(s))
+			return nil
+		}
+		if err := e.putLen(len(s), n.prefix); err != nil {
+			return err
+		}
+		e.buf = append(e.buf, s...)
+	case kSlice:
+		return e.slice(n, p)
+	case kArray:
+		size := n.elem.typ.Size()
+		for i := 0; i < n.n; i++ {
+			if err := e.encode(n.elem, unsafe.Add(p, uintptr(i)*size)); err != nil {
+				return err
+			}
+		}
+	case kStruct:
+		for _, m := range n.fields {
+			if m.blank {
+				e.pad(m.node.align)
+				e.zeros(m.node.fixed)
+				continue
+			}
+			if err := e.encode(m.node, unsafe.Add(p, m.off)); err != nil {
+				return fmt.Errorf("%s: %w", m.name, err)
+			}
+		}
+		e.pad(n.align)
+	}
+	return nil
+}
+
+func (e *encoder) slice(n *node, p unsafe.Pointer) error {
+	v := reflect.NewAt(n.typ, p).Elem()
+	l := v.Len()
+	if n.width > 0 {
+		if l > n.width {
+			return fmt.Errorf("%w: %d elements exceed size %d", ErrRange, l, n.width)
+		}
+	} else if err := e.putLen(l, n.prefix); err != nil {
+		return err
+	}
+	if l > 0 && n.elem.kind == kUint && n.elem.width == 1 && n.elem.typ.Size() == 1 {
+		// 字节切片直接复制
+		e.buf = append(e.buf, unsafe.Slice((*byte)(v.UnsafePointer()), l)...)
+	} else if l > 0 {
+		size := n.elem.typ.Size()
+		data := v.UnsafePointer()
+		for i := 0; i < l; i++ {
+			if err := e.encode(n.elem, unsafe.Add(data, uintptr(i)*size)); err != nil {
+				return err
+			}
+		}
+	}
+	if n.width > l {
+		// 补齐零值元素，元素是定长的
+		for i := l; i < n.width; i++ {
+			e.pad(n.elem.align)
+			e.zeros(n.elem.fixed)
+		}
+	}
+	return nil
+}
+
+func loadInt(p unsafe.Pointer, size uintptr) int64 {
+	switch size {
+	case 1:
+		return int64(*(*int8)(p))
+	case 2:
+		return int64(*(*int16)(p))
+	case 4:
+		return int64(*(*int32)(p))
+	}
+	return *(*int64)(p)
+}
+
+func loadUint(p unsafe.Pointer, size uintptr) uint64 {
+	switch size {
+	case 1:
+		return uint64(*(*uint8)(p))
+	case 2:
+		return uint64(*(*uint16)(p))
+	case 4:
+		return uint64(*(*uint32)(p))
+	}
+	return *(*uint64)(p)
+}
@@ -267,6 +267,8 @@ func tReceive() {
 	wg.Wait()
 }
 
+// 接收方提前退出 range 时，发送方会永远阻塞在 c <- 上；
+// 需要提前停止的场景见 data/seq 的 Produce，用 ctx 通知生产者退出
 func tReceiveRange() {
 	var wg sync.WaitGroup
 	wg.Add(2)
@@ -0,0 +1,147 @@
+package collect
+
+import (
+	"strconv"
+	"testing"
+)
+
+// 泛型版本和手写循环的对比，对应 data/generic.go 中 performanceGen 关于 gcshape 的说明：
+// 值类型按底层类型各有一份实例，所有指针共享 go.shape.*uint8 一份实例，方法经字典间接调用
+
+const benchN = 1024
+
+func ints() []int {
+	s := make([]int, benchN)
+	for i := range s {
+		s[i] = i
+	}
+	return s
+}
+
+func BenchmarkMapGeneric(b *testing.B) {
+	s := ints()
+	for i := 0; i < b.N; i++ {
+		Map(s, func(v int) int { return v * 2 })
+	}
+}
+
+func BenchmarkMapLoop(b *testing.B) {
+	s := ints()
+	for i := 0; i < b.N; i++ {
+		out := make([]int, len(s))
+		for j, v := range s {
+			out[j] = v * 2
+		}
+	}
+}
+
+func BenchmarkFilterGeneric(b *testing.B) {
+	s := ints()
+	for i := 0; i < b.N; i++ {
+		Filter(s, even)
+	}
+}
+
+func BenchmarkFilterLoop(b *testing.B) {
+	s := ints()
+	for i := 0; i < b.N; i++ {
+		var out []int
+		for _, v := range s {
+			if v%2 == 0 {
+				out = append(out, v)
+			}
+		}
+	}
+}
+
+func BenchmarkReduceGeneric(b *testing.B) {
+	s := ints()
+	for i := 0; i < b.N; i++ {
+		Reduce(s, 0, func(a, v int) int { return a + v })
+	}
+}
+
+func BenchmarkReduceLoop(b *testing.B) {
+	s := ints()
+	for i := 0; i < b.N; i++ {
+		sum := 0
+		for _, v := range s {
+			sum += v
+		}
+		_ = sum
+	}
+}
+
+// 指针类型的方法通过约束调用，方法本身很简单，差异主要来自调用方式
+type num int
+
+//go:noinline
+func (n *num) Value() int { return int(*n) }
+
+type valuer interface{ Value() int }
+
+func values[T valuer](s []T) []int {
+	return Map(s, func(v T) int { return v.Value() })
+}
+
+func nums() []*num {
+	s := make([]*num, benchN)
+	for i := range s {
+		n := num(i)
+		s[i] = &n
+	}
+	return s
+}
+
+func BenchmarkMethodGeneric(b *testing.B) {
+	s := nums()
+	for i := 0; i < b.N; i++ {
+		values(s)
+	}
+}
+
+func BenchmarkMethodLoop(b *testing.B) {
+	s := nums()
+	for i := 0; i < b.N; i++ {
+		out := make([]int, len(s))
+		for j, v := range s {
+			out[j] = v.Value()
+		}
+	}
+}
+
+func BenchmarkSerialMap(b *testing.B) {
+	s := nums()
+	for i := 0; i < b.N; i++ {
+		Map(s, func(v *num) string { return strconv.Itoa(v.Value()) })
+	}
+}
+
+func BenchmarkParallelMap(b *testing.B) {
+	s := nums()
+	for i := 0; i < b.N; i++ {
+		ParallelMap(s, 0, func(v *num) string { return strconv.Itoa(v.Value()) })
+	}
+}
+
+// go test -bench . -benchmem ./data/collect
+/*
+goos: linux
+goarch: amd64
+pkg: yuhen/data/collect
+cpu: Intel(R) Xeon(R) Processor
+BenchmarkMapGeneric               402440              2771 ns/op            8192 B/op          1 allocs/op
+BenchmarkMapLoop                  481146              2400 ns/op            8192 B/op          1 allocs/op
+BenchmarkFilterGeneric            245762              4747 ns/op            8184 B/op          9 allocs/op
+BenchmarkFilterLoop               260988              4360 ns/op            8184 B/op          9 allocs/op
+BenchmarkReduceGeneric           2101418               761.0 ns/op             0 B/op          0 allocs/op
+BenchmarkReduceLoop              1600093               779.0 ns/op             0 B/op          0 allocs/op
+BenchmarkMethodGeneric            314965              3705 ns/op            8192 B/op          1 allocs/op
+BenchmarkMethodLoop               348327              3459 ns/op            8192 B/op          1 allocs/op
+BenchmarkSerialMap                 40532             32386 ns/op           21408 B/op        925 allocs/op
+BenchmarkParallelMap               29852             38647 ns/op           21488 B/op        926 allocs/op
+
+值类型的实例内联了 f，和手写循环只差一次函数值调用，分配次数相同
+指针实例 values[go.shape.*uint8] 经字典取得方法地址，每个元素多一次间接调用，约慢 7%
+这台机器只有 1 个 CPU，ParallelMap 退化为串行，多出的只有调度开销
+*/
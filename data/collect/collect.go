@@ -0,0 +1,227 @@
+// Package collect 在 data/generic.go 中 Max、testMakeSlice 的基础上提供切片和 map 的泛型操作
+//
+// 所有函数都不修改参数，返回新的切片或 map。
+// 单独成包是为了不和 data 中的 Map 等名字冲突
+package collect
+
+import (
+	"sort"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Map 对每个元素调用 f
+func Map[T, R any](s []T, f func(T) R) []R {
+	out := make([]R, len(s))
+	for i, v := range s {
+		out[i] = f(v)
+	}
+	return out
+}
+
+// Filter 保留 keep 返回 true 的元素
+func Filter[T any](s []T, keep func(T) bool) []T {
+	var out []T
+	for _, v := range s {
+		if keep(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Reduce 从 init 开始依次累积
+func Reduce[T, A any](s []T, init A, f func(A, T) A) A {
+	acc := init
+	for _, v := range s {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+// GroupBy 按 key 分组，组内保持原来的顺序
+func GroupBy[T any, K comparable](s []T, key func(T) K) map[K][]T {
+	out := make(map[K][]T)
+	for _, v := range s {
+		k := key(v)
+		out[k] = append(out[k], v)
+	}
+	return out
+}
+
+// Partition 按 pred 分成满足和不满足的两部分
+func Partition[T any](s []T, pred func(T) bool) (yes, no []T) {
+	for _, v := range s {
+		if pred(v) {
+			yes = append(yes, v)
+		} else {
+			no = append(no, v)
+		}
+	}
+	return yes, no
+}
+
+// Chunk 每 n 个元素一组，最后一组可能不足 n 个
+// 各组共享 s 的底层数组，但容量被截断，append 不会覆盖下一组
+func Chunk[T any](s []T, n int) [][]T {
+	if n <= 0 {
+		panic("collect: Chunk size must be positive")
+	}
+	out := make([][]T, 0, (len(s)+n-1)/n)
+	for i := 0; i < len(s); i += n {
+		j := i + n
+		if j > len(s) {
+			j = len(s)
+		}
+		out = append(out, s[i:j:j])
+	}
+	return out
+}
+
+// Pair 两个值
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Zip 按位置配对，长度取较短的一个
+func Zip[A, B any](a []A, b []B) []Pair[A, B] {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	out := make([]Pair[A, B], n)
+	for i := 0; i < n; i++ {
+		out[i] = Pair[A, B]{a[i], b[i]}
+	}
+	return out
+}
+
+// Unzip Zip 的逆操作
+func Unzip[A, B any](s []Pair[A, B]) ([]A, []B) {
+	a, b := make([]A, len(s)), make([]B, len(s))
+	for i, p := range s {
+		a[i], b[i] = p.First, p.Second
+	}
+	return a, b
+}
+
+// Uniq 去掉重复的元素，保留第一次出现的位置
+func Uniq[T comparable](s []T) []T {
+	return UniqBy(s, func(v T) T { return v })
+}
+
+// UniqBy 按 key 去重
+func UniqBy[T any, K comparable](s []T, key func(T) K) []T {
+	seen := make(map[K]struct{}, len(s))
+	var out []T
+	for _, v := range s {
+		k := key(v)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		out = append(out, v)
+	}
+	return out
+}
+
+// SortBy 按 key 升序排列的副本，key 相同时保持原来的顺序
+// key 对每个元素只计算一次
+func SortBy[T any, K constraints.Ordered](s []T, key func(T) K) []T {
+	type item struct {
+		k K
+		v T
+	}
+	items := make([]item, len(s))
+	for i, v := range s {
+		items[i] = item{key(v), v}
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].k < items[j].k })
+	out := make([]T, len(s))
+	for i, it := range items {
+		out[i] = it.v
+	}
+	return out
+}
+
+// MinBy key 最小的元素，有多个时取第一个；s 为空时 ok 为 false
+func MinBy[T any, K constraints.Ordered](s []T, key func(T) K) (min T, ok bool) {
+	return best(s, key, func(a, b K) bool { return a < b })
+}
+
+// MaxBy key 最大的元素，有多个时取第一个
+func MaxBy[T any, K constraints.Ordered](s []T, key func(T) K) (max T, ok bool) {
+	return best(s, key, func(a, b K) bool { return a > b })
+}
+
+func best[T any, K constraints.Ordered](s []T, key func(T) K, better func(a, b K) bool) (T, bool) {
+	if len(s) == 0 {
+		var zero T
+		return zero, false
+	}
+	v, k := s[0], key(s[0])
+	for _, x := range s[1:] {
+		if kx := key(x); better(kx, k) {
+			v, k = x, kx
+		}
+	}
+	return v, true
+}
+
+// Keys map 的键，顺序不确定
+func Keys[K comparable, V any](m map[K]V) []K {
+	out := make([]K, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+// Values map 的值，顺序不确定
+func Values[K comparable, V any](m map[K]V) []V {
+	out := make([]V, 0, len(m))
+	for _, v := range m {
+		out = append(out, v)
+	}
+	return out
+}
+
+// MapValues 对每个值调用 f，键不变
+func MapValues[K comparable, V, R any](m map[K]V, f func(V) R) map[K]R {
+	out := make(map[K]R, len(m))
+	for k, v := range m {
+		out[k] = f(v)
+	}
+	return out
+}
+
+// FilterEntries 保留 keep 返回 true 的键值对
+func FilterEntries[K comparable, V any](m map[K]V, keep func(K, V) bool) map[K]V {
+	out := make(map[K]V)
+	for k, v := range m {
+		if keep(k, v) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Entries 按键排序的键值对，输出顺序固定
+func Entries[K constraints.Ordered, V any](m map[K]V) []Pair[K, V] {
+	out := make([]Pair[K, V], 0, len(m))
+	for k, v := range m {
+		out = append(out, Pair[K, V]{k, v})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].First < out[j].First })
+	return out
+}
+
+// FromEntries 由键值对构造 map，重复的键取最后一个
+func FromEntries[K comparable, V any](s []Pair[K, V]) map[K]V {
+	out := make(map[K]V, len(s))
+	for _, p := range s {
+		out[p.First] = p.Second
+	}
+	return out
+}
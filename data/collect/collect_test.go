@@ -0,0 +1,250 @@
+package collect
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+// 基于 testing/quick 的性质测试：随机生成输入，检查结果满足的性质而不是具体的值
+
+func check(t *testing.T, f any) {
+	t.Helper()
+	if err := quick.Check(f, &quick.Config{MaxCount: 500}); err != nil {
+		t.Error(err)
+	}
+}
+
+func even(v int) bool { return v%2 == 0 }
+
+func TestMapProps(t *testing.T) {
+	check(t, func(s []int) bool {
+		m := Map(s, func(v int) string { return strconv.Itoa(v) })
+		if len(m) != len(s) {
+			return false
+		}
+		for i := range s {
+			if m[i] != strconv.Itoa(s[i]) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestFilterPartitionProps(t *testing.T) {
+	check(t, func(s []int) bool {
+		yes, no := Partition(s, even)
+		odd := func(v int) bool { return !even(v) }
+		return len(yes)+len(no) == len(s) &&
+			reflect.DeepEqual(yes, Filter(s, even)) &&
+			reflect.DeepEqual(no, Filter(s, odd))
+	})
+}
+
+func TestReduceProps(t *testing.T) {
+	check(t, func(s []int) bool {
+		sum := 0
+		for _, v := range s {
+			sum += v
+		}
+		return Reduce(s, 0, func(a, v int) int { return a + v }) == sum
+	})
+}
+
+func TestGroupByProps(t *testing.T) {
+	check(t, func(s []int) bool {
+		key := func(v int) int { return v % 7 }
+		g := GroupBy(s, key)
+		n := 0
+		for k, vs := range g {
+			n += len(vs)
+			for _, v := range vs {
+				if key(v) != k {
+					return false
+				}
+			}
+			// 组内保持原来的顺序
+			if !reflect.DeepEqual(vs, Filter(s, func(v int) bool { return key(v) == k })) {
+				return false
+			}
+		}
+		return n == len(s)
+	})
+}
+
+func TestChunkProps(t *testing.T) {
+	check(t, func(s []int, n uint8) bool {
+		size := int(n%16) + 1
+		cs := Chunk(s, size)
+		var all []int
+		for i, c := range cs {
+			if len(c) == 0 || len(c) > size || i < len(cs)-1 && len(c) != size {
+				return false
+			}
+			all = append(all, c...)
+		}
+		return len(all) == len(s) && (len(s) == 0 || reflect.DeepEqual(all, s))
+	})
+
+	// 各组容量被截断，append 不影响下一组
+	s := []int{1, 2, 3, 4}
+	cs := Chunk(s, 2)
+	_ = append(cs[0], 9)
+	if s[2] != 3 {
+		t.Error("append to a chunk overwrote the next one")
+	}
+}
+
+func TestZipProps(t *testing.T) {
+	check(t, func(a []int, b []string) bool {
+		z := Zip(a, b)
+		n := len(a)
+		if len(b) < n {
+			n = len(b)
+		}
+		x, y := Unzip(z)
+		return len(z) == n && reflect.DeepEqual(x, a[:n]) && reflect.DeepEqual(y, b[:n])
+	})
+}
+
+func TestUniqProps(t *testing.T) {
+	check(t, func(s []uint8) bool {
+		u := Uniq(s)
+		seen := make(map[uint8]bool)
+		for _, v := range u {
+			if seen[v] {
+				return false
+			}
+			seen[v] = true
+		}
+		for _, v := range s {
+			if !seen[v] {
+				return false
+			}
+		}
+		// 幂等，顺序为第一次出现的顺序
+		return reflect.DeepEqual(Uniq(u), u) && (len(s) == 0 || u[0] == s[0])
+	})
+}
+
+func TestSortByProps(t *testing.T) {
+	type item struct {
+		k, i int
+	}
+	check(t, func(ks []int8) bool {
+		s := make([]item, len(ks))
+		for i, k := range ks {
+			s[i] = item{int(k), i}
+		}
+		out := SortBy(s, func(v item) int { return v.k })
+		if len(out) != len(s) {
+			return false
+		}
+		for i := 1; i < len(out); i++ {
+			// 升序，key 相同时保持原来的顺序
+			if out[i-1].k > out[i].k || out[i-1].k == out[i].k && out[i-1].i > out[i].i {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestMinMaxByProps(t *testing.T) {
+	check(t, func(s []int) bool {
+		neg := func(v int) int { return -v }
+		min, ok1 := MinBy(s, neg)
+		max, ok2 := MaxBy(s, neg)
+		if len(s) == 0 {
+			return !ok1 && !ok2
+		}
+		sorted := append([]int(nil), s...)
+		sort.Ints(sorted)
+		return ok1 && ok2 && min == sorted[len(sorted)-1] && max == sorted[0]
+	})
+}
+
+func TestMaps(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	ks, vs := Keys(m), Values(m)
+	sort.Strings(ks)
+	sort.Ints(vs)
+	if !reflect.DeepEqual(ks, []string{"a", "b", "c"}) || !reflect.DeepEqual(vs, []int{1, 2, 3}) {
+		t.Error(ks, vs)
+	}
+	if got := MapValues(m, strconv.Itoa); !reflect.DeepEqual(got, map[string]string{"a": "1", "b": "2", "c": "3"}) {
+		t.Error(got)
+	}
+	if got := FilterEntries(m, func(k string, v int) bool { return k != "a" && v < 3 }); !reflect.DeepEqual(got, map[string]int{"b": 2}) {
+		t.Error(got)
+	}
+	e := Entries(m)
+	if e[0] != (Pair[string, int]{"a", 1}) || !reflect.DeepEqual(FromEntries(e), m) {
+		t.Error(e)
+	}
+}
+
+func TestParallelProps(t *testing.T) {
+	check(t, func(s []int, w int8) bool {
+		workers := int(w % 9)
+		sq := func(v int) int { return v * v }
+		key := func(v int) int { return v % 5 }
+		g, pg := GroupBy(s, key), ParallelGroupBy(s, workers, key)
+		return reflect.DeepEqual(ParallelMap(s, workers, sq), Map(s, sq)) &&
+			reflect.DeepEqual(ParallelFilter(s, workers, even), Filter(s, even)) &&
+			reflect.DeepEqual(pg, g)
+	})
+
+	// 长切片每个 worker 分批领取下标
+	s := make([]int, 10000)
+	for i := range s {
+		s[i] = i
+	}
+	sq := func(v int) int { return v * v }
+	if !reflect.DeepEqual(ParallelMap(s, 4, sq), Map(s, sq)) {
+		t.Error("ParallelMap")
+	}
+	if got := ParallelFilter(s, 0, even); len(got) != 5000 || got[1] != 2 {
+		t.Error("ParallelFilter")
+	}
+}
+
+// 元素少也要并发：每次调用等到 workers 个同时运行才返回，串行执行会超时；
+// 同时运行的个数不能超过 workers
+func TestParallelWorkers(t *testing.T) {
+	const n, workers = 10, 4
+	var (
+		mu            sync.Mutex
+		running, peak int
+		once          sync.Once
+	)
+	full := make(chan struct{})
+	ParallelMap(make([]int, n), workers, func(int) int {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		if running == workers {
+			once.Do(func() { close(full) })
+		}
+		mu.Unlock()
+
+		select {
+		case <-full:
+		case <-time.After(5 * time.Second):
+		}
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return 0
+	})
+	if peak != workers {
+		t.Errorf("peak %d concurrent calls, want %d", peak, workers)
+	}
+}
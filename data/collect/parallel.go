@@ -0,0 +1,87 @@
+package collect
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// maxBatch 每个 worker 一次最多领取的元素个数，减少对计数器的争用
+const maxBatch = 64
+
+// parallel 用 workers 个 goroutine 对 [0, n) 调用 f，workers <= 0 时取 GOMAXPROCS
+// 按批次动态领取下标，各元素耗时不均时也能分摊；元素少时每次只领一个，
+// 10 个慢的元素、10 个 worker 也是全部并发
+func parallel(n, workers int, f func(i int)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+	// 每个 worker 平均至少领取 4 批
+	batch := n / (workers * 4)
+	if batch < 1 {
+		batch = 1
+	} else if batch > maxBatch {
+		batch = maxBatch
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				start := int(next.Add(int64(batch))) - batch
+				if start >= n {
+					return
+				}
+				end := start + batch
+				if end > n {
+					end = n
+				}
+				for i := start; i < end; i++ {
+					f(i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// ParallelMap 并发地对每个元素调用 f，结果顺序和 s 相同，最多 workers 个 goroutine
+func ParallelMap[T, R any](s []T, workers int, f func(T) R) []R {
+	out := make([]R, len(s))
+	parallel(len(s), workers, func(i int) { out[i] = f(s[i]) })
+	return out
+}
+
+// ParallelFilter 并发地计算 keep，结果保持原来的顺序
+func ParallelFilter[T any](s []T, workers int, keep func(T) bool) []T {
+	ok := ParallelMap(s, workers, keep)
+	var out []T
+	for i, v := range s {
+		if ok[i] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// ParallelGroupBy 并发地计算 key，分组和 GroupBy 相同
+func ParallelGroupBy[T any, K comparable](s []T, workers int, key func(T) K) map[K][]T {
+	keys := ParallelMap(s, workers, key)
+	out := make(map[K][]T)
+	for i, v := range s {
+		out[keys[i]] = append(out[keys[i]], v)
+	}
+	return out
+}
@@ -0,0 +1,327 @@
+// Package deep 基于反射逐个字段比较任意值，补充 data.equals() 和 interfaceCmp() 中 == 做不到的部分：
+// 包含 map、切片的结构体不能比较，接口中的动态类型不可比较时 == 会 panic
+//
+// 和 reflect.DeepEqual 相比：
+//   - 默认忽略未导出的字段，Unexported 打开
+//   - 函数按代码地址比较，IgnoreFuncs 忽略
+//   - 浮点数可以设置误差，NaN 等于 NaN
+//   - Diff 按路径列出所有差异，而不只是结果
+package deep
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Option 比较选项
+type Option func(*config)
+
+type config struct {
+	unexported  bool
+	ignoreFuncs bool
+	emptyNil    bool
+	tolerance   float64
+}
+
+// Unexported 比较未导出的字段
+func Unexported() Option {
+	return func(c *config) { c.unexported = true }
+}
+
+// IgnoreFuncs 不比较函数
+func IgnoreFuncs() Option {
+	return func(c *config) { c.ignoreFuncs = true }
+}
+
+// EquateEmpty nil 和长度为 0 的切片、map 相等
+func EquateEmpty() Option {
+	return func(c *config) { c.emptyNil = true }
+}
+
+// Tolerance 浮点数（包括复数的实部和虚部）相差不超过 eps 时相等
+func Tolerance(eps float64) Option {
+	return func(c *config) { c.tolerance = eps }
+}
+
+// Change 一处差异，A、B 为格式化后的值，缺少的一侧为 <missing>
+type Change struct {
+	Path string // 如 next.Point.x、data[2]、m["k"]，根为 .
+	A, B string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s != %s", c.Path, c.A, c.B)
+}
+
+// Equal a 和 b 是否相等
+func Equal(a, b any, opts ...Option) bool {
+	d := newDiffer(opts, true)
+	d.compare(reflect.ValueOf(a), reflect.ValueOf(b))
+	return len(d.changes) == 0
+}
+
+// Diff 列出 a 和 b 的所有差异，相等时为空
+func Diff(a, b any, opts ...Option) []Change {
+	d := newDiffer(opts, false)
+	d.compare(reflect.ValueOf(a), reflect.ValueOf(b))
+	return d.changes
+}
+
+// Format 每行一处差异
+func Format(cs []Change) string {
+	var b strings.Builder
+	for _, c := range cs {
+		b.WriteString(c.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// visit 已经比较过的一对指针，用于处理循环引用
+// 切片还要加上长度：s[:2] 和 t[:2] 相等不代表 s[:3] 和 t[:3] 相等
+type visit struct {
+	a, b uintptr
+	n    int
+	t    reflect.Type
+}
+
+type differ struct {
+	config
+	first   bool // 找到第一处差异即停止
+	changes []Change
+	path    []string
+	visited map[visit]bool
+}
+
+func newDiffer(opts []Option, first bool) *differ {
+	d := &differ{first: first, visited: make(map[visit]bool)}
+	for _, o := range opts {
+		o(&d.config)
+	}
+	return d
+}
+
+func (d *differ) done() bool {
+	return d.first && len(d.changes) > 0
+}
+
+func (d *differ) report(a, b string) {
+	p := strings.Join(d.path, "")
+	p = strings.TrimPrefix(p, ".")
+	if p == "" {
+		p = "."
+	}
+	d.changes = append(d.changes, Change{Path: p, A: a, B: b})
+}
+
+func (d *differ) push(s string) { d.path = append(d.path, s) }
+func (d *differ) pop()          { d.path = d.path[:len(d.path)-1] }
+
+const missing = "<missing>"
+
+// format 格式化值，未导出的字段也能输出
+func format(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<nil>"
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if v.IsNil() {
+			return "nil"
+		}
+		if v.Kind() == reflect.Func || v.Kind() == reflect.Chan || v.Kind() == reflect.UnsafePointer {
+			return fmt.Sprintf("%s(%#x)", v.Type(), v.Pointer())
+		}
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
+func (d *differ) compare(a, b reflect.Value) {
+	if d.done() {
+		return
+	}
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			d.report(format(a), format(b))
+		}
+		return
+	}
+	if a.Type() != b.Type() {
+		d.report(a.Type().String(), b.Type().String())
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Bool:
+		if a.Bool() != b.Bool() {
+			d.report(format(a), format(b))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if a.Int() != b.Int() {
+			d.report(format(a), format(b))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if a.Uint() != b.Uint() {
+			d.report(format(a), format(b))
+		}
+	case reflect.Float32, reflect.Float64:
+		if !d.float(a.Float(), b.Float()) {
+			d.report(format(a), format(b))
+		}
+	case reflect.Complex64, reflect.Complex128:
+		x, y := a.Complex(), b.Complex()
+		if !d.float(real(x), real(y)) || !d.float(imag(x), imag(y)) {
+			d.report(format(a), format(b))
+		}
+	case reflect.String:
+		if a.String() != b.String() {
+			d.report(format(a), format(b))
+		}
+	case reflect.Chan, reflect.UnsafePointer:
+		if a.Pointer() != b.Pointer() {
+			d.report(format(a), format(b))
+		}
+	case reflect.Func:
+		if !d.ignoreFuncs && a.Pointer() != b.Pointer() {
+			d.report(format(a), format(b))
+		}
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				d.report(format(a.Elem()), format(b.Elem()))
+			}
+			return
+		}
+		d.compare(a.Elem(), b.Elem())
+	case reflect.Pointer:
+		if a.Pointer() == b.Pointer() {
+			return
+		}
+		if a.IsNil() || b.IsNil() {
+			d.report(format(a), format(b))
+			return
+		}
+		if d.seen(a, b) {
+			return
+		}
+		d.compare(a.Elem(), b.Elem())
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() && !d.unexported {
+				continue
+			}
+			d.push("." + f.Name)
+			d.compare(a.Field(i), b.Field(i))
+			d.pop()
+		}
+	case reflect.Array:
+		d.list(a, b)
+	case reflect.Slice:
+		if a.IsNil() != b.IsNil() && !(d.emptyNil && a.Len() == 0 && b.Len() == 0) {
+			d.report(format(a), format(b))
+			return
+		}
+		if a.Pointer() == b.Pointer() && a.Len() == b.Len() {
+			return
+		}
+		if d.seen(a, b) {
+			return
+		}
+		d.list(a, b)
+	case reflect.Map:
+		if a.IsNil() != b.IsNil() && !(d.emptyNil && a.Len() == 0 && b.Len() == 0) {
+			d.report(format(a), format(b))
+			return
+		}
+		if a.Pointer() == b.Pointer() {
+			return
+		}
+		if d.seen(a, b) {
+			return
+		}
+		d.maps(a, b)
+	}
+}
+
+func (d *differ) float(x, y float64) bool {
+	if math.IsNaN(x) && math.IsNaN(y) {
+		return true
+	}
+	return x == y || math.Abs(x-y) <= d.tolerance
+}
+
+// seen 记录一对引用，再次遇到时（循环引用）视为相等，避免无限递归
+func (d *differ) seen(a, b reflect.Value) bool {
+	v := visit{a: a.Pointer(), b: b.Pointer(), t: a.Type()}
+	if a.Kind() == reflect.Slice {
+		v.n = a.Len()
+	}
+	if d.visited[v] {
+		return true
+	}
+	d.visited[v] = true
+	return false
+}
+
+// list 逐个比较元素，长度不同时多出的元素另一侧为 <missing>
+func (d *differ) list(a, b reflect.Value) {
+	n := a.Len()
+	if b.Len() > n {
+		n = b.Len()
+	}
+	for i := 0; i < n && !d.done(); i++ {
+		d.push("[" + strconv.Itoa(i) + "]")
+		switch {
+		case i >= a.Len():
+			d.report(missing, format(b.Index(i)))
+		case i >= b.Len():
+			d.report(format(a.Index(i)), missing)
+		default:
+			d.compare(a.Index(i), b.Index(i))
+		}
+		d.pop()
+	}
+}
+
+// maps 按键的格式化结果排序，输出的顺序固定
+func (d *differ) maps(a, b reflect.Value) {
+	type entry struct {
+		key  reflect.Value
+		name string
+	}
+	var keys []entry
+	for _, k := range a.MapKeys() {
+		keys = append(keys, entry{k, format(k)})
+	}
+	for _, k := range b.MapKeys() {
+		if !a.MapIndex(k).IsValid() {
+			keys = append(keys, entry{k, format(k)})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].name < keys[j].name })
+
+	for _, k := range keys {
+		if d.done() {
+			return
+		}
+		d.push("[" + k.name + "]")
+		x, y := a.MapIndex(k.key), b.MapIndex(k.key)
+		switch {
+		case !x.IsValid():
+			d.report(missing, format(y))
+		case !y.IsValid():
+			d.report(format(x), missing)
+		default:
+			d.compare(x, y)
+		}
+		d.pop()
+	}
+}
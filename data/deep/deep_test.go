@@ -0,0 +1,179 @@
+package deep
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 和 data.mem() 中的定义相同，next 指向自身时形成循环
+type Point struct {
+	x, y int
+}
+
+type Value struct {
+	id   int
+	name string
+	data []byte
+	next *Value
+	Point
+}
+
+func paths(cs []Change) []string {
+	var out []string
+	for _, c := range cs {
+		out = append(out, c.String())
+	}
+	return out
+}
+
+func TestCycle(t *testing.T) {
+	a := &Value{id: 1, name: "test", data: []byte{1, 2, 3, 4}, Point: Point{100, 200}}
+	a.next = a
+	b := &Value{id: 1, name: "test", data: []byte{1, 2, 3, 4}, Point: Point{100, 200}}
+	b.next = b
+
+	if !Equal(a, b, Unexported()) {
+		t.Error("cyclic values should be equal")
+	}
+
+	// 两个节点的环和一个节点的环
+	c := &Value{id: 1, name: "test", data: []byte{1, 2, 3, 4}, Point: Point{100, 200}}
+	c.next = &Value{id: 2, next: c}
+	got := paths(Diff(a, c, Unexported()))
+	want := []string{
+		"next.id: 1 != 2",
+		`next.name: "test" != ""`,
+		"next.data: [1 2 3 4] != nil",
+		"next.Point.x: 100 != 0",
+		"next.Point.y: 200 != 0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// 共享底层数组、长度不同的切片不能当成已经比较过
+func TestSliceLen(t *testing.T) {
+	type P struct{ A, B []int }
+	s, u := []int{1, 2, 3}, []int{1, 2, 4}
+	a, b := P{A: s[:2], B: s[:3]}, P{A: u[:2], B: u[:3]}
+	if Equal(a, b) || reflect.DeepEqual(a, b) {
+		t.Error("B differs")
+	}
+	if got := paths(Diff(a, b)); !reflect.DeepEqual(got, []string{"B[2]: 3 != 4"}) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestUnexported(t *testing.T) {
+	a, b := Value{id: 1}, Value{id: 2}
+	// 所有字段都未导出，默认不比较
+	if !Equal(a, b) {
+		t.Error("unexported fields should be ignored by default")
+	}
+	if Equal(a, b, Unexported()) {
+		t.Error("Unexported should compare id")
+	}
+}
+
+type S struct {
+	Name  string
+	Tags  []string
+	M     map[string]int
+	F     func() int
+	Any   any
+	Score float64
+	Arr   [2]int
+}
+
+func one() int { return 1 }
+func two() int { return 2 }
+
+func TestDiff(t *testing.T) {
+	a := S{Name: "a", Tags: []string{"x", "y"}, M: map[string]int{"k": 1, "a": 2}, F: one, Any: 1, Score: 1, Arr: [2]int{1, 2}}
+	b := S{Name: "b", Tags: []string{"x"}, M: map[string]int{"k": 2, "z": 3}, F: one, Any: "1", Score: 1, Arr: [2]int{1, 3}}
+
+	got := paths(Diff(a, b))
+	want := []string{
+		`Name: "a" != "b"`,
+		`Tags[1]: "y" != <missing>`,
+		`M["a"]: 2 != <missing>`,
+		`M["k"]: 1 != 2`,
+		`M["z"]: <missing> != 3`,
+		"Any: int != string",
+		"Arr[1]: 2 != 3",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if Equal(a, b) {
+		t.Error("Equal")
+	}
+	if Diff(a, a) != nil || !Equal(a, a) {
+		t.Error("a != a")
+	}
+}
+
+func TestFuncs(t *testing.T) {
+	a, b := S{F: one}, S{F: two}
+	if Equal(a, b) {
+		t.Error("funcs are compared by pointer")
+	}
+	if !Equal(a, b, IgnoreFuncs()) {
+		t.Error("IgnoreFuncs")
+	}
+	if !Equal(S{F: one}, S{F: one}) {
+		t.Error("same func")
+	}
+}
+
+// data.interfaceCmp 中接口保存不可比较的类型时 == 会 panic
+func TestUncomparable(t *testing.T) {
+	a, b := any([]int{1}), any([]int{1})
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("== should panic")
+			}
+		}()
+		_ = a == b
+	}()
+	if !Equal(a, b) {
+		t.Error("Equal")
+	}
+	if got := paths(Diff(a, any([]int{2}))); !reflect.DeepEqual(got, []string{"[0]: 1 != 2"}) {
+		t.Error(got)
+	}
+	if got := paths(Diff(nil, 1)); !reflect.DeepEqual(got, []string{".: <nil> != 1"}) {
+		t.Error(got)
+	}
+}
+
+func TestFloat(t *testing.T) {
+	// 常量运算是精确的，用变量
+	x, y := 0.1, 0.2
+	if Equal(x+y, 0.3) {
+		t.Error("exact comparison")
+	}
+	if !Equal(x+y, 0.3, Tolerance(1e-9)) {
+		t.Error("Tolerance")
+	}
+	if !Equal(math.NaN(), math.NaN()) {
+		t.Error("NaN")
+	}
+	if !Equal([]complex128{complex(1, x+y)}, []complex128{complex(1, 0.3)}, Tolerance(1e-9)) {
+		t.Error("complex")
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	a, b := S{Tags: nil, M: nil}, S{Tags: []string{}, M: map[string]int{}}
+	if got := paths(Diff(a, b)); len(got) != 2 {
+		t.Error(got)
+	}
+	if !Equal(a, b, EquateEmpty()) {
+		t.Error("EquateEmpty")
+	}
+}
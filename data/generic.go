@@ -59,51 +59,9 @@ func testGen2[T fmt.Stringer](v T) { fmt.Println(v) }
 // |： 竖线，类型集合，匹配其中任一类型即可
 // 波浪线：底层类型 时该类型的所有类型
 
-/*
-// Signed is a constraint that permits any signed integer type.
-// If future releases of Go add new predeclared signed integer types,
-// this constraint will be modified to include them.
-type Signed interface {
-	~int | ~int8 | ~int16 | ~int32 | ~int64
-}
-
-// Unsigned is a constraint that permits any unsigned integer type.
-// If future releases of Go add new predeclared unsigned integer types,
-// this constraint will be modified to include them.
-type Unsigned interface {
-	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
-}
-
-// Integer is a constraint that permits any integer type.
-// If future releases of Go add new predeclared integer types,
-// this constraint will be modified to include them.
-type Integer interface {
-	Signed | Unsigned
-}
-
-// Float is a constraint that permits any floating-point type.
-// If future releases of Go add new predeclared floating-point types,
-// this constraint will be modified to include them.
-type Float interface {
-	~float32 | ~float64
-}
-
-// Complex is a constraint that permits any complex numeric type.
-// If future releases of Go add new predeclared complex numeric types,
-// this constraint will be modified to include them.
-type Complex interface {
-	~complex64 | ~complex128
-}
-
-// Ordered is a constraint that permits any ordered type: any type
-// that supports the operators < <= >= >.
-// If future releases of Go add new ordered types,
-// this constraint will be modified to include them.
-type Ordered interface {
-	Integer | Float | ~string
-}
-
-*/
+// Signed、Unsigned、Integer、Float、Complex、Ordered 直接使用 golang.org/x/exp/constraints 中的定义，
+// 例如 Signed 即 ~int | ~int8 | ~int16 | ~int32 | ~int64，Integer 即 Signed | Unsigned
+// 基于这些约束的溢出检查、安全转换和统计函数见 data/numeric
 
 type GenA int
 type GenB string
@@ -223,129 +181,32 @@ func testGen3() {
 func testGenImp[T any](x T) {
 	println(x)
 
-	// 操作步骤
-	// 1. go build -gcflags "-l"
-	// 2. go tool objdump -s "data\.testGenImp" ./yuhen
-	/*	俩函数并没有使用字典
-		TEXT yuhen/data.testGenImp[go.shape.string](SB) /mnt/c/Users/GolandProjects/yuhen/data/generic.go
-		  generic.go:211        0x4fc180                493b6610                CMPQ 0x10(R14), SP
-		  generic.go:211        0x4fc184                7642                    JBE 0x4fc1c8
-		  generic.go:211        0x4fc186                4883ec18                SUBQ $0x18, SP
-		  generic.go:211        0x4fc18a                48896c2410              MOVQ BP, 0x10(SP)
-		  generic.go:211        0x4fc18f                488d6c2410              LEAQ 0x10(SP), BP
-		  generic.go:211        0x4fc194                48894c2430              MOVQ CX, 0x30(SP)
-		  generic.go:211        0x4fc199                48895c2428              MOVQ BX, 0x28(SP)
-		  generic.go:212        0x4fc19e                6690                    NOPW
-		  generic.go:212        0x4fc1a0                e85bbbf3ff              CALL runtime.printlock(SB)
-		  generic.go:212        0x4fc1a5                488b442428              MOVQ 0x28(SP), AX
-		  generic.go:212        0x4fc1aa                488b5c2430              MOVQ 0x30(SP), BX
-		  generic.go:212        0x4fc1af                e84cc4f3ff              CALL runtime.printstring(SB)
-		  generic.go:212        0x4fc1b4                e8a7bdf3ff              CALL runtime.printnl(SB)
-		  generic.go:212        0x4fc1b9                e8c2bbf3ff              CALL runtime.printunlock(SB)
-		  generic.go:213        0x4fc1be                488b6c2410              MOVQ 0x10(SP), BP
-		  generic.go:213        0x4fc1c3                4883c418                ADDQ $0x18, SP
-		  generic.go:213        0x4fc1c7                c3                      RET
-		  generic.go:211        0x4fc1c8                4889442408              MOVQ AX, 0x8(SP)
-		  generic.go:211        0x4fc1cd                48895c2410              MOVQ BX, 0x10(SP)
-		  generic.go:211        0x4fc1d2                48894c2418              MOVQ CX, 0x18(SP)
-		  generic.go:211        0x4fc1d7                e88492f6ff              CALL runtime.morestack_noctxt.abi0(SB)
-		  generic.go:211        0x4fc1dc                488b442408              MOVQ 0x8(SP), AX
-		  generic.go:211        0x4fc1e1                488b5c2410              MOVQ 0x10(SP), BX
-		  generic.go:211        0x4fc1e6                488b4c2418              MOVQ 0x18(SP), CX
-		  generic.go:211        0x4fc1eb                eb93                    JMP yuhen/data.testGenImp[go.shape.string](SB)
-
-		TEXT yuhen/data.testGenImp[go.shape.int](SB) /mnt/c/Users/GolandProjects/yuhen/data/generic.go
-		  generic.go:211        0x4fc200                493b6610                CMPQ 0x10(R14), SP
-		  generic.go:211        0x4fc204                7636                    JBE 0x4fc23c
-		  generic.go:211        0x4fc206                4883ec18                SUBQ $0x18, SP
-		  generic.go:211        0x4fc20a                48896c2410              MOVQ BP, 0x10(SP)
-		  generic.go:211        0x4fc20f                488d6c2410              LEAQ 0x10(SP), BP
-		  generic.go:211        0x4fc214                48895c2408              MOVQ BX, 0x8(SP)
-		  generic.go:212        0x4fc219                e8e2baf3ff              CALL runtime.printlock(SB)
-		  generic.go:212        0x4fc21e                488b442408              MOVQ 0x8(SP), AX
-		  generic.go:212        0x4fc223                e8d8c1f3ff              CALL runtime.printint(SB)
-		  generic.go:212        0x4fc228                e833bdf3ff              CALL runtime.printnl(SB)
-		  generic.go:212        0x4fc22d                e84ebbf3ff              CALL runtime.printunlock(SB)
-		  generic.go:213        0x4fc232                488b6c2410              MOVQ 0x10(SP), BP
-		  generic.go:213        0x4fc237                4883c418                ADDQ $0x18, SP
-		  generic.go:213        0x4fc23b                c3                      RET
-		  generic.go:211        0x4fc23c                4889442408              MOVQ AX, 0x8(SP)
-		  generic.go:211        0x4fc241                48895c2410              MOVQ BX, 0x10(SP)
-		  generic.go:211        0x4fc246                e81592f6ff              CALL runtime.morestack_noctxt.abi0(SB)
-		  generic.go:211        0x4fc24b                488b442408              MOVQ 0x8(SP), AX
-		  generic.go:211        0x4fc250                488b5c2410              MOVQ 0x10(SP), BX
-		  generic.go:211        0x4fc255                eba9                    JMP yuhen/data.testGenImp[go.shape.int](SB)
-	*/
-
-	/*
-		TEXT yuhen/data.testGenImp[go.shape.*uint8](SB) /mnt/c/Users/GolandProjects/yuhen/data/generic.go
-		TEXT yuhen/data.testGenImp[go.shape.string](SB) /mnt/c/Users/GolandProjects/yuhen/data/generic.go
-		TEXT yuhen/data.testGenImp[go.shape.int](SB) /mnt/c/Users/GolandProjects/yuhen/data/generic.go
-	*/
+	// 按 gcshape 生成实例：int 和底层类型为 int 的 X 共用 [go.shape.int]，
+	// 所有指针共用 [go.shape.*uint8]，string 单独一份。
+	// 实例中没有读取 AX 里的字典，打印只依赖 shape 本身
+	// 以上由 generic_test.go 的 TestGenImpShapes 编译后检查（go build -gcflags "-l" + objdump）
 }
 
-/*
-操作步骤
-go build -gcflags "-l"
-go tool objdump -S -s "data\.impGen" ./yuhen
-*/
-
+// 调用方先把字典装入 AX，参数从 BX 开始，再调用 shape 实例
+// 见 generic_test.go 的 TestImpGenDict
+//
 //go:noinline
 func impGen() {
-	testGenImp(1)
-	/*
-	  0x4f5cf4              488d05edf21d00          LEAQ yuhen/data..dict.testGenImp[int](SB), AX
-	  0x4f5cfb              bb01000000              MOVL $0x1, BX
-	  0x4f5d00              e8fb640000              CALL yuhen/data.testGenImp[go.shape.int](SB)
-	*/
+	testGenImp(1) // LEAQ ..dict.testGenImp[int](SB), AX; CALL testGenImp[go.shape.int]
 
 	// same underlying type
 	type X int
-	testGenImp(X(2))
-	/*
-	  0x4f5d05              488d05ecf21d00          LEAQ yuhen/data..dict.testGenImp[yuhen/data.X.2](SB), AX 	; 字典不同
-	  0x4f5d0c              bb02000000              MOVL $0x2, BX
-	  0x4f5d11              e8ea640000              CALL yuhen/data.testGenImp[go.shape.int](SB)				; 函数相同
-	*/
+	testGenImp(X(2)) // 字典不同 ..dict.testGenImp[X.2]，函数相同 testGenImp[go.shape.int]
 
-	testGenImp("abc")
-	/*
-	  0x4f5d16              488d05d3f21d00          LEAQ yuhen/data..dict.testGenImp[string](SB), AX	; 字典不同
-	  0x4f5d1d              488d1d5e491700          LEAQ 0x17495e(IP), BX
-	  0x4f5d24              b903000000              MOVL $0x3, CX
-	  0x4f5d29              e852640000              CALL yuhen/data.testGenImp[go.shape.string](SB)		; 函数不同
-	*/
+	testGenImp("abc") // 字典不同，函数也不同 testGenImp[go.shape.string]
 
 	//所有指针（任意类型）同步，与指针目标类型不同组
 	a := 1
-	/*
-		0x4f5d32              48c744242001000000      MOVQ $0x1, 0x20(SP)
-	*/
 	testGenImp(a)
-	/*
-	  0x4f5d3b              488d05b6f21d00          LEAQ yuhen/data..dict.testGenImp[int](SB), AX
-	  0x4f5d42              bb01000000              MOVL $0x1, BX
-	  0x4f5d47              e874650000              CALL yuhen/data.testGenImp[go.shape.int](SB)
-	*/
-	testGenImp(&a) // 与&int &float同组，与int float不同组
-	/*
-	  0x4f5d4c              488d059df21d00          LEAQ yuhen/data..dict.testGenImp[*int](SB), AX
-	  0x4f5d53              488d5c2420              LEAQ 0x20(SP), BX
-	  0x4f5d58              e883640000              CALL yuhen/data.testGenImp[go.shape.*uint8](SB); 和testGenImp(a)不同
-	*/
+	testGenImp(&a) // 与&int &float同组，与int float不同组：testGenImp[go.shape.*uint8]
 
 	b := 1.2
-	/*
-	  0x4f5d5d              f20f100583f01d00        MOVSD_XMM $f64.3ff3333333333333(SB), X0
-	  0x4f5d65              f20f11442418            MOVSD_XMM X0, 0x18(SP)
-	*/
-	testGenImp(&b)
-	/*
-	  0x4f5d6b              488d0576f21d00          LEAQ yuhen/data..dict.testGenImp[*float64](SB), AX
-	  0x4f5d72              488d5c2418              LEAQ 0x18(SP), BX
-	  0x4f5d77              e864640000              CALL yuhen/data.testGenImp[go.shape.*uint8](SB);和testGenImp(&a)相同
-	*/
-
+	testGenImp(&b) // 字典 ..dict.testGenImp[*float64]，函数和testGenImp(&a)相同
 }
 
 /*
@@ -362,64 +223,18 @@ func impGen() {
 
 //go:noinline
 func performanceGen() {
-	var a GenA = 1
+	var a GenA = 1 // 跑到堆上去了（runtime.newobject 或 mallocgc 系列）
 	var b GenB = "2"
-	/*
-	        var a GenA = 1
-	  0x4f5d74              488d0565cd1300          LEAQ 0x13cd65(IP), AX
-	  0x4f5d7b              0f1f440000              NOPL 0(AX)(AX*1)
-	  0x4f5d80              e8fb96f1ff              CALL runtime.newobject(SB) ;跑到堆上去了
-	  0x4f5d85              4889442418              MOVQ AX, 0x18(SP)
-	  0x4f5d8a              48c70001000000          MOVQ $0x1, 0(AX)
-	        var b GenB = "2"
-	  0x4f5d91              488d05a8cd1300          LEAQ 0x13cda8(IP), AX
-	  0x4f5d98              e8e396f1ff              CALL runtime.newobject(SB)
-	  0x4f5d9d              4889442410              MOVQ AX, 0x10(SP)
-	  0x4f5da2              48c7400801000000        MOVQ $0x1, 0x8(AX)
-	  0x4f5daa              488d0df7ed1d00          LEAQ 0x1dedf7(IP), CX
-	  0x4f5db1              488908                  MOVQ CX, 0(AX)
-	*/
 
 	// testTester(a)
 	// testTester(b)
+	// 值类型按底层类型分组：testTester[go.shape.int]、testTester[go.shape.string]
 
 	testTesterPoniter(&a)
 	testTesterPoniter(&b) // 确实，指针版本存在内存逃逸
-	/*
-			func performanceGen() {
-			  0x4f5da0              493b6610                CMPQ 0x10(R14), SP
-			  0x4f5da4              7641                    JBE 0x4f5de7
-			  0x4f5da6              4883ec20                SUBQ $0x20, SP
-			  0x4f5daa              48896c2418              MOVQ BP, 0x18(SP)
-			  0x4f5daf              488d6c2418              LEAQ 0x18(SP), BP
-			        testTester(a)
-			  0x4f5db4              488d05e5111e00          LEAQ yuhen/data..dict.testTester[yuhen/data.GenA](SB), AX
-			  0x4f5dbb              bb01000000              MOVL $0x1, BX
-			  0x4f5dc0              e8db640000              CALL yuhen/data.testTester[go.shape.int](SB)
-			        testTester(b)
-			  0x4f5dc5              488d05e4111e00          LEAQ yuhen/data..dict.testTester[yuhen/data.GenB](SB), AX
-			  0x4f5dcc              488d1dcdec1d00          LEAQ 0x1deccd(IP), BX
-			  0x4f5dd3              b901000000              MOVL $0x1, CX
-			  0x4f5dd8              e863640000              CALL yuhen/data.testTester[go.shape.string](SB)
-			}
-			  0x4f5ddd              488b6c2418              MOVQ 0x18(SP), BP
-			  0x4f5de2              4883c420                ADDQ $0x20, SP
-			  0x4f5de6              c3                      RET
-			func performanceGen() {
-			  0x4f5de7              e874f6f6ff              CALL runtime.morestack_noctxt.abi0(SB)
-			  0x4f5dec              ebb2                    JMP yuhen/data.performanceGen(SB)
-
-
-			  testTesterPoniter(&a)
-		  0x4f5db4              488b5c2418              MOVQ 0x18(SP), BX
-		  0x4f5db9              488d05e0121e00          LEAQ yuhen/data..dict.testTesterPoniter[*yuhen/data.GenA](SB), AX
-		  0x4f5dc0              e8db640000              CALL yuhen/data.testTesterPoniter[go.shape.*yuhen/data.GenA](SB)
-		        testTesterPoniter(&b)
-		  0x4f5dc5              488d05e4121e00          LEAQ yuhen/data..dict.testTesterPoniter[*yuhen/data.GenB](SB), AX
-		  0x4f5dcc              488b5c2410              MOVQ 0x10(SP), BX
-		  0x4f5dd1              e86a640000              CALL yuhen/data.testTesterPoniter[go.shape.*yuhen/data.GenB](SB)
-	*/
-
+	// 指针实例中方法通过字典间接调用（CALL CX），编译器无法证明 a b 不逃逸
+	// 见 generic_test.go 的 TestPerformanceGenEscape
+	// 泛型集合操作和手写循环的性能对比见 data/collect 的 bench_test.go
 }
 
 func Generic() {
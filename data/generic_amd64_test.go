@@ -0,0 +1,80 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+
+	"yuhen/internal/disasm/disasmtest"
+)
+
+// 以下检查 amd64 ABIInternal 的寄存器约定：字典在 AX，参数从 BX 开始
+
+// 和注释中的操作步骤一致：关闭内联后检查
+func build(t *testing.T) *disasmtest.Object {
+	return disasmtest.Build(t, "yuhen/data", "-l")
+}
+
+func TestGenImpShapes(t *testing.T) {
+	o := build(t)
+
+	want := []string{"[*float64]", "[*int]", "[go.shape.*uint8]", "[go.shape.int]", "[go.shape.string]", "[int]", "[string]", "[yuhen/data.X.2]"}
+	if got := o.Instances("testGenImp"); !reflect.DeepEqual(got, want) {
+		t.Errorf("instances = %q, want %q", got, want)
+	}
+
+	// shape 实例没有读取 AX 中的字典
+	for _, shape := range []string{"go.shape.int", "go.shape.string", "go.shape.*uint8"} {
+		f := o.Func(t, "testGenImp["+shape+"]")
+		if f.ReadsBeforeWrite("AX") {
+			t.Errorf("%s reads the dictionary\n%s", f.Sym, f)
+		}
+	}
+	o.AssertCalls(t, o.Func(t, "testGenImp[go.shape.int]"), "runtime.printint")
+	o.AssertCalls(t, o.Func(t, "testGenImp[go.shape.string]"), "runtime.printstring")
+	o.AssertCalls(t, o.Func(t, "testGenImp[go.shape.*uint8]"), "runtime.printpointer")
+}
+
+func TestImpGenDict(t *testing.T) {
+	o := build(t)
+	f := o.Func(t, "impGen")
+
+	// 字典不同，函数相同
+	o.AssertDictCall(t, f, ".dict.testGenImp[int]", "testGenImp[go.shape.int]")
+	o.AssertDictCall(t, f, ".dict.testGenImp[yuhen/data.X.2]", "testGenImp[go.shape.int]")
+	o.AssertDictCall(t, f, ".dict.testGenImp[string]", "testGenImp[go.shape.string]")
+	// 所有指针共用一个 shape
+	o.AssertDictCall(t, f, ".dict.testGenImp[*int]", "testGenImp[go.shape.*uint8]")
+	o.AssertDictCall(t, f, ".dict.testGenImp[*float64]", "testGenImp[go.shape.*uint8]")
+
+	// 参数紧跟在字典之后，从 BX 开始
+	c := f.CallsTo(o.Func(t, "testGenImp[go.shape.int]").Sym)[0]
+	if got := c.Loaded("BX"); got != "$0x1" {
+		t.Errorf("first argument in BX = %q, want $0x1\n%s", got, f)
+	}
+
+	o.AssertNotCalls(t, f, "testGenImp[int]", "testGenImp[string]")
+	o.AssertNoAllocs(t, f)
+}
+
+func TestPerformanceGenEscape(t *testing.T) {
+	o := build(t)
+	f := o.Func(t, "performanceGen")
+
+	// 取地址传给指针版本的泛型函数，两个变量都分配在堆上
+	o.AssertAllocs(t, f, disasmtest.LineOf(t, "generic.go", "var a GenA = 1"))
+	o.AssertAllocs(t, f, disasmtest.LineOf(t, "generic.go", `var b GenB = "2"`))
+	o.AssertDictCall(t, f, ".dict.testTesterPoniter[*yuhen/data.GenA]", "testTesterPoniter[go.shape.*yuhen/data.GenA]")
+	o.AssertDictCall(t, f, ".dict.testTesterPoniter[*yuhen/data.GenB]", "testTesterPoniter[go.shape.*yuhen/data.GenB]")
+
+	// 方法通过字典找到后间接调用
+	for _, shape := range []string{"go.shape.*yuhen/data.GenA", "go.shape.*yuhen/data.GenB"} {
+		if p := o.Func(t, "testTesterPoniter["+shape+"]"); len(p.DynamicCalls()) == 0 {
+			t.Errorf("%s: no dynamic call\n%s", p.Sym, p)
+		}
+	}
+
+	// 值版本按底层类型共用 shape
+	g := o.Func(t, "testGen3")
+	o.AssertDictCall(t, g, ".dict.testTester[yuhen/data.GenA]", "testTester[go.shape.int]")
+	o.AssertDictCall(t, g, ".dict.testTester[yuhen/data.GenB]", "testTester[go.shape.string]")
+}
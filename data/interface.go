@@ -346,12 +346,13 @@ type Ner interface {
 type L int
 
 /*尝试展开下方法表的地址
+itab 的 fun 从 +0x18 开始（inter 8、_type 8、hash 4，fun 按指针对齐；32 位从 +0xc 开始），按接口方法名排序，嵌入的 Mer.A 和 Ner 自己的方法一起排序
 fun
-Mer.A() +0
-Ner.Mer.A()+8
-Ner.B()+16
-Ner.C()+24
-.D()   +32
+A() +0x18  值接收者，经指针调用的是 (*L).A 包装函数
+B() +0x20
+C() +0x28
+D 不在 Ner 中，不占方法表
+由 interface_test.go 的 TestInterfaceItab 通过 internal/methodset.Itab 读取实际的 itab 检查
 */
 
 func (L) A()               {}
@@ -387,41 +388,15 @@ func main() {
 */
 
 /*
-go tool objdump -S  -s "data.Interface" ./yuhen
-
-	var n L = 100
-  0x4f664d		488d050cb41300		LEAQ 0x13b40c(IP), AX
-  0x4f6654		e8278ef1ff		CALL runtime.newobject(SB);  heap alloc
-  0x4f6659		48c70064000000		MOVQ $0x64, 0(AX)
-	testNew(i)
-  0x4f6660		4889c3			MOVQ AX, BX
-  0x4f6663		488d05760e1e00		LEAQ go:itab.*yuhen/data.L,yuhen/data.Ner(SB), AX
-  0x4f666a		e811ffffff		CALL yuhen/data.testNew(SB)
-
-
-
-*/
-
-/*
-func testNew(n Ner) {
-  0x4f6580              493b6610                CMPQ 0x10(R14), SP
-  0x4f6584              7630                    JBE 0x4f65b6
-  0x4f6586              4883ec18                SUBQ $0x18, SP
-  0x4f658a              48896c2410              MOVQ BP, 0x10(SP)
-  0x4f658f              488d6c2410              LEAQ 0x10(SP), BP
-  0x4f6594              4889442420              MOVQ AX, 0x20(SP)
-  0x4f6599              48895c2428              MOVQ BX, 0x28(SP)
-        n.B(9)
-  0x4f659e              488b4820                MOVQ 0x20(AX), CX  	; .tab+32->B
-  0x4f65a2              4889d8                  MOVQ BX, AX        	; .data
-  0x4f65a5              bb09000000              MOVL $0x9, BX		; arg
-  0x4f65aa              ffd1                    CALL CX				; B.(.data, 0x9)
-}
+go build -gcflags "-l" 关闭内联后：
+main 中 var n L 分配在堆上（&n 赋值给接口后逃逸），itab go:itab.*L,Ner 装入 AX、.data 装入 BX 后调用 testNew1
+testNew 从 itab 偏移 0x20 处取出方法 B，以寄存器间接调用 CALL CX，.data 作为接收者放入 AX
+以上由 interface_test.go 的 TestInterfaceDynamicCall 检查
 */
 
-// go build -gcflags "-m" ./data 2>&1|grep interface|grep heap
-// data/interface.go:362:6: moved to heap: n
+// 关闭内联后 main 中的 n 分配在堆上（moved to heap: n），内联后则留在栈上
 // 相比动态调用，内存逃逸才是接口导致的最大性能问题
+// 以上由 interface_test.go 的 TestInterfaceEscape 检查（internal/escape 解析 -gcflags=-m=2 的输出）
 
 type X int
 
@@ -435,6 +410,8 @@ func (f FuncString) String() string {
 func skill() {
 	// 让编译器检查，确保类型实现了指定接口
 	//var _ fmt.Stringer = X(0) // X does not implement fmt.Stringer (missing method String)
+	// 不必手写：tools/cmd/implements 列出所有实现者并生成这种断言文件
+	//   implements -gen stringer_assert.go fmt.Stringer ./data
 
 	// 定义函数类型，包装函数，使其实现特定接口
 	f := func() string {
@@ -443,6 +420,8 @@ func skill() {
 
 	// f 实现了 string这个方法. 所以其可以转换为 fmt.Stringer
 	// 感觉还是比较有用的，后边看看在哪儿可以应用
+	// 对任意接口生成这种适配器（多方法接口生成函数字段组成的结构体）：tools/cmd/funcadapter
+	//   //go:generate funcadapter -o stringer_func.go fmt.Stringer
 	var t fmt.Stringer = FuncString(f)
 	fmt.Println(t)
 }
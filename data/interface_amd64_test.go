@@ -0,0 +1,37 @@
+package data
+
+import (
+	"testing"
+
+	"yuhen/internal/disasm/disasmtest"
+)
+
+func TestInterfaceDynamicCall(t *testing.T) {
+	o := build(t)
+
+	m := o.Func(t, "main")
+	o.AssertAllocs(t, m, disasmtest.LineOf(t, "interface.go", "var n L = 100"))
+	c := m.CallsTo(o.Func(t, "testNew1").Sym)
+	if len(c) != 1 || c[0].Loaded("AX") != "go:itab.*yuhen/data.L,yuhen/data.Ner" {
+		t.Errorf("testNew1 should receive the itab in AX\n%s", m)
+	}
+
+	// 从 itab 中取出方法地址，接收者 .data 移入 AX
+	f := o.Func(t, "testNew")
+	dyn := f.DynamicCalls()
+	if len(dyn) != 1 || dyn[0].String() != "CALL CX" {
+		t.Fatalf("dynamic calls = %v\n%s", dyn, f)
+	}
+	var loadB, recv bool
+	for _, in := range f.Insts {
+		switch in.String() {
+		case "MOVQ 0x20(AX), CX":
+			loadB = true
+		case "MOVQ BX, AX":
+			recv = true
+		}
+	}
+	if !loadB || !recv {
+		t.Errorf("method B not loaded from itab+0x20 or receiver not in AX\n%s", f)
+	}
+}
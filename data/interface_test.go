@@ -0,0 +1,114 @@
+package data
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+
+	"yuhen/internal/escape"
+	"yuhen/internal/escape/escapetest"
+	"yuhen/internal/methodset"
+	"yuhen/internal/methodset/methodsettest"
+	"yuhen/internal/mock"
+)
+
+func TestInterfaceEscape(t *testing.T) {
+	const file = "data/interface.go"
+
+	// 通过接口调用方法，编译器无法知道 n 会不会被保存，参数泄漏但本身不分配
+	escapetest.AssertNoEscape(t, file, "testNew")
+	escapetest.AssertNoEscape(t, file, "testNew1")
+
+	// 内联 testNew1 后去虚拟化，n 留在栈上
+	escapetest.AssertNoEscape(t, file, "main")
+
+	// 关闭内联后 &n 赋值给接口，n 分配在堆上
+	r := escapetest.Load(t, file, "-l")
+	r.AssertHas(t, file, "main", escape.MovedToHeap, "n")
+	r.AssertHas(t, file, "testNew", escape.Leak, "n")
+
+	// 非指针值转换为接口，复制到堆上
+	escapetest.AssertEscapes(t, file, "z3Test", "Z3{}")
+	escapetest.AssertEscapes(t, file, "interfaceConvert", "&N5{}")
+}
+
+func TestInterfaceMethodSets(t *testing.T) {
+	p := methodsettest.Load(t, "./data")
+
+	p.AssertMethods(t, "L", []string{"A"}, []string{"A", "B", "C", "D"})
+	p.AssertSatisfies(t, "L", "Mer", true, true)
+	p.AssertSatisfies(t, "L", "Ner", false, true, "method B has pointer receiver", "method C has pointer receiver")
+
+	// yerTest 中 var t1 Yer = z 的报错
+	p.AssertMethods(t, "Z2", []string{"toString"}, []string{"test", "toString"})
+	p.AssertSatisfies(t, "Z2", "Yer", false, true, "method test has pointer receiver")
+
+	// interfaceConvert 中 var x1 X2er = N5{} 的报错；N5 的 toString 有参数，和 Yer 的签名不同
+	p.AssertSatisfies(t, "N5", "X2er", false, true, "method test has pointer receiver")
+	p.AssertSatisfies(t, "N5", "A1er", true, true)
+	p.AssertSatisfies(t, "N5", "Yer", false, false,
+		"method test has pointer receiver",
+		"wrong type for method toString: have func(s string) string, want func() string")
+}
+
+// 方法表按方法名排序，64 位从 itab+0x18 开始，和 TestInterfaceDynamicCall 中 B 位于 0x20 一致
+func TestInterfaceItab(t *testing.T) {
+	var l L
+	tab, err := methodset.Itab[Ner](&l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ptr := unsafe.Sizeof(uintptr(0)) // inter、_type 各一个指针，hash 之后按指针对齐
+	want := []struct {
+		method string
+		off    uintptr
+		fn     string
+	}{
+		{"A", 3 * ptr, "yuhen/data.(*L).A"}, // 值接收者的包装函数
+		{"B", 4 * ptr, "yuhen/data.(*L).B"},
+		{"C", 5 * ptr, "yuhen/data.(*L).C"},
+	}
+	if len(tab.Entries) != len(want) {
+		t.Fatalf("%s", tab)
+	}
+	for i, w := range want {
+		if e := tab.Entries[i]; e.Method != w.method || e.Offset != w.off || e.Func != w.fn {
+			t.Errorf("entry %d = %+v, want %+v", i, e, w)
+		}
+	}
+	// D 不在 Ner 中，不占方法表
+	if _, ok := tab.Offset("D"); ok {
+		t.Error(tab)
+	}
+
+	// 未导出的方法同样在表中
+	tab, err = methodset.Itab[Yer](&Z2{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if off, ok := tab.Offset("toString"); !ok || off != 4*ptr {
+		t.Errorf("%s", tab)
+	}
+}
+
+//go:generate mockgen -o mock_test.go Ner Mer
+
+func TestInterfaceMock(t *testing.T) {
+	n := NewMockNer(t)
+	n.ExpectB(9) // testNew 固定传 9
+	testNew(n)
+
+	// 嵌入的 Mer.A 和 Ner 自己的方法一样生成
+	var m Mer = n
+	n.ExpectA().Times(2)
+	m.A()
+	m.A()
+
+	n.ExpectC(mock.Match("short", func(s string) bool { return len(s) < 3 })).Do(strings.ToUpper).AnyTimes()
+	if got := n.C("ab"); got != "AB" {
+		t.Errorf("C = %q", got)
+	}
+	if calls := n.Calls(""); len(calls) != 4 || calls[0].String() != "B(9)" || calls[3].String() != `C("ab")` {
+		t.Errorf("calls = %v", calls)
+	}
+}
@@ -0,0 +1,12 @@
+package memview
+
+import "unsafe"
+
+// Add 等同于 unsafe.Add(p, off)，p 和结果都应该指向 s 的元素内存
+// 使用 -tags debug 构建时检查 p 和结果不越出 s，越界时 panic；
+// 否则不做检查，和直接调用 unsafe.Add 一样没有额外开销。
+// 结果不能指向 s 末尾之后：Go 不允许指针越过分配的末尾，哪怕不解引用
+func Add[E any](s []E, p unsafe.Pointer, off int) unsafe.Pointer {
+	checkAdd(s, p, off) // 在计算之前检查，越界的指针本身在 checkptr 下就会报错
+	return unsafe.Add(p, off)
+}
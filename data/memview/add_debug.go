@@ -0,0 +1,22 @@
+//go:build debug
+
+package memview
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// debug 构建：p 和 p+off 都必须在 s 的元素内存之内
+func checkAdd[E any](s []E, p unsafe.Pointer, off int) {
+	var zero E
+	base := uintptr(unsafe.Pointer(unsafe.SliceData(s)))
+	size := uintptr(len(s)) * unsafe.Sizeof(zero)
+	pos := uintptr(p) - base // p 在 base 之前时回绕为很大的数
+	if pos >= size {
+		panic(fmt.Sprintf("memview: Add: %p is outside [%#x, %#x)", p, base, base+size))
+	}
+	if n := int(pos) + off; n < 0 || n >= int(size) {
+		panic(fmt.Sprintf("memview: Add: offset %d from %p is outside [%#x, %#x)", off, p, base, base+size))
+	}
+}
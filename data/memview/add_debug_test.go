@@ -0,0 +1,33 @@
+//go:build debug
+
+package memview
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestAddDebug(t *testing.T) {
+	d := make([]int64, 4)
+	p := unsafe.Pointer(&d[1])
+	for _, c := range []struct {
+		p    unsafe.Pointer
+		off  int
+		want string
+	}{
+		{p, 24, "offset 24"}, // 末尾之后
+		{p, -16, "offset -16"},
+		{unsafe.Pointer(&d[3]), 8, "offset 8"},
+		{unsafe.Pointer(new(int64)), 0, "is outside"},
+	} {
+		func() {
+			defer func() {
+				if r, _ := recover().(string); !strings.Contains(r, c.want) {
+					t.Errorf("Add(%p, %d): recovered %q, want %q", c.p, c.off, r, c.want)
+				}
+			}()
+			Add(d, c.p, c.off)
+		}()
+	}
+}
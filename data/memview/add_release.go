@@ -0,0 +1,8 @@
+//go:build !debug
+
+package memview
+
+import "unsafe"
+
+// 非 debug 构建不检查
+func checkAdd[E any]([]E, unsafe.Pointer, int) {}
@@ -0,0 +1,93 @@
+package memview
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+var (
+	ErrField = errors.New("memview: no such field")
+	ErrType  = errors.New("memview: field type mismatch")
+)
+
+// Field 结构体字段的布局，和 data/struct.go 中 mem() 用 unsafe.Offsetof 打印的一致
+type Field struct {
+	Name   string // 嵌入结构体的字段带路径，如 Point.x
+	Offset uintptr
+	Size   uintptr
+	Type   reflect.Type
+}
+
+func (f Field) String() string {
+	return fmt.Sprintf("%s %s: offset %d, size %d", f.Name, f.Type, f.Offset, f.Size)
+}
+
+// Fields 结构体 T 的字段，按内存顺序；嵌入的结构体展开为其字段，嵌入的指针不展开
+func Fields[T any]() ([]Field, error) {
+	var zero T
+	t := reflect.TypeOf(&zero).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("memview: %s is not a struct", t)
+	}
+	return appendFields(nil, t, "", 0), nil
+}
+
+func appendFields(out []Field, t reflect.Type, prefix string, base uintptr) []Field {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			out = appendFields(out, f.Type, prefix+f.Name+".", base+f.Offset)
+			continue
+		}
+		out = append(out, Field{prefix + f.Name, base + f.Offset, f.Type.Size(), f.Type})
+	}
+	return out
+}
+
+// Offset 字段 name 的偏移，name 可以是 Point.x 这样的路径，也可以是提升的 x
+// 提升经过嵌入的指针时没有固定的偏移，返回错误
+func Offset[T any](name string) (Field, error) {
+	var zero T
+	t := reflect.TypeOf(&zero).Elem()
+	if t.Kind() != reflect.Struct {
+		return Field{}, fmt.Errorf("memview: %s is not a struct", t)
+	}
+	var off uintptr
+	var sf reflect.StructField
+	for _, part := range strings.Split(name, ".") {
+		if t.Kind() != reflect.Struct {
+			return Field{}, fmt.Errorf("%w: %s in %T", ErrField, name, zero)
+		}
+		var ok bool
+		if sf, ok = t.FieldByName(part); !ok {
+			return Field{}, fmt.Errorf("%w: %s in %T", ErrField, name, zero)
+		}
+		// 提升的字段：沿着嵌入路径累加偏移
+		ft := t
+		for _, i := range sf.Index {
+			if ft.Kind() != reflect.Struct {
+				return Field{}, fmt.Errorf("memview: %s in %T is promoted through a pointer", name, zero)
+			}
+			f := ft.Field(i)
+			off += f.Offset
+			ft = f.Type
+		}
+		t = sf.Type
+	}
+	return Field{name, off, sf.Type.Size(), sf.Type}, nil
+}
+
+// FieldPtr p 指向的结构体中字段 name 的指针，F 必须和字段类型相同，未导出的字段同样可以访问
+func FieldPtr[F, T any](p *T, name string) (*F, error) {
+	f, err := Offset[T](name)
+	if err != nil {
+		return nil, err
+	}
+	if want := reflect.TypeOf((*F)(nil)).Elem(); f.Type != want {
+		return nil, fmt.Errorf("%w: %s is %s, not %s", ErrType, name, f.Type, want)
+	}
+	return (*F)(unsafe.Add(unsafe.Pointer(p), f.Offset)), nil
+}
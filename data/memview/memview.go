@@ -0,0 +1,134 @@
+// Package memview 在字节缓冲区上按类型读写，把 data/pointer.go 中 unsafe 的用法包装成带检查的函数
+//
+// Go 不允许 p1++ 和 (*int)(p1)，只能借助 unsafe.Pointer 和 unsafe.Add，写错了就是越界读写。
+// 这里每次访问都检查边界，返回指针时还检查对齐；含指针的类型不能放进字节缓冲区（GC 看不到其中的指针），直接拒绝。
+// 结构体字段的偏移见 field.go，带检查的指针运算见 add.go
+package memview
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"unsafe"
+)
+
+var (
+	ErrBounds   = errors.New("memview: out of bounds")
+	ErrAlign    = errors.New("memview: misaligned")
+	ErrPointers = errors.New("memview: type contains pointers")
+)
+
+// View 把 buf 看作 T 的存储，按字节偏移读写
+type View[T any] struct {
+	buf   []byte
+	size  int
+	align uintptr
+}
+
+// Of 创建 buf 上的 T 视图，T 不能包含指针
+func Of[T any](buf []byte) (View[T], error) {
+	var zero T
+	if t := reflect.TypeOf(&zero).Elem(); hasPointers(t) {
+		return View[T]{}, fmt.Errorf("%w: %s", ErrPointers, t)
+	}
+	return View[T]{buf, int(unsafe.Sizeof(zero)), unsafe.Alignof(zero)}, nil
+}
+
+// Len buf 能容纳的 T 的个数
+func (v View[T]) Len() int {
+	if v.size == 0 {
+		return 0
+	}
+	return len(v.buf) / v.size
+}
+
+// Bytes 底层的缓冲区
+func (v View[T]) Bytes() []byte {
+	return v.buf
+}
+
+func (v View[T]) check(off int) error {
+	if off < 0 || off > len(v.buf)-v.size {
+		return fmt.Errorf("%w: offset %d, size %d, len %d", ErrBounds, off, v.size, len(v.buf))
+	}
+	return nil
+}
+
+// Get 复制 off 处的 T，不要求对齐
+func (v View[T]) Get(off int) (x T, err error) {
+	if err := v.check(off); err != nil {
+		return x, err
+	}
+	copy(bytesOf(&x), v.buf[off:])
+	return x, nil
+}
+
+// Set 把 x 写入 off 处，不要求对齐
+func (v View[T]) Set(off int, x T) error {
+	if err := v.check(off); err != nil {
+		return err
+	}
+	copy(v.buf[off:], bytesOf(&x))
+	return nil
+}
+
+// Ptr off 处的 *T，和 buf 共享内存，off 处的地址必须按 T 对齐
+// 有的平台不支持非对齐访问，x86 上也可能跨缓存行，所以不论平台都要求对齐
+func (v View[T]) Ptr(off int) (*T, error) {
+	if err := v.check(off); err != nil {
+		return nil, err
+	}
+	if v.size == 0 {
+		return new(T), nil
+	}
+	p := unsafe.Pointer(&v.buf[off])
+	if uintptr(p)%v.align != 0 {
+		return nil, fmt.Errorf("%w: %p is not %d-byte aligned", ErrAlign, p, v.align)
+	}
+	return (*T)(p), nil
+}
+
+// Index 第 i 个 T，即 Ptr(i * sizeof(T))
+func (v View[T]) Index(i int) (*T, error) {
+	if i < 0 || i >= v.Len() {
+		return nil, fmt.Errorf("%w: index %d, len %d", ErrBounds, i, v.Len())
+	}
+	return v.Ptr(i * v.size)
+}
+
+// Slice 把整个 buf 看作 []T，多余的尾部字节忽略，buf 的起始地址必须对齐
+func (v View[T]) Slice() ([]T, error) {
+	n := v.Len()
+	if n == 0 {
+		return nil, nil
+	}
+	p, err := v.Ptr(0)
+	if err != nil {
+		return nil, err
+	}
+	return unsafe.Slice(p, n), nil
+}
+
+// bytesOf x 的内存，T 不含指针时才能安全地按字节复制
+func bytesOf[T any](x *T) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(x)), unsafe.Sizeof(*x))
+}
+
+// hasPointers t 的内存中是否有指针（包括字符串、切片、接口等的数据指针）
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	case reflect.Pointer, reflect.UnsafePointer, reflect.String, reflect.Slice, reflect.Map,
+		reflect.Chan, reflect.Func, reflect.Interface:
+		return true
+	}
+	return false
+}
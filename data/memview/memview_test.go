@@ -0,0 +1,203 @@
+package memview
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+type header struct {
+	Magic uint32
+	Flags uint16
+	Kind  uint8
+	_     uint8
+	Size  uint64
+}
+
+// aligned 8 字节对齐的缓冲区；make([]byte) 可能分配在栈上，不保证对齐
+func aligned(n int) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(&make([]uint64, (n+7)/8)[0])), n)
+}
+
+func TestView(t *testing.T) {
+	buf := aligned(32)
+	v, err := Of[header](buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Len() != 2 {
+		t.Fatal(v.Len())
+	}
+	h := header{Magic: 0xcafe, Flags: 3, Kind: 1, Size: 1 << 40}
+	if err := v.Set(16, h); err != nil {
+		t.Fatal(err)
+	}
+	p, err := v.Index(1)
+	if err != nil || *p != h {
+		t.Fatal(p, err)
+	}
+	// 指针和 buf 共享内存
+	p.Size++
+	if got, _ := v.Get(16); got.Size != h.Size+1 {
+		t.Error(got)
+	}
+
+	for _, off := range []int{-1, 17, 32} {
+		if _, err := v.Get(off); !errors.Is(err, ErrBounds) {
+			t.Errorf("Get(%d): %v", off, err)
+		}
+		if err := v.Set(off, h); !errors.Is(err, ErrBounds) {
+			t.Errorf("Set(%d): %v", off, err)
+		}
+	}
+	if _, err := v.Index(2); !errors.Is(err, ErrBounds) {
+		t.Error(err)
+	}
+
+	// Get/Set 不要求对齐，Ptr 要求
+	u, _ := Of[uint32](buf)
+	if err := u.Set(1, 0x01020304); err != nil {
+		t.Fatal(err)
+	}
+	if x, err := u.Get(1); err != nil || x != 0x01020304 {
+		t.Error(x, err)
+	}
+	if _, err := u.Ptr(1); !errors.Is(err, ErrAlign) {
+		t.Error(err)
+	}
+	if _, err := u.Ptr(4); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSlice(t *testing.T) {
+	buf := aligned(18)
+	v, _ := Of[uint64](buf)
+	s, err := v.Slice()
+	if err != nil || len(s) != 2 {
+		t.Fatal(s, err)
+	}
+	s[1] = 7
+	if x, _ := v.Get(8); x != 7 {
+		t.Error(x)
+	}
+	// 起始地址没有对齐
+	if _, err := must(Of[uint64](buf[1:])).Slice(); !errors.Is(err, ErrAlign) {
+		t.Error(err)
+	}
+}
+
+func must[T any](v View[T], err error) View[T] {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func TestPointers(t *testing.T) {
+	buf := make([]byte, 64)
+	for _, err := range []error{
+		errOf[*int](buf),
+		errOf[string](buf),
+		errOf[[2][]byte](buf),
+		errOf[struct {
+			a int
+			b any
+		}](buf),
+	} {
+		if !errors.Is(err, ErrPointers) {
+			t.Error(err)
+		}
+	}
+	if err := errOf[[0]*int](buf); err != nil {
+		t.Error(err)
+	}
+}
+
+func errOf[T any](buf []byte) error {
+	_, err := Of[T](buf)
+	return err
+}
+
+// 和 data/struct.go mem() 中的 Value 相同
+type point struct {
+	x, y int
+}
+
+type value struct {
+	id   int
+	name string
+	data []byte
+	next *value
+	point
+}
+
+func TestFields(t *testing.T) {
+	var v value
+	fs, err := Fields[value]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Field{
+		{"id", unsafe.Offsetof(v.id), unsafe.Sizeof(v.id), reflect.TypeOf(v.id)},
+		{"name", unsafe.Offsetof(v.name), unsafe.Sizeof(v.name), reflect.TypeOf(v.name)},
+		{"data", unsafe.Offsetof(v.data), unsafe.Sizeof(v.data), reflect.TypeOf(v.data)},
+		{"next", unsafe.Offsetof(v.next), unsafe.Sizeof(v.next), reflect.TypeOf(v.next)},
+		{"point.x", unsafe.Offsetof(v.point) + unsafe.Offsetof(v.point.x), unsafe.Sizeof(v.x), reflect.TypeOf(v.x)},
+		{"point.y", unsafe.Offsetof(v.point) + unsafe.Offsetof(v.point.y), unsafe.Sizeof(v.y), reflect.TypeOf(v.y)},
+	}
+	if !reflect.DeepEqual(fs, want) {
+		t.Errorf("Fields =\n%v\nwant\n%v", fs, want)
+	}
+
+	// 提升的名字和路径得到同一个字段
+	y, err := FieldPtr[int](&v, "y")
+	if err != nil || y != &v.y {
+		t.Fatal(y, err)
+	}
+	if p, _ := FieldPtr[int](&v, "point.y"); p != y {
+		t.Error(p, y)
+	}
+	name, _ := FieldPtr[string](&v, "name")
+	*name = "test"
+	if v.name != "test" {
+		t.Error(v.name)
+	}
+
+	if _, err := FieldPtr[int](&v, "name"); !errors.Is(err, ErrType) {
+		t.Error(err)
+	}
+	if _, err := FieldPtr[int](&v, "z"); !errors.Is(err, ErrField) {
+		t.Error(err)
+	}
+	if _, err := FieldPtr[int](&v, "id.x"); !errors.Is(err, ErrField) {
+		t.Error(err)
+	}
+
+	// 经过嵌入的指针提升，没有固定偏移
+	type outer struct {
+		n int
+		*point
+	}
+	if _, err := Offset[outer]("x"); err == nil {
+		t.Error("promoted through pointer")
+	}
+	if _, err := Fields[int](); err == nil {
+		t.Error("Fields of int")
+	}
+}
+
+func TestAdd(t *testing.T) {
+	// data/pointer.go 中的 p6++，改用 Add
+	d := [...]int{1, 2, 3}
+	p := Add(d[:], unsafe.Pointer(&d[0]), int(unsafe.Sizeof(d[0])))
+	*(*int)(p) += 100
+	if d != [...]int{1, 102, 3} {
+		t.Error(d)
+	}
+	// 向前移动
+	if q := Add(d[:], p, -int(unsafe.Sizeof(d[0]))); q != unsafe.Pointer(&d[0]) {
+		t.Error(q)
+	}
+}
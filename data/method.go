@@ -159,6 +159,7 @@ func (T2) A()  {} //导出成员，否则反射无法获取
 func (T2) B()  {} //导出成员，否则反射无法获取
 func (*T2) C() {} //导出成员，否则反射无法获取
 func (*T2) D() {} //导出成员，否则反射无法获取
+// show 只能列出导出的方法，完整的方法集以及接口实现的检查见 internal/methodset
 func show(i interface{}) {
 	t := reflect.TypeOf(i)
 	for i := 0; i < t.NumMethod(); i++ {
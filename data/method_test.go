@@ -0,0 +1,14 @@
+package data
+
+import (
+	"testing"
+
+	"yuhen/internal/methodset/methodsettest"
+)
+
+// set() 中的 show(n)、show(p) 只能列出导出的方法，这里检查完整的方法集
+func TestT2MethodSet(t *testing.T) {
+	p := methodsettest.Load(t, "./data")
+	p.AssertMethods(t, "T2", []string{"A", "B"}, []string{"A", "B", "C", "D"})
+	p.AssertSatisfies(t, "T2", "Xer", false, true, "method C has pointer receiver")
+}
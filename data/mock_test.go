@@ -0,0 +1,193 @@
+// Code generated by mockgen; DO NOT EDIT.
+
+package data
+
+import (
+	"testing"
+	"yuhen/internal/mock"
+)
+
+// MockNer Ner 的 mock
+type MockNer struct {
+	mock *mock.Mock
+}
+
+// NewMockNer 创建 MockNer，测试结束时检查没有满足的期望
+func NewMockNer(tb testing.TB) *MockNer {
+	return &MockNer{mock.New(tb, "MockNer")}
+}
+
+// Calls 方法 method 的调用记录，method 为空时返回全部
+func (m *MockNer) Calls(method string) []mock.Call {
+	return m.mock.Calls(method)
+}
+
+// A 记录调用，返回匹配的期望设置的值
+func (m *MockNer) A() {
+	m.mock.Call("A")
+}
+
+// ExpectA 期望调用 A，参数可以是值或者 mock.Matcher
+func (m *MockNer) ExpectA() MockNerACall {
+	return MockNerACall{m.mock.Expect("A")}
+}
+
+// MockNerACall A 的期望
+type MockNerACall struct {
+	*mock.Expect
+}
+
+// Do 调用时执行 f，以 f 的结果作为返回值
+func (c MockNerACall) Do(f func()) MockNerACall {
+	c.Expect.Do(func(args []any) []any {
+		f()
+		return nil
+	})
+	return c
+}
+
+// Times 恰好调用 n 次
+func (c MockNerACall) Times(n int) MockNerACall {
+	c.Expect.Times(n)
+	return c
+}
+
+// AnyTimes 调用任意次
+func (c MockNerACall) AnyTimes() MockNerACall {
+	c.Expect.AnyTimes()
+	return c
+}
+
+// B 记录调用，返回匹配的期望设置的值
+func (m *MockNer) B(p0 int) {
+	m.mock.Call("B", p0)
+}
+
+// ExpectB 期望调用 B，参数可以是值或者 mock.Matcher
+func (m *MockNer) ExpectB(p0 any) MockNerBCall {
+	return MockNerBCall{m.mock.Expect("B", p0)}
+}
+
+// MockNerBCall B 的期望
+type MockNerBCall struct {
+	*mock.Expect
+}
+
+// Do 调用时执行 f，以 f 的结果作为返回值
+func (c MockNerBCall) Do(f func(int)) MockNerBCall {
+	c.Expect.Do(func(args []any) []any {
+		f(mock.At[int](args, 0))
+		return nil
+	})
+	return c
+}
+
+// Times 恰好调用 n 次
+func (c MockNerBCall) Times(n int) MockNerBCall {
+	c.Expect.Times(n)
+	return c
+}
+
+// AnyTimes 调用任意次
+func (c MockNerBCall) AnyTimes() MockNerBCall {
+	c.Expect.AnyTimes()
+	return c
+}
+
+// C 记录调用，返回匹配的期望设置的值
+func (m *MockNer) C(p0 string) string {
+	rets := m.mock.Call("C", p0)
+	return mock.At[string](rets, 0)
+}
+
+// ExpectC 期望调用 C，参数可以是值或者 mock.Matcher
+func (m *MockNer) ExpectC(p0 any) MockNerCCall {
+	return MockNerCCall{m.mock.Expect("C", p0)}
+}
+
+// MockNerCCall C 的期望
+type MockNerCCall struct {
+	*mock.Expect
+}
+
+// Return 设置返回值
+func (c MockNerCCall) Return(r0 string) MockNerCCall {
+	c.Expect.Return(r0)
+	return c
+}
+
+// Do 调用时执行 f，以 f 的结果作为返回值
+func (c MockNerCCall) Do(f func(string) string) MockNerCCall {
+	c.Expect.Do(func(args []any) []any {
+		r0 := f(mock.At[string](args, 0))
+		return []any{r0}
+	})
+	return c
+}
+
+// Times 恰好调用 n 次
+func (c MockNerCCall) Times(n int) MockNerCCall {
+	c.Expect.Times(n)
+	return c
+}
+
+// AnyTimes 调用任意次
+func (c MockNerCCall) AnyTimes() MockNerCCall {
+	c.Expect.AnyTimes()
+	return c
+}
+
+var _ Ner = (*MockNer)(nil)
+
+// MockMer Mer 的 mock
+type MockMer struct {
+	mock *mock.Mock
+}
+
+// NewMockMer 创建 MockMer，测试结束时检查没有满足的期望
+func NewMockMer(tb testing.TB) *MockMer {
+	return &MockMer{mock.New(tb, "MockMer")}
+}
+
+// Calls 方法 method 的调用记录，method 为空时返回全部
+func (m *MockMer) Calls(method string) []mock.Call {
+	return m.mock.Calls(method)
+}
+
+// A 记录调用，返回匹配的期望设置的值
+func (m *MockMer) A() {
+	m.mock.Call("A")
+}
+
+// ExpectA 期望调用 A，参数可以是值或者 mock.Matcher
+func (m *MockMer) ExpectA() MockMerACall {
+	return MockMerACall{m.mock.Expect("A")}
+}
+
+// MockMerACall A 的期望
+type MockMerACall struct {
+	*mock.Expect
+}
+
+// Do 调用时执行 f，以 f 的结果作为返回值
+func (c MockMerACall) Do(f func()) MockMerACall {
+	c.Expect.Do(func(args []any) []any {
+		f()
+		return nil
+	})
+	return c
+}
+
+// Times 恰好调用 n 次
+func (c MockMerACall) Times(n int) MockMerACall {
+	c.Expect.Times(n)
+	return c
+}
+
+// AnyTimes 调用任意次
+func (c MockMerACall) AnyTimes() MockMerACall {
+	c.Expect.AnyTimes()
+	return c
+}
+
+var _ Mer = (*MockMer)(nil)
@@ -0,0 +1,112 @@
+package numeric
+
+import "golang.org/x/exp/constraints"
+
+// Add a+b，溢出时 ok 为 false，结果为回绕后的值
+func Add[T constraints.Integer](a, b T) (T, bool) {
+	return addGo(a, b)
+}
+
+// Sub a-b，溢出时 ok 为 false
+func Sub[T constraints.Integer](a, b T) (T, bool) {
+	return subGo(a, b)
+}
+
+// Mul a*b，溢出时 ok 为 false
+// 64 位有符号整数在 amd64 上由 IMULQ 的 OF 标志判断，省掉验证用的除法
+func Mul[T constraints.Integer](a, b T) (T, bool) {
+	if is64[T]() {
+		c, ok := mul64(int64(a), int64(b))
+		return T(c), ok
+	}
+	return mulGo(a, b)
+}
+
+// addGo、subGo 可以内联，比调用汇编更快；mulGo 是没有汇编时的实现，差分测试用它和汇编对比
+
+func addGo[T constraints.Integer](a, b T) (T, bool) {
+	c := a + b
+	if signed[T]() {
+		// b 为负时结果必然变小，否则不变小，违反即溢出
+		return c, (c < a) == (b < 0)
+	}
+	return c, c >= a
+}
+
+func subGo[T constraints.Integer](a, b T) (T, bool) {
+	c := a - b
+	if signed[T]() {
+		return c, (c > a) == (b < 0)
+	}
+	return c, a >= b
+}
+
+func mulGo[T constraints.Integer](a, b T) (T, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if signed[T]() {
+		// MinOf / -1 在 Go 中结果仍为 MinOf，不能用除法验证，单独判断
+		min, neg := MinOf[T](), ^T(0) // 有符号的全 1 即 -1
+		if a == neg && b == min || b == neg && a == min {
+			return c, false
+		}
+	}
+	return c, c/b == a
+}
+
+// SatAdd a+b，溢出时取最大或最小值
+func SatAdd[T constraints.Integer](a, b T) T {
+	c, ok := Add(a, b)
+	if ok {
+		return c
+	}
+	if b < 0 {
+		return MinOf[T]()
+	}
+	return MaxOf[T]()
+}
+
+// SatSub a-b，溢出时取最大或最小值，无符号数不小于 0
+func SatSub[T constraints.Integer](a, b T) T {
+	c, ok := Sub(a, b)
+	if ok {
+		return c
+	}
+	if b < 0 {
+		return MaxOf[T]()
+	}
+	return MinOf[T]()
+}
+
+// SatMul a*b，溢出时按结果的符号取最大或最小值
+func SatMul[T constraints.Integer](a, b T) T {
+	c, ok := Mul(a, b)
+	if ok {
+		return c
+	}
+	if (a < 0) != (b < 0) {
+		return MinOf[T]()
+	}
+	return MaxOf[T]()
+}
+
+// Convert 整数类型之间的转换，值超出 To 的范围时 ok 为 false，结果为截断后的值
+// 转换回 From 能还原且符号不变，说明没有丢失信息
+func Convert[To, From constraints.Integer](v From) (To, bool) {
+	t := To(v)
+	return t, From(t) == v && (t < 0) == (v < 0)
+}
+
+// Clamp 转换到 To，超出范围时取最接近的边界值
+func Clamp[To, From constraints.Integer](v From) To {
+	t, ok := Convert[To](v)
+	if ok {
+		return t
+	}
+	if v < 0 {
+		return MinOf[To]()
+	}
+	return MaxOf[To]()
+}
@@ -0,0 +1,8 @@
+//go:build !purego
+
+package numeric
+
+// 汇编实现见 checked_amd64.s，IMULQ 结果截断时设置 OF，SETOC 取反后即为 ok
+// 汇编函数不能内联，加减法的 Go 版本只需一次比较，调用汇编反而更慢，见 BenchmarkMul
+
+func mul64(a, b int64) (int64, bool)
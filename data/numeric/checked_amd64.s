@@ -0,0 +1,12 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// func mul64(a, b int64) (int64, bool)
+TEXT ·mul64(SB), NOSPLIT, $0-25
+    MOVQ a+0(FP), AX
+    MOVQ b+8(FP), BX
+    IMULQ BX, AX // 128 位的结果截断为 64 位时设置 OF
+    SETOC ret1+24(FP)
+    MOVQ AX, ret+16(FP)
+    RET
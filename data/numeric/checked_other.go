@@ -0,0 +1,5 @@
+//go:build !amd64 || purego
+
+package numeric
+
+func mul64(a, b int64) (int64, bool) { return mulGo(a, b) }
@@ -0,0 +1,51 @@
+// Package numeric 基于 golang.org/x/exp/constraints 的数值运算
+//
+// data/generic.go 中手写的 Signed、Integer 等类型集合直接使用 constraints 中的定义，
+// 本包在此之上提供检查溢出的整数运算、饱和运算、整数宽度之间的安全转换以及统计函数。
+// amd64 上 int64 宽度的 Mul 由汇编实现，通过 OF 标志判断溢出
+package numeric
+
+import (
+	"unsafe"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Number 可以参与算术运算且有序的数值类型
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+// bits T 的位数
+func bits[T constraints.Integer]() uint {
+	var zero T
+	return uint(unsafe.Sizeof(zero)) * 8
+}
+
+// signed T 是否为有符号整数，无符号的 0-1 回绕为最大值
+func signed[T constraints.Integer]() bool {
+	var zero T
+	return zero-1 < 0
+}
+
+// MaxOf T 的最大值
+func MaxOf[T constraints.Integer]() T {
+	if signed[T]() {
+		return T(1)<<(bits[T]()-1) - 1
+	}
+	var zero T
+	return ^zero
+}
+
+// MinOf T 的最小值，无符号为 0
+func MinOf[T constraints.Integer]() T {
+	if signed[T]() {
+		return -MaxOf[T]() - 1
+	}
+	return 0
+}
+
+// is64 T 为 64 位有符号整数，可以走 int64 的汇编实现
+func is64[T constraints.Integer]() bool {
+	return bits[T]() == 64 && signed[T]()
+}
@@ -0,0 +1,210 @@
+package numeric
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"golang.org/x/exp/constraints"
+)
+
+// 以 math/big 的精确结果为准：结果在 T 的范围内时 ok 为 true 且值相同，否则 ok 为 false 且值为回绕结果
+
+func bigOf[T constraints.Integer](v T) *big.Int {
+	if signed[T]() {
+		return big.NewInt(int64(v))
+	}
+	return new(big.Int).SetUint64(uint64(v))
+}
+
+func inRange[T constraints.Integer](x *big.Int) bool {
+	return x.Cmp(bigOf(MinOf[T]())) >= 0 && x.Cmp(bigOf(MaxOf[T]())) <= 0
+}
+
+func clampBig[T constraints.Integer](x *big.Int) T {
+	switch {
+	case x.Cmp(bigOf(MinOf[T]())) < 0:
+		return MinOf[T]()
+	case x.Cmp(bigOf(MaxOf[T]())) > 0:
+		return MaxOf[T]()
+	}
+	if signed[T]() {
+		return T(x.Int64())
+	}
+	return T(x.Uint64())
+}
+
+type op[T constraints.Integer] struct {
+	name    string
+	checked func(a, b T) (T, bool)
+	sat     func(a, b T) T
+	wrap    func(a, b T) T
+	exact   func(z, x, y *big.Int) *big.Int
+}
+
+func ops[T constraints.Integer]() []op[T] {
+	return []op[T]{
+		{"Add", Add[T], SatAdd[T], func(a, b T) T { return a + b }, (*big.Int).Add},
+		{"Sub", Sub[T], SatSub[T], func(a, b T) T { return a - b }, (*big.Int).Sub},
+		{"Mul", Mul[T], SatMul[T], func(a, b T) T { return a * b }, (*big.Int).Mul},
+		// int64 时 Mul 走汇编，纯 Go 版本同样要和 big 一致
+		{"mulGo", mulGo[T], nil, func(a, b T) T { return a * b }, (*big.Int).Mul},
+	}
+}
+
+func checkArith[T constraints.Integer](t *testing.T, a, b T) {
+	t.Helper()
+	for _, o := range ops[T]() {
+		exact := o.exact(new(big.Int), bigOf(a), bigOf(b))
+		got, ok := o.checked(a, b)
+		if ok != inRange[T](exact) || got != o.wrap(a, b) {
+			t.Fatalf("%s[%T](%d, %d) = %d, %v; exact %s", o.name, a, a, b, got, ok, exact)
+		}
+		if o.sat == nil {
+			continue
+		}
+		if got, want := o.sat(a, b), clampBig[T](exact); got != want {
+			t.Fatalf("Sat%s[%T](%d, %d) = %d, want %d", o.name, a, a, b, got, want)
+		}
+	}
+}
+
+func seed(f *testing.F) {
+	edges := []int64{0, 1, -1, 2, -2, 127, -128, 255, 32767, -32768, math.MaxInt32, math.MinInt32,
+		math.MaxUint32, math.MaxInt64, math.MinInt64, math.MaxInt64 - 1, math.MinInt64 + 1, 1 << 32, 3037000500}
+	for _, a := range edges {
+		for _, b := range edges {
+			f.Add(a, b)
+		}
+	}
+}
+
+func FuzzArith(f *testing.F) {
+	seed(f)
+	f.Fuzz(func(t *testing.T, a, b int64) {
+		checkArith(t, int8(a), int8(b))
+		checkArith(t, int16(a), int16(b))
+		checkArith(t, int32(a), int32(b))
+		checkArith(t, a, b)
+		checkArith(t, int(a), int(b))
+		checkArith(t, uint8(a), uint8(b))
+		checkArith(t, uint16(a), uint16(b))
+		checkArith(t, uint32(a), uint32(b))
+		checkArith(t, uint64(a), uint64(b))
+		checkArith(t, uintptr(a), uintptr(b))
+	})
+}
+
+func checkConvert[To, From constraints.Integer](t *testing.T, v From) {
+	t.Helper()
+	x := bigOf(v)
+	got, ok := Convert[To](v)
+	if ok != inRange[To](x) || got != To(v) {
+		t.Fatalf("Convert[%T](%T(%d)) = %d, %v", got, v, v, got, ok)
+	}
+	if got, want := Clamp[To](v), clampBig[To](x); got != want {
+		t.Fatalf("Clamp[%T](%T(%d)) = %d, want %d", got, v, v, got, want)
+	}
+}
+
+func convertAll[From constraints.Integer](t *testing.T, v From) {
+	t.Helper()
+	checkConvert[int8](t, v)
+	checkConvert[int16](t, v)
+	checkConvert[int32](t, v)
+	checkConvert[int64](t, v)
+	checkConvert[uint8](t, v)
+	checkConvert[uint16](t, v)
+	checkConvert[uint32](t, v)
+	checkConvert[uint64](t, v)
+}
+
+func FuzzConvert(f *testing.F) {
+	seed(f)
+	f.Fuzz(func(t *testing.T, a, _ int64) {
+		convertAll(t, a)
+		convertAll(t, uint64(a))
+		convertAll(t, int32(a))
+		convertAll(t, uint16(a))
+	})
+}
+
+type myInt int16
+
+func TestBounds(t *testing.T) {
+	if MaxOf[int8]() != math.MaxInt8 || MinOf[int8]() != math.MinInt8 ||
+		MaxOf[int64]() != math.MaxInt64 || MinOf[int64]() != math.MinInt64 ||
+		MaxOf[uint32]() != math.MaxUint32 || MinOf[uint32]() != 0 ||
+		MaxOf[myInt]() != math.MaxInt16 {
+		t.Error("bounds")
+	}
+	// ~int16 的类型也适用
+	if v, ok := Add[myInt](math.MaxInt16, 1); ok || v != math.MinInt16 {
+		t.Error(v, ok)
+	}
+	if v := SatMul[myInt](-300, 300); v != math.MinInt16 {
+		t.Error(v)
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b))
+}
+
+func TestStats(t *testing.T) {
+	s := []int{2, 4, 4, 4, 5, 5, 7, 9}
+	if m, v, sd := Mean(s), Variance(s), StdDev(s); m != 5 || v != 4 || sd != 2 {
+		t.Error(m, v, sd)
+	}
+	// 乱序输入，插值方式和 numpy.percentile 相同
+	f := []float64{15, 20, 35, 40, 50}
+	for _, c := range []struct{ p, want float64 }{{0, 15}, {25, 20}, {40, 29}, {50, 35}, {90, 46}, {100, 50}} {
+		if got := Percentile([]float64{50, 15, 40, 20, 35}, c.p); !near(got, c.want) {
+			t.Errorf("Percentile(%v) = %v, want %v", c.p, got, c.want)
+		}
+	}
+	if got := Percentiles(f, 25, 75); got[0] != 20 || got[1] != 40 || Median(f) != 35 {
+		t.Error(got)
+	}
+	if !math.IsNaN(Mean([]int{})) || !math.IsNaN(Median([]uint8{})) {
+		t.Error("empty")
+	}
+
+	// 大偏移量下方差仍然准确，朴素的 E[x²]-E[x]² 会因相消得到 0 或负数
+	big := []float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16}
+	if v := Variance(big); !near(v, 22.5) {
+		t.Error(v)
+	}
+	// 整数求和不会溢出
+	if m := Mean([]int8{100, 100, 100}); m != 100 {
+		t.Error(m)
+	}
+}
+
+var sink int64
+
+func BenchmarkMul(b *testing.B) {
+	b.Run("asm", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sink, _ = mul64(int64(i), sink|1)
+		}
+	})
+	b.Run("go", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sink, _ = mulGo(int64(i), sink|1)
+		}
+	})
+}
+
+// go test -bench . -benchmem ./data/numeric
+/*
+goos: linux
+goarch: amd64
+pkg: yuhen/data/numeric
+cpu: Intel(R) Xeon(R) Processor
+BenchmarkMul/asm         	294707391	         4.124 ns/op	       0 B/op	       0 allocs/op
+BenchmarkMul/go          	248030124	         6.053 ns/op	       0 B/op	       0 allocs/op
+
+mulGo 需要一次 64 位除法验证结果，汇编版本虽然多一次不能内联的调用，仍然更快
+加法的汇编版本测过 3.1ns，内联的 addGo 2.9ns，所以 Add、Sub 不走汇编
+*/
@@ -0,0 +1,80 @@
+package numeric
+
+import (
+	"math"
+	"sort"
+)
+
+// 统计函数的结果都是 float64，整数先转换再计算，避免求和溢出；空切片返回 NaN
+
+// Mean 算术平均值
+func Mean[T Number](s []T) float64 {
+	m, _ := meanVar(s)
+	return m
+}
+
+// Variance 总体方差（除以 n），样本方差乘以 n/(n-1) 即可
+func Variance[T Number](s []T) float64 {
+	_, v := meanVar(s)
+	return v
+}
+
+// StdDev 总体标准差
+func StdDev[T Number](s []T) float64 {
+	return math.Sqrt(Variance(s))
+}
+
+// meanVar Welford 算法，一次遍历，相比先求平方和再相减不会因为大数相消丢失精度
+func meanVar[T Number](s []T) (mean, variance float64) {
+	if len(s) == 0 {
+		return math.NaN(), math.NaN()
+	}
+	var m2 float64
+	for i, v := range s {
+		x := float64(v)
+		d := x - mean
+		mean += d / float64(i+1)
+		m2 += d * (x - mean)
+	}
+	return mean, m2 / float64(len(s))
+}
+
+// Percentile 第 p 百分位数（0 <= p <= 100），在相邻两个值之间线性插值
+// 和 numpy.percentile 的默认方法相同；不修改 s
+func Percentile[T Number](s []T, p float64) float64 {
+	return Percentiles(s, p)[0]
+}
+
+// Percentiles 一次排序计算多个百分位数
+func Percentiles[T Number](s []T, ps ...float64) []float64 {
+	out := make([]float64, len(ps))
+	if len(s) == 0 {
+		for i := range out {
+			out[i] = math.NaN()
+		}
+		return out
+	}
+	sorted := make([]float64, len(s))
+	for i, v := range s {
+		sorted[i] = float64(v)
+	}
+	sort.Float64s(sorted)
+	for i, p := range ps {
+		if p < 0 || p > 100 || math.IsNaN(p) {
+			panic("numeric: percentile out of range [0, 100]")
+		}
+		rank := p / 100 * float64(len(sorted)-1)
+		lo := int(rank)
+		if lo == len(sorted)-1 {
+			out[i] = sorted[lo]
+			continue
+		}
+		out[i] = sorted[lo] + (rank-float64(lo))*(sorted[lo+1]-sorted[lo])
+	}
+	return out
+}
+
+// Median 中位数
+func Median[T Number](s []T) float64 {
+	return Percentile(s, 50)
+}
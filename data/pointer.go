@@ -140,6 +140,8 @@ func operator() {
 	p6 = (*int)(unsafe.Add(unsafe.Pointer(p6), unsafe.Sizeof(p5[0])))
 	*p6 += 100
 	fmt.Println(d) // [1 102 3], 这尼玛就离谱
+	// 写错偏移就是越界读写，data/memview 的 Add 在 -tags debug 构建时检查边界，
+	// View[T] 在字节缓冲区上按类型读写并检查边界和对齐
 }
 
 // 简单开始下
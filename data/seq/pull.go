@@ -0,0 +1,71 @@
+package seq
+
+// Puller 拉取式的迭代器，由 Pull 创建
+// Seq 在单独的 goroutine 中运行，每次 Next 让它前进一步；
+// 不再需要时必须调用 Stop，否则该 goroutine 一直阻塞在 yield 中
+type Puller[T any] struct {
+	next  chan bool     // true 取下一个，false 停止
+	items chan T        // Seq 产生的元素
+	done  chan struct{} // Seq 返回后关闭
+	end   bool
+}
+
+// Pull 把推送式的 s 转换为拉取式，第一次调用 Next 时才开始遍历
+func Pull[T any](s Seq[T]) *Puller[T] {
+	p := &Puller[T]{
+		next:  make(chan bool),
+		items: make(chan T),
+		done:  make(chan struct{}),
+	}
+	go func() {
+		defer close(p.done)
+		if !<-p.next {
+			return
+		}
+		s(func(v T) bool {
+			p.items <- v
+			return <-p.next
+		})
+	}()
+	return p
+}
+
+// Next 下一个元素，序列结束或已经 Stop 时 ok 为 false
+func (p *Puller[T]) Next() (v T, ok bool) {
+	if p.end {
+		return v, false
+	}
+	select {
+	case p.next <- true:
+	case <-p.done:
+		p.end = true
+		return v, false
+	}
+	select {
+	case v = <-p.items:
+		return v, true
+	case <-p.done:
+		p.end = true
+		return v, false
+	}
+}
+
+// Stop 停止遍历并等待 goroutine 退出，可以重复调用
+func (p *Puller[T]) Stop() {
+	if !p.end {
+		p.end = true
+		select {
+		case p.next <- false:
+		case <-p.done:
+		}
+	}
+	<-p.done
+}
+
+// Seq 剩余的元素，遍历结束或提前停止时自动 Stop
+func (p *Puller[T]) Seq() Seq[T] {
+	return func(yield func(T) bool) {
+		defer p.Stop()
+		FromNext(p.Next)(yield)
+	}
+}
@@ -0,0 +1,255 @@
+// Package seq 惰性的序列，代替 data/channel.go、data/slice.go 中各处手写的 for range
+//
+// Seq 是推送式的：调用 Seq 时把每个元素交给 yield，yield 返回 false 表示消费方不再需要，
+// Seq 应立即返回。组合子只包装函数，直到终结操作（Collect、Reduce 等）才真正遍历。
+// 需要逐个取值时用 Pull 转换为拉取式的 Next
+package seq
+
+import (
+	"bufio"
+	"context"
+	"sort"
+	"sync"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Seq 推送式的序列
+type Seq[T any] func(yield func(T) bool)
+
+// FromSlice 按顺序产生切片的元素
+func FromSlice[T any](s []T) Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range s {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Values 产生参数列表
+func Values[T any](vs ...T) Seq[T] {
+	return FromSlice(vs)
+}
+
+// Entry map 的键值对
+type Entry[K, V any] struct {
+	Key   K
+	Value V
+}
+
+// FromMap 按键排序产生 map 的键值对，map 的遍历顺序本身是随机的
+// 键在开始遍历时复制，之后对 map 的修改不影响已排序的键
+func FromMap[K constraints.Ordered, V any](m map[K]V) Seq[Entry[K, V]] {
+	return func(yield func(Entry[K, V]) bool) {
+		keys := make([]K, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+		for _, k := range keys {
+			v, ok := m[k]
+			if !ok {
+				continue
+			}
+			if !yield(Entry[K, V]{k, v}) {
+				return
+			}
+		}
+	}
+}
+
+// FromChan 读取 ch 直到关闭
+// 序列不拥有发送方，提前停止后发送方可能阻塞；需要停止生产者时用 Produce
+func FromChan[T any](ch <-chan T) Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// FromScanner 产生 sc 扫描到的每个 token（默认按行），出错时停止，错误由 sc.Err() 取得
+func FromScanner(sc *bufio.Scanner) Seq[string] {
+	return func(yield func(string) bool) {
+		for sc.Scan() {
+			if !yield(sc.Text()) {
+				return
+			}
+		}
+	}
+}
+
+// FromNext 由拉取式的 next 构造序列
+func FromNext[T any](next func() (T, bool)) Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			v, ok := next()
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Produce 在新的 goroutine 中运行 produce，经 channel 把数据交给消费方
+// 消费方提前停止时取消 ctx，并等待 produce 返回后才结束遍历，不会遗留 goroutine。
+// produce 应使用 send 发送，send 返回 false 时立即返回
+func Produce[T any](produce func(ctx context.Context, send func(T) bool)) Seq[T] {
+	return func(yield func(T) bool) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan T)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(ch)
+			produce(ctx, func(v T) bool {
+				select {
+				case ch <- v:
+					return true
+				case <-ctx.Done():
+					return false
+				}
+			})
+		}()
+		defer wg.Wait()
+		defer cancel()
+
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Map 对每个元素调用 f
+func Map[T, R any](s Seq[T], f func(T) R) Seq[R] {
+	return func(yield func(R) bool) {
+		s(func(v T) bool { return yield(f(v)) })
+	}
+}
+
+// Filter 只保留 keep 返回 true 的元素
+func Filter[T any](s Seq[T], keep func(T) bool) Seq[T] {
+	return func(yield func(T) bool) {
+		s(func(v T) bool { return !keep(v) || yield(v) })
+	}
+}
+
+// Take 最多取前 n 个元素，取够后立即停止上游
+func Take[T any](s Seq[T], n int) Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		s(func(v T) bool {
+			i++
+			return yield(v) && i < n
+		})
+	}
+}
+
+// Skip 跳过前 n 个元素
+func Skip[T any](s Seq[T], n int) Seq[T] {
+	return func(yield func(T) bool) {
+		i := 0
+		s(func(v T) bool {
+			if i < n {
+				i++
+				return true
+			}
+			return yield(v)
+		})
+	}
+}
+
+// Window 长度为 size 的滑动窗口，每次产生新的切片，元素不足 size 时不产生
+func Window[T any](s Seq[T], size int) Seq[[]T] {
+	if size <= 0 {
+		panic("seq: Window size must be positive")
+	}
+	return func(yield func([]T) bool) {
+		buf := make([]T, 0, size)
+		s(func(v T) bool {
+			if len(buf) == size {
+				copy(buf, buf[1:])
+				buf = buf[:size-1]
+			}
+			buf = append(buf, v)
+			if len(buf) < size {
+				return true
+			}
+			return yield(append([]T(nil), buf...))
+		})
+	}
+}
+
+// FlatMap 把每个元素展开为一个序列，依次产生其中的元素
+func FlatMap[T, R any](s Seq[T], f func(T) Seq[R]) Seq[R] {
+	return func(yield func(R) bool) {
+		s(func(v T) bool {
+			more := true
+			f(v)(func(r R) bool {
+				more = yield(r)
+				return more
+			})
+			return more
+		})
+	}
+}
+
+// Concat 依次产生各序列的元素
+func Concat[T any](ss ...Seq[T]) Seq[T] {
+	return FlatMap(FromSlice(ss), func(s Seq[T]) Seq[T] { return s })
+}
+
+// Collect 收集所有元素
+func Collect[T any](s Seq[T]) []T {
+	var out []T
+	s(func(v T) bool {
+		out = append(out, v)
+		return true
+	})
+	return out
+}
+
+// Reduce 从 init 开始依次累积
+func Reduce[T, A any](s Seq[T], init A, f func(A, T) A) A {
+	acc := init
+	s(func(v T) bool {
+		acc = f(acc, v)
+		return true
+	})
+	return acc
+}
+
+// Count 元素个数
+func Count[T any](s Seq[T]) int {
+	return Reduce(s, 0, func(n int, _ T) int { return n + 1 })
+}
+
+// First 第一个元素，取到后立即停止
+func First[T any](s Seq[T]) (v T, ok bool) {
+	s(func(x T) bool {
+		v, ok = x, true
+		return false
+	})
+	return v, ok
+}
+
+// Any 是否有元素满足 pred，找到后立即停止
+func Any[T any](s Seq[T], pred func(T) bool) bool {
+	_, ok := First(Filter(s, pred))
+	return ok
+}
+
+// ForEach 对每个元素调用 f，f 返回 false 时停止
+func ForEach[T any](s Seq[T], f func(T) bool) {
+	s(f)
+}
@@ -0,0 +1,182 @@
+package seq
+
+import (
+	"bufio"
+	"context"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// noLeak 检查 f 执行后 goroutine 数量回到执行前，退出的 goroutine 需要一点时间被回收
+func noLeak(t *testing.T, f func()) {
+	t.Helper()
+	before := runtime.NumGoroutine()
+	f()
+	n := 0
+	for i := 0; i < 100; i++ {
+		if n = runtime.NumGoroutine(); n <= before {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("goroutines: %d before, %d after", before, n)
+}
+
+func even(v int) bool { return v%2 == 0 }
+
+// naturals 无限的自然数序列，只能依靠消费方停止
+func naturals(yield func(int) bool) {
+	for i := 0; yield(i); i++ {
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	s := Take(Map(Filter(naturals, even), func(v int) int { return v * v }), 4)
+	if got := Collect(s); !reflect.DeepEqual(got, []int{0, 4, 16, 36}) {
+		t.Error(got)
+	}
+	// 惰性：再遍历一次结果相同
+	if got := Collect(s); !reflect.DeepEqual(got, []int{0, 4, 16, 36}) {
+		t.Error(got)
+	}
+	if got := Collect(Take(Skip(naturals, 3), 2)); !reflect.DeepEqual(got, []int{3, 4}) {
+		t.Error(got)
+	}
+	if got := Collect(Take[int](naturals, 0)); got != nil {
+		t.Error(got)
+	}
+	if got := Collect(Window(Values(1, 2, 3, 4), 3)); !reflect.DeepEqual(got, [][]int{{1, 2, 3}, {2, 3, 4}}) {
+		t.Error(got)
+	}
+	if got := Collect(Window(Values(1, 2), 3)); got != nil {
+		t.Error(got)
+	}
+
+	rep := func(v int) Seq[int] { return Take(Map(naturals, func(int) int { return v }), v) }
+	if got := Collect(FlatMap(Values(1, 2, 3), rep)); !reflect.DeepEqual(got, []int{1, 2, 2, 3, 3, 3}) {
+		t.Error(got)
+	}
+	// 内层提前停止时外层也停止
+	if got := Collect(Take(FlatMap[int, int](naturals, rep), 4)); !reflect.DeepEqual(got, []int{1, 2, 2, 3}) {
+		t.Error(got)
+	}
+	if got := Collect(Concat(Values(1), Values(2, 3))); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Error(got)
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	s := Values(1, 2, 3, 4)
+	if n := Reduce(s, 0, func(a, v int) int { return a + v }); n != 10 {
+		t.Error(n)
+	}
+	if n := Count(Filter(s, even)); n != 2 {
+		t.Error(n)
+	}
+	if v, ok := First(Skip(s, 1)); !ok || v != 2 {
+		t.Error(v, ok)
+	}
+	if _, ok := First(Skip(s, 4)); ok {
+		t.Error("First of empty seq")
+	}
+	if !Any(naturals, func(v int) bool { return v > 100 }) {
+		t.Error("Any")
+	}
+	var got []int
+	ForEach(s, func(v int) bool {
+		got = append(got, v)
+		return v < 2
+	})
+	if !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Error(got)
+	}
+}
+
+func TestSources(t *testing.T) {
+	m := map[string]int{"c": 3, "a": 1, "b": 2}
+	want := []Entry[string, int]{{"a", 1}, {"b", 2}, {"c", 3}}
+	if got := Collect(FromMap(m)); !reflect.DeepEqual(got, want) {
+		t.Error(got)
+	}
+
+	sc := bufio.NewScanner(strings.NewReader("x\ny\nz\n"))
+	if got := Collect(FromScanner(sc)); !reflect.DeepEqual(got, []string{"x", "y", "z"}) {
+		t.Error(got)
+	}
+
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+	if got := Collect(FromChan(ch)); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Error(got)
+	}
+}
+
+func TestPull(t *testing.T) {
+	p := Pull(Values(1, 2))
+	for _, want := range []int{1, 2} {
+		if v, ok := p.Next(); !ok || v != want {
+			t.Fatal(v, ok)
+		}
+	}
+	if _, ok := p.Next(); ok {
+		t.Error("Next after end")
+	}
+	p.Stop()
+	p.Stop()
+
+	if got := Collect(Pull(Take[int](naturals, 3)).Seq()); !reflect.DeepEqual(got, []int{0, 1, 2}) {
+		t.Error(got)
+	}
+}
+
+func counter(ctx context.Context, send func(int) bool) {
+	for i := 0; send(i); i++ {
+	}
+}
+
+// 从 channel 读取的序列被提前放弃时，生产者 goroutine 必须退出
+func TestNoLeak(t *testing.T) {
+	noLeak(t, func() {
+		if got := Collect(Take(Produce(counter), 3)); !reflect.DeepEqual(got, []int{0, 1, 2}) {
+			t.Error(got)
+		}
+	})
+	noLeak(t, func() {
+		First(Filter(Produce(counter), func(v int) bool { return v > 10 }))
+	})
+
+	// 生产者自己结束
+	noLeak(t, func() {
+		s := Produce(func(ctx context.Context, send func(string) bool) {
+			_ = send("a") && send("b")
+		})
+		if got := Collect(s); !reflect.DeepEqual(got, []string{"a", "b"}) {
+			t.Error(got)
+		}
+	})
+
+	// 生产者阻塞在 ctx 上而不是 send 上
+	noLeak(t, func() {
+		First(Produce(func(ctx context.Context, send func(int) bool) {
+			send(1)
+			<-ctx.Done()
+		}))
+	})
+
+	// 拉取到一半放弃
+	noLeak(t, func() {
+		p := Pull(Produce(counter))
+		p.Next()
+		p.Next()
+		p.Stop()
+	})
+	noLeak(t, func() {
+		Pull[int](naturals).Stop()
+	})
+}
@@ -0,0 +1,32 @@
+//go:build !purego
+
+package simd
+
+// 和 golang.org/x/sys/cpu 相同的检测方式，避免为几个标志位引入依赖
+
+func cpuid(eaxArg, ecxArg uint32) (eax, ebx, ecx, edx uint32)
+func xgetbv() (eax, edx uint32)
+
+// hasAVX2 需要 CPU 支持 AVX2，同时操作系统开启了 YMM 寄存器的保存（OSXSAVE + XCR0）
+var hasAVX2 = detectAVX2()
+
+func detectAVX2() bool {
+	maxID, _, _, _ := cpuid(0, 0)
+	if maxID < 7 {
+		return false
+	}
+
+	_, _, ecx1, _ := cpuid(1, 0)
+	osxsave := ecx1&(1<<27) != 0
+	avx := ecx1&(1<<28) != 0
+	if !osxsave || !avx {
+		return false
+	}
+	// XCR0 第 1、2 位：XMM 和 YMM 状态
+	if xcr0, _ := xgetbv(); xcr0&6 != 6 {
+		return false
+	}
+
+	_, ebx7, _, _ := cpuid(7, 0)
+	return ebx7&(1<<5) != 0
+}
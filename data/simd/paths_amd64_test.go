@@ -0,0 +1,31 @@
+//go:build !purego
+
+package simd
+
+import "testing"
+
+// forEachPath 依次在 SSE2 和 AVX2（如果支持）路径下执行 f
+func forEachPath(t testing.TB, f func(t testing.TB)) {
+	old := useAVX2
+	defer func() { useAVX2 = old }()
+
+	useAVX2 = false
+	f(t)
+	if hasAVX2 {
+		useAVX2 = true
+		f(t)
+	}
+}
+
+func pathNames() []string {
+	if hasAVX2 {
+		return []string{"sse2", "avx2"}
+	}
+	return []string{"sse2"}
+}
+
+func setPath(name string) func() {
+	old := useAVX2
+	useAVX2 = name == "avx2"
+	return func() { useAVX2 = old }
+}
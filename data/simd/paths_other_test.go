@@ -0,0 +1,11 @@
+//go:build !amd64 || purego
+
+package simd
+
+import "testing"
+
+func forEachPath(t testing.TB, f func(t testing.TB)) { f(t) }
+
+func pathNames() []string { return []string{"go"} }
+
+func setPath(string) func() { return func() {} }
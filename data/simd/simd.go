@@ -0,0 +1,89 @@
+// Package simd data.sum 之类切片循环的向量化版本
+//
+// amd64 上默认使用 SSE2（amd64 必然支持），运行时检测到 AVX2 时切换到 256 位的实现；
+// 其他平台或者 purego 构建使用本文件中的纯 Go 版本。
+// 汇编只处理整块数据，不足一块的尾部由 Go 代码处理，这样汇编里不需要处理边界
+package simd
+
+// SumInt64 求和，溢出时回绕，和 data.sum 一致
+func SumInt64(s []int64) int64 {
+	return sumInt64(s)
+}
+
+// SumFloat64 求和，向量化版本会改变加法顺序，结果可能和顺序累加有舍入误差
+func SumFloat64(s []float64) float64 {
+	return sumFloat64(s)
+}
+
+// MinMax 最小值和最大值，空切片时 ok 为 false
+func MinMax(s []int64) (min, max int64, ok bool) {
+	if len(s) == 0 {
+		return 0, 0, false
+	}
+	min, max = minMax(s)
+	return min, max, true
+}
+
+// IndexByte 第一次出现 c 的位置，没有则为 -1，语义同 bytes.IndexByte
+func IndexByte(s []byte, c byte) int {
+	return indexByte(s, c)
+}
+
+// Contains s 中是否包含 c
+func Contains(s []byte, c byte) bool {
+	return indexByte(s, c) >= 0
+}
+
+// Dot 点积，长度不同时 panic
+func Dot(a, b []float64) float64 {
+	if len(a) != len(b) {
+		panic("simd: Dot of slices with different lengths")
+	}
+	return dot(a, b)
+}
+
+// 下面是纯 Go 版本，同时作为测试的参照
+
+func sumInt64Go(s []int64) (n int64) {
+	for _, v := range s {
+		n += v
+	}
+	return n
+}
+
+func sumFloat64Go(s []float64) (n float64) {
+	for _, v := range s {
+		n += v
+	}
+	return n
+}
+
+func minMaxGo(s []int64) (min, max int64) {
+	min, max = s[0], s[0]
+	for _, v := range s[1:] {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return min, max
+}
+
+func indexByteGo(s []byte, c byte) int {
+	for i, b := range s {
+		if b == c {
+			return i
+		}
+	}
+	return -1
+}
+
+func dotGo(a, b []float64) (n float64) {
+	b = b[:len(a)] // 消除循环内的边界检查
+	for i, v := range a {
+		n += v * b[i]
+	}
+	return n
+}
@@ -0,0 +1,103 @@
+//go:build !purego
+
+package simd
+
+// useAVX2 测试中可以关闭，以覆盖 SSE2 路径
+var useAVX2 = hasAVX2
+
+// 汇编函数只处理整块，调用方保证长度是块大小的整数倍且大于 0
+// SSE2 每次循环处理 2 个 XMM 寄存器，AVX2 处理 2 个 YMM 寄存器
+
+func sumInt64SSE2(s []int64) int64
+func sumInt64AVX2(s []int64) int64
+func sumFloat64SSE2(s []float64) float64
+func sumFloat64AVX2(s []float64) float64
+func dotSSE2(a, b []float64) float64
+func dotAVX2(a, b []float64) float64
+func indexByteSSE2(s []byte, c byte) int
+func indexByteAVX2(s []byte, c byte) int
+
+// SSE2 没有 64 位整数比较（PCMPGTQ 是 SSE4.2），只能用 CMOV 的标量循环
+func minMaxScalar(s []int64) (min, max int64)
+
+// out[0:4] 为 4 路最小值，out[4:8] 为 4 路最大值
+func minMaxAVX2(s []int64, out *[8]int64)
+
+// block 按当前路径返回块大小（元素个数）
+func block(sse2, avx2 int) int {
+	if useAVX2 {
+		return avx2
+	}
+	return sse2
+}
+
+func sumInt64(s []int64) (r int64) {
+	n := len(s) &^ (block(4, 8) - 1)
+	if n > 0 {
+		if useAVX2 {
+			r = sumInt64AVX2(s[:n])
+		} else {
+			r = sumInt64SSE2(s[:n])
+		}
+	}
+	return r + sumInt64Go(s[n:])
+}
+
+func sumFloat64(s []float64) (r float64) {
+	n := len(s) &^ (block(4, 8) - 1)
+	if n > 0 {
+		if useAVX2 {
+			r = sumFloat64AVX2(s[:n])
+		} else {
+			r = sumFloat64SSE2(s[:n])
+		}
+	}
+	return r + sumFloat64Go(s[n:])
+}
+
+func dot(a, b []float64) (r float64) {
+	n := len(a) &^ (block(4, 8) - 1)
+	if n > 0 {
+		if useAVX2 {
+			r = dotAVX2(a[:n], b[:n])
+		} else {
+			r = dotSSE2(a[:n], b[:n])
+		}
+	}
+	return r + dotGo(a[n:], b[n:])
+}
+
+func indexByte(s []byte, c byte) int {
+	n := len(s) &^ (block(16, 32) - 1)
+	if n > 0 {
+		var i int
+		if useAVX2 {
+			i = indexByteAVX2(s[:n], c)
+		} else {
+			i = indexByteSSE2(s[:n], c)
+		}
+		if i >= 0 {
+			return i
+		}
+	}
+	if i := indexByteGo(s[n:], c); i >= 0 {
+		return n + i
+	}
+	return -1
+}
+
+func minMax(s []int64) (min, max int64) {
+	if !useAVX2 {
+		return minMaxScalar(s)
+	}
+
+	n := len(s) &^ 3
+	if n == 0 {
+		return minMaxGo(s)
+	}
+	var out [8]int64
+	minMaxAVX2(s[:n], &out)
+	min, _ = minMaxGo(append(out[:4:4], s[n:]...))
+	_, max = minMaxGo(append(out[4:8:8], s[n:]...))
+	return min, max
+}
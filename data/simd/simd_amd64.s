@@ -0,0 +1,270 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// func sumInt64SSE2(s []int64) int64
+TEXT ·sumInt64SSE2(SB), NOSPLIT, $0-32
+	MOVQ s_base+0(FP), SI
+	MOVQ s_len+8(FP), CX
+	PXOR X0, X0
+	PXOR X1, X1
+
+loop:
+	MOVOU 0(SI), X2
+	MOVOU 16(SI), X3
+	PADDQ X2, X0
+	PADDQ X3, X1
+	ADDQ  $32, SI
+	SUBQ  $4, CX
+	JNZ   loop
+
+	PADDQ  X1, X0
+	MOVQ   X0, AX
+	PSRLDQ $8, X0 // 高 64 位移到低位
+	MOVQ   X0, BX
+	ADDQ   BX, AX
+	MOVQ   AX, ret+24(FP)
+	RET
+
+// func sumInt64AVX2(s []int64) int64
+TEXT ·sumInt64AVX2(SB), NOSPLIT, $0-32
+	MOVQ  s_base+0(FP), SI
+	MOVQ  s_len+8(FP), CX
+	VPXOR Y0, Y0, Y0
+	VPXOR Y1, Y1, Y1
+
+loop:
+	VPADDQ 0(SI), Y0, Y0
+	VPADDQ 32(SI), Y1, Y1
+	ADDQ   $64, SI
+	SUBQ   $8, CX
+	JNZ    loop
+
+	VPADDQ       Y1, Y0, Y0
+	VEXTRACTI128 $1, Y0, X1 // 高 128 位
+	VPADDQ       X1, X0, X0
+	VZEROUPPER              // 避免之后的 SSE 指令产生状态切换开销
+	MOVQ         X0, AX
+	PSRLDQ       $8, X0
+	MOVQ         X0, BX
+	ADDQ         BX, AX
+	MOVQ         AX, ret+24(FP)
+	RET
+
+// func sumFloat64SSE2(s []float64) float64
+TEXT ·sumFloat64SSE2(SB), NOSPLIT, $0-32
+	MOVQ  s_base+0(FP), SI
+	MOVQ  s_len+8(FP), CX
+	XORPD X0, X0
+	XORPD X1, X1
+
+loop:
+	MOVUPD 0(SI), X2
+	MOVUPD 16(SI), X3
+	ADDPD  X2, X0
+	ADDPD  X3, X1
+	ADDQ   $32, SI
+	SUBQ   $4, CX
+	JNZ    loop
+
+	ADDPD    X1, X0
+	MOVAPD   X0, X1
+	UNPCKHPD X1, X1
+	ADDSD    X1, X0
+	MOVSD    X0, ret+24(FP)
+	RET
+
+// func sumFloat64AVX2(s []float64) float64
+TEXT ·sumFloat64AVX2(SB), NOSPLIT, $0-32
+	MOVQ   s_base+0(FP), SI
+	MOVQ   s_len+8(FP), CX
+	VXORPD Y0, Y0, Y0
+	VXORPD Y1, Y1, Y1
+
+loop:
+	VADDPD 0(SI), Y0, Y0
+	VADDPD 32(SI), Y1, Y1
+	ADDQ   $64, SI
+	SUBQ   $8, CX
+	JNZ    loop
+
+	VADDPD       Y1, Y0, Y0
+	VEXTRACTF128 $1, Y0, X1
+	VADDPD       X1, X0, X0
+	VZEROUPPER
+	MOVAPD       X0, X1
+	UNPCKHPD     X1, X1
+	ADDSD        X1, X0
+	MOVSD        X0, ret+24(FP)
+	RET
+
+// func dotSSE2(a, b []float64) float64
+TEXT ·dotSSE2(SB), NOSPLIT, $0-56
+	MOVQ  a_base+0(FP), SI
+	MOVQ  a_len+8(FP), CX
+	MOVQ  b_base+24(FP), DI
+	XORPD X0, X0
+	XORPD X1, X1
+
+loop:
+	MOVUPD 0(SI), X2
+	MOVUPD 0(DI), X3
+	MULPD  X3, X2
+	ADDPD  X2, X0
+	MOVUPD 16(SI), X4
+	MOVUPD 16(DI), X5
+	MULPD  X5, X4
+	ADDPD  X4, X1
+	ADDQ   $32, SI
+	ADDQ   $32, DI
+	SUBQ   $4, CX
+	JNZ    loop
+
+	ADDPD    X1, X0
+	MOVAPD   X0, X1
+	UNPCKHPD X1, X1
+	ADDSD    X1, X0
+	MOVSD    X0, ret+48(FP)
+	RET
+
+// func dotAVX2(a, b []float64) float64
+TEXT ·dotAVX2(SB), NOSPLIT, $0-56
+	MOVQ   a_base+0(FP), SI
+	MOVQ   a_len+8(FP), CX
+	MOVQ   b_base+24(FP), DI
+	VXORPD Y0, Y0, Y0
+	VXORPD Y1, Y1, Y1
+
+loop:
+	VMOVUPD 0(SI), Y2
+	VMULPD  0(DI), Y2, Y2
+	VADDPD  Y2, Y0, Y0
+	VMOVUPD 32(SI), Y3
+	VMULPD  32(DI), Y3, Y3
+	VADDPD  Y3, Y1, Y1
+	ADDQ    $64, SI
+	ADDQ    $64, DI
+	SUBQ    $8, CX
+	JNZ     loop
+
+	VADDPD       Y1, Y0, Y0
+	VEXTRACTF128 $1, Y0, X1
+	VADDPD       X1, X0, X0
+	VZEROUPPER
+	MOVAPD       X0, X1
+	UNPCKHPD     X1, X1
+	ADDSD        X1, X0
+	MOVSD        X0, ret+48(FP)
+	RET
+
+// func indexByteSSE2(s []byte, c byte) int
+TEXT ·indexByteSSE2(SB), NOSPLIT, $0-40
+	MOVQ   s_base+0(FP), SI
+	MOVQ   s_len+8(FP), CX
+	MOVBLZX c+24(FP), AX
+
+	// 把 c 广播到 X0 的 16 个字节
+	MOVQ      AX, X0
+	PUNPCKLBW X0, X0
+	PUNPCKLWL X0, X0
+	PSHUFD    $0, X0, X0
+	MOVQ      SI, DI
+
+loop:
+	MOVOU    0(DI), X1
+	PCMPEQB  X0, X1
+	PMOVMSKB X1, DX // 每个相等的字节对应掩码中的一位
+	TESTL    DX, DX
+	JNZ      found
+	ADDQ     $16, DI
+	SUBQ     $16, CX
+	JNZ      loop
+
+	MOVQ $-1, ret+32(FP)
+	RET
+
+found:
+	BSFL DX, DX // 最低位的 1 即第一个匹配
+	SUBQ SI, DI
+	ADDQ DX, DI
+	MOVQ DI, ret+32(FP)
+	RET
+
+// func indexByteAVX2(s []byte, c byte) int
+TEXT ·indexByteAVX2(SB), NOSPLIT, $0-40
+	MOVQ         s_base+0(FP), SI
+	MOVQ         s_len+8(FP), CX
+	MOVBLZX      c+24(FP), AX
+	MOVQ         AX, X0
+	VPBROADCASTB X0, Y0
+	MOVQ         SI, DI
+
+loop:
+	VMOVDQU   0(DI), Y1
+	VPCMPEQB  Y0, Y1, Y1
+	VPMOVMSKB Y1, DX
+	TESTL     DX, DX
+	JNZ       found
+	ADDQ      $32, DI
+	SUBQ      $32, CX
+	JNZ       loop
+
+	VZEROUPPER
+	MOVQ $-1, ret+32(FP)
+	RET
+
+found:
+	VZEROUPPER
+	BSFL DX, DX
+	SUBQ SI, DI
+	ADDQ DX, DI
+	MOVQ DI, ret+32(FP)
+	RET
+
+// func minMaxScalar(s []int64) (min, max int64)
+TEXT ·minMaxScalar(SB), NOSPLIT, $0-40
+	MOVQ s_base+0(FP), SI
+	MOVQ s_len+8(FP), CX
+	MOVQ 0(SI), AX // min
+	MOVQ AX, BX    // max
+
+loop:
+	MOVQ    0(SI), DX
+	CMPQ    DX, AX
+	CMOVQLT DX, AX
+	CMPQ    DX, BX
+	CMOVQGT DX, BX
+	ADDQ    $8, SI
+	DECQ    CX
+	JNZ     loop
+
+	MOVQ AX, min+24(FP)
+	MOVQ BX, max+32(FP)
+	RET
+
+// func minMaxAVX2(s []int64, out *[8]int64)
+TEXT ·minMaxAVX2(SB), NOSPLIT, $0-32
+	MOVQ    s_base+0(FP), SI
+	MOVQ    s_len+8(FP), CX
+	MOVQ    out+24(FP), DI
+	VMOVDQU 0(SI), Y0 // 4 路最小值
+	VMOVDQU Y0, Y1    // 4 路最大值
+	ADDQ    $32, SI
+	SUBQ    $4, CX
+	JZ      done
+
+loop:
+	VMOVDQU   0(SI), Y2
+	VPCMPGTQ  Y2, Y0, Y3     // Y3 = Y0 > Y2
+	VPBLENDVB Y3, Y2, Y0, Y0 // Y3 对应位置取 Y2
+	VPCMPGTQ  Y1, Y2, Y4     // Y4 = Y2 > Y1
+	VPBLENDVB Y4, Y2, Y1, Y1
+	ADDQ      $32, SI
+	SUBQ      $4, CX
+	JNZ       loop
+
+done:
+	VMOVDQU Y0, 0(DI)
+	VMOVDQU Y1, 32(DI)
+	VZEROUPPER
+	RET
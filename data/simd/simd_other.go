@@ -0,0 +1,9 @@
+//go:build !amd64 || purego
+
+package simd
+
+func sumInt64(s []int64) int64        { return sumInt64Go(s) }
+func sumFloat64(s []float64) float64  { return sumFloat64Go(s) }
+func minMax(s []int64) (int64, int64) { return minMaxGo(s) }
+func indexByte(s []byte, c byte) int  { return indexByteGo(s, c) }
+func dot(a, b []float64) float64      { return dotGo(a, b) }
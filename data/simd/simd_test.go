@@ -0,0 +1,293 @@
+package simd
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func int64s(data []byte) []int64 {
+	s := make([]int64, len(data)/8)
+	for i := range s {
+		s[i] = int64(binary.LittleEndian.Uint64(data[i*8:]))
+	}
+	return s
+}
+
+// float64s 限制在有限的范围内，避免不同累加顺序导致一个溢出成 Inf 而另一个没有
+func float64s(data []byte) []float64 {
+	s := make([]float64, len(data)/4)
+	for i := range s {
+		s[i] = float64(int32(binary.LittleEndian.Uint32(data[i*4:]))) / 1000
+	}
+	return s
+}
+
+// closeEnough 向量化累加改变了加法顺序，误差上界为 n * eps * sum|x|
+func closeEnough(got, want float64, abs float64, n int) bool {
+	return math.Abs(got-want) <= float64(n+1)*0x1p-52*abs
+}
+
+func seed(f *testing.F) {
+	for _, n := range []int{0, 1, 7, 8, 9, 31, 32, 33, 100, 1000} {
+		b := make([]byte, n*8)
+		for i := range b {
+			b[i] = byte(i*7 + n)
+		}
+		f.Add(b, uint8(n))
+	}
+}
+
+func FuzzSumInt64(f *testing.F) {
+	seed(f)
+	f.Fuzz(func(t *testing.T, data []byte, off uint8) {
+		s := int64s(data)
+		s = s[int(off)%(len(s)+1):] // 改变起始地址的对齐
+		forEachPath(t, func(t testing.TB) {
+			if got, want := SumInt64(s), sumInt64Go(s); got != want {
+				t.Fatalf("SumInt64(len %d) = %d, want %d", len(s), got, want)
+			}
+		})
+	})
+}
+
+func FuzzSumFloat64(f *testing.F) {
+	seed(f)
+	f.Fuzz(func(t *testing.T, data []byte, off uint8) {
+		s := float64s(data)
+		s = s[int(off)%(len(s)+1):]
+		var abs float64
+		for _, v := range s {
+			abs += math.Abs(v)
+		}
+		forEachPath(t, func(t testing.TB) {
+			if got, want := SumFloat64(s), sumFloat64Go(s); !closeEnough(got, want, abs, len(s)) {
+				t.Fatalf("SumFloat64(len %d) = %v, want %v", len(s), got, want)
+			}
+		})
+	})
+}
+
+func FuzzMinMax(f *testing.F) {
+	seed(f)
+	f.Fuzz(func(t *testing.T, data []byte, off uint8) {
+		s := int64s(data)
+		s = s[int(off)%(len(s)+1):]
+		forEachPath(t, func(t testing.TB) {
+			min, max, ok := MinMax(s)
+			if ok != (len(s) > 0) {
+				t.Fatalf("MinMax(len %d) ok = %v", len(s), ok)
+			}
+			if !ok {
+				return
+			}
+			if wmin, wmax := minMaxGo(s); min != wmin || max != wmax {
+				t.Fatalf("MinMax(len %d) = %d %d, want %d %d", len(s), min, max, wmin, wmax)
+			}
+		})
+	})
+}
+
+func FuzzIndexByte(f *testing.F) {
+	seed(f)
+	f.Fuzz(func(t *testing.T, data []byte, c uint8) {
+		forEachPath(t, func(t testing.TB) {
+			for off := 0; off < 4 && off <= len(data); off++ {
+				s := data[off:]
+				if got, want := IndexByte(s, c), indexByteGo(s, c); got != want {
+					t.Fatalf("IndexByte(len %d, %d) = %d, want %d", len(s), c, got, want)
+				}
+				if Contains(s, c) != (indexByteGo(s, c) >= 0) {
+					t.Fatalf("Contains(len %d, %d) mismatch", len(s), c)
+				}
+			}
+		})
+	})
+}
+
+func FuzzDot(f *testing.F) {
+	seed(f)
+	f.Fuzz(func(t *testing.T, data []byte, off uint8) {
+		s := float64s(data)
+		a, b := s[:len(s)/2], s[len(s)/2:len(s)/2*2]
+		k := int(off) % (len(a) + 1)
+		a, b = a[k:], b[k:]
+		var abs float64
+		for i := range a {
+			abs += math.Abs(a[i] * b[i])
+		}
+		forEachPath(t, func(t testing.TB) {
+			if got, want := Dot(a, b), dotGo(a, b); !closeEnough(got, want, abs, len(a)) {
+				t.Fatalf("Dot(len %d) = %v, want %v", len(a), got, want)
+			}
+		})
+	})
+}
+
+func TestIndexByteLast(t *testing.T) {
+	// 匹配位于每个块的各个位置以及尾部
+	for n := 0; n < 100; n++ {
+		s := make([]byte, n)
+		forEachPath(t, func(t testing.TB) {
+			for i := 0; i < n; i++ {
+				s[i] = 'x'
+				if got := IndexByte(s, 'x'); got != i {
+					t.Fatalf("len %d: IndexByte = %d, want %d", n, got, i)
+				}
+				s[i] = 0
+			}
+			if got := IndexByte(s, 'x'); got != -1 {
+				t.Fatalf("len %d: IndexByte = %d, want -1", n, got)
+			}
+		})
+	}
+}
+
+func TestDotPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Dot with different lengths did not panic")
+		}
+	}()
+	Dot([]float64{1}, nil)
+}
+
+var (
+	sinkI int64
+	sinkF float64
+	sinkN int
+)
+
+var benchSizes = []int{0, 1, 16, 1 << 10, 1 << 16, 1 << 20}
+
+func BenchmarkSumInt64(b *testing.B) {
+	for _, n := range benchSizes {
+		s := make([]int64, n)
+		for i := range s {
+			s[i] = int64(i)
+		}
+		b.Run(fmt.Sprintf("go/%d", n), func(b *testing.B) {
+			b.SetBytes(int64(n * 8))
+			for i := 0; i < b.N; i++ {
+				sinkI = sumInt64Go(s)
+			}
+		})
+		for _, p := range pathNames() {
+			b.Run(fmt.Sprintf("%s/%d", p, n), func(b *testing.B) {
+				defer setPath(p)()
+				b.SetBytes(int64(n * 8))
+				for i := 0; i < b.N; i++ {
+					sinkI = SumInt64(s)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkSumFloat64(b *testing.B) {
+	for _, n := range benchSizes {
+		s := make([]float64, n)
+		for i := range s {
+			s[i] = float64(i)
+		}
+		b.Run(fmt.Sprintf("go/%d", n), func(b *testing.B) {
+			b.SetBytes(int64(n * 8))
+			for i := 0; i < b.N; i++ {
+				sinkF = sumFloat64Go(s)
+			}
+		})
+		for _, p := range pathNames() {
+			b.Run(fmt.Sprintf("%s/%d", p, n), func(b *testing.B) {
+				defer setPath(p)()
+				b.SetBytes(int64(n * 8))
+				for i := 0; i < b.N; i++ {
+					sinkF = SumFloat64(s)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkMinMax(b *testing.B) {
+	for _, n := range benchSizes[1:] {
+		s := make([]int64, n)
+		for i := range s {
+			s[i] = int64(i * 7919 % 1000003)
+		}
+		b.Run(fmt.Sprintf("go/%d", n), func(b *testing.B) {
+			b.SetBytes(int64(n * 8))
+			for i := 0; i < b.N; i++ {
+				sinkI, _ = minMaxGo(s)
+			}
+		})
+		for _, p := range pathNames() {
+			b.Run(fmt.Sprintf("%s/%d", p, n), func(b *testing.B) {
+				defer setPath(p)()
+				b.SetBytes(int64(n * 8))
+				for i := 0; i < b.N; i++ {
+					sinkI, _, _ = MinMax(s)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkIndexByte(b *testing.B) {
+	for _, n := range benchSizes {
+		s := make([]byte, n) // 不包含目标字节，扫描整个切片
+		b.Run(fmt.Sprintf("go/%d", n), func(b *testing.B) {
+			b.SetBytes(int64(n))
+			for i := 0; i < b.N; i++ {
+				sinkN = indexByteGo(s, 1)
+			}
+		})
+		for _, p := range pathNames() {
+			b.Run(fmt.Sprintf("%s/%d", p, n), func(b *testing.B) {
+				defer setPath(p)()
+				b.SetBytes(int64(n))
+				for i := 0; i < b.N; i++ {
+					sinkN = IndexByte(s, 1)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkDot(b *testing.B) {
+	for _, n := range benchSizes {
+		x, y := make([]float64, n), make([]float64, n)
+		for i := range x {
+			x[i], y[i] = float64(i), float64(n-i)
+		}
+		b.Run(fmt.Sprintf("go/%d", n), func(b *testing.B) {
+			b.SetBytes(int64(n * 16))
+			for i := 0; i < b.N; i++ {
+				sinkF = dotGo(x, y)
+			}
+		})
+		for _, p := range pathNames() {
+			b.Run(fmt.Sprintf("%s/%d", p, n), func(b *testing.B) {
+				defer setPath(p)()
+				b.SetBytes(int64(n * 16))
+				for i := 0; i < b.N; i++ {
+					sinkF = Dot(x, y)
+				}
+			})
+		}
+	}
+}
+
+// go test -run XXX -bench 'SumInt64|IndexByte' ./data/simd
+/*
+cpu: Intel(R) Xeon(R) Processor
+BenchmarkSumInt64/go/1024              798.6 ns/op     10258.03 MB/s
+BenchmarkSumInt64/sse2/1024            238.6 ns/op     34330.30 MB/s
+BenchmarkSumInt64/avx2/1024            172.0 ns/op     47617.94 MB/s
+BenchmarkSumInt64/go/1048576          717887 ns/op     11685.13 MB/s
+BenchmarkSumInt64/sse2/1048576        407634 ns/op     20578.80 MB/s
+BenchmarkSumInt64/avx2/1048576        385971 ns/op     21733.76 MB/s  大切片受内存带宽限制
+BenchmarkIndexByte/go/1024              1252 ns/op       817.89 MB/s
+BenchmarkIndexByte/sse2/1024           74.58 ns/op     13730.87 MB/s
+BenchmarkIndexByte/avx2/1024           35.08 ns/op     29188.34 MB/s
+*/
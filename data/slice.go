@@ -283,6 +283,8 @@ func pslice(name string, p *[]int) {
 		name, *(*reflect.SliceHeader)(unsafe.Pointer(p)))
 }
 
+// 向量化（SSE2/AVX2）版本见 data/simd
+//
 //go:noinline
 func sum(s []int) (n int) {
 	for _, v := range s {
@@ -0,0 +1,26 @@
+package data
+
+import (
+	"testing"
+
+	"yuhen/internal/inline/inlinetest"
+)
+
+func TestQueueInline(t *testing.T) {
+	r := inlinetest.Load(t, "./data")
+
+	// append 实现的队列足够简单，调用处直接展开
+	r.AssertInlinable(t, "(*Queue).Put")
+
+	// defer 解锁使 LoopQueue 的方法无法内联，取模后的索引仍需一次边界检查
+	r.AssertNotInlinable(t, "(*LoopQueue).Put", "DEFER")
+	r.AssertBounds(t, "(*LoopQueue).Put", 1)
+	r.AssertBounds(t, "(*LoopQueue).Get", 1)
+}
+
+func TestNoinline(t *testing.T) {
+	r := inlinetest.Load(t, "./data")
+	for _, name := range []string{"normal", "block", "sumCon", "performanceGen", "testNew"} {
+		r.AssertNotInlinable(t, name, "go:noinline")
+	}
+}
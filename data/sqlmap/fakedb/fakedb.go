@@ -0,0 +1,310 @@
+// Package fakedb 内存中的 database/sql 驱动，只支持 sqlmap 生成的语句，用于离线测试
+//
+//	CREATE TABLE t (a integer, b text)
+//	INSERT INTO t (a, b) VALUES (?, ?)
+//	SELECT a, b FROM t [WHERE a = ?]
+//	SELECT * FROM t
+//
+// 驱动名为 fakedb，DSN 是数据库的名字，同名的连接共享数据，不同的测试使用不同的名字即可隔离
+package fakedb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	sql.Register("fakedb", Driver{})
+}
+
+// Driver 实现 driver.Driver
+type Driver struct{}
+
+type table struct {
+	cols  []string
+	types []string // 列类型的第一个单词，小写
+	rows  [][]driver.Value
+}
+
+type db struct {
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+var (
+	mu  sync.Mutex
+	dbs = make(map[string]*db)
+)
+
+func (Driver) Open(name string) (driver.Conn, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	d := dbs[name]
+	if d == nil {
+		d = &db{tables: make(map[string]*table)}
+		dbs[name] = d
+	}
+	return &conn{db: d}, nil
+}
+
+// Drop 删除数据库 name 中的所有表
+func Drop(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(dbs, name)
+}
+
+type conn struct {
+	db *db
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	st, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	st.db = c.db
+	return st, nil
+}
+
+func (c *conn) Close() error { return nil }
+
+// Begin 不支持回滚，只为满足接口
+func (c *conn) Begin() (driver.Tx, error) { return tx{}, nil }
+
+type tx struct{}
+
+func (tx) Commit() error   { return nil }
+func (tx) Rollback() error { return errors.New("fakedb: rollback is not supported") }
+
+var (
+	createRE = regexp.MustCompile(`(?is)^\s*CREATE TABLE\s+(\w+)\s*\((.*)\)\s*$`)
+	insertRE = regexp.MustCompile(`(?is)^\s*INSERT INTO\s+(\w+)\s*\(([^)]*)\)\s*VALUES\s*\(([^)]*)\)\s*$`)
+	selectRE = regexp.MustCompile(`(?is)^\s*SELECT\s+(.+?)\s+FROM\s+(\w+)(?:\s+WHERE\s+(\w+)\s*=\s*\?)?\s*$`)
+)
+
+type kind int
+
+const (
+	create kind = iota
+	insert
+	query
+)
+
+// stmt 解析后的语句
+type stmt struct {
+	db    *db
+	kind  kind
+	table string
+	cols  []string // CREATE 的列名，INSERT、SELECT 的列
+	types []string // CREATE 的列类型
+	where string   // SELECT 的条件列
+	n     int      // 参数个数
+}
+
+func split(s string) []string {
+	var out []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func parse(q string) (*stmt, error) {
+	if m := createRE.FindStringSubmatch(q); m != nil {
+		st := &stmt{kind: create, table: m[1]}
+		for _, def := range split(m[2]) {
+			f := strings.Fields(def)
+			if len(f) < 2 {
+				return nil, fmt.Errorf("fakedb: bad column definition %q", def)
+			}
+			st.cols = append(st.cols, f[0])
+			st.types = append(st.types, strings.ToLower(f[1]))
+		}
+		return st, nil
+	}
+	if m := insertRE.FindStringSubmatch(q); m != nil {
+		st := &stmt{kind: insert, table: m[1], cols: split(m[2])}
+		ps := split(m[3])
+		for _, p := range ps {
+			if p != "?" {
+				return nil, fmt.Errorf("fakedb: only ? placeholders are supported: %q", q)
+			}
+		}
+		if len(ps) != len(st.cols) {
+			return nil, fmt.Errorf("fakedb: %d columns but %d values", len(st.cols), len(ps))
+		}
+		st.n = len(ps)
+		return st, nil
+	}
+	if m := selectRE.FindStringSubmatch(q); m != nil {
+		st := &stmt{kind: query, table: m[2], cols: split(m[1]), where: m[3]}
+		if st.where != "" {
+			st.n = 1
+		}
+		return st, nil
+	}
+	return nil, fmt.Errorf("fakedb: unsupported statement %q", q)
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return s.n }
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	switch s.kind {
+	case create:
+		if s.db.tables[s.table] != nil {
+			return nil, fmt.Errorf("fakedb: table %s already exists", s.table)
+		}
+		s.db.tables[s.table] = &table{cols: s.cols, types: s.types}
+		return driver.RowsAffected(0), nil
+	case insert:
+		t, err := s.db.table(s.table)
+		if err != nil {
+			return nil, err
+		}
+		row := make([]driver.Value, len(t.cols))
+		for i, c := range s.cols {
+			j := t.index(c)
+			if j < 0 {
+				return nil, fmt.Errorf("fakedb: %s: no column %s", s.table, c)
+			}
+			if err := check(t.types[j], args[i]); err != nil {
+				return nil, fmt.Errorf("fakedb: %s.%s: %w", s.table, c, err)
+			}
+			if b, ok := args[i].([]byte); ok {
+				args[i] = append([]byte(nil), b...)
+			}
+			row[j] = args[i]
+		}
+		t.rows = append(t.rows, row)
+		return driver.RowsAffected(1), nil
+	}
+	return nil, errors.New("fakedb: Exec on SELECT")
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.kind != query {
+		return nil, errors.New("fakedb: Query on non-SELECT")
+	}
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	t, err := s.db.table(s.table)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := s.cols
+	if len(cols) == 1 && cols[0] == "*" {
+		cols = t.cols
+	}
+	idx := make([]int, len(cols))
+	for i, c := range cols {
+		if idx[i] = t.index(c); idx[i] < 0 {
+			return nil, fmt.Errorf("fakedb: %s: no column %s", s.table, c)
+		}
+	}
+	w := -1
+	if s.where != "" {
+		if w = t.index(s.where); w < 0 {
+			return nil, fmt.Errorf("fakedb: %s: no column %s", s.table, s.where)
+		}
+	}
+
+	r := &rows{cols: cols}
+	for _, row := range t.rows {
+		if w >= 0 && !equal(row[w], args[0]) {
+			continue
+		}
+		out := make([]driver.Value, len(idx))
+		for i, j := range idx {
+			out[i] = row[j]
+		}
+		r.rows = append(r.rows, out)
+	}
+	return r, nil
+}
+
+func (d *db) table(name string) (*table, error) {
+	t := d.tables[name]
+	if t == nil {
+		return nil, fmt.Errorf("fakedb: no table %s", name)
+	}
+	return t, nil
+}
+
+func (t *table) index(col string) int {
+	for i, c := range t.cols {
+		if strings.EqualFold(c, col) {
+			return i
+		}
+	}
+	return -1
+}
+
+// check 按列类型检查值的类型，未知的类型不检查
+func check(typ string, v driver.Value) error {
+	if v == nil {
+		return nil
+	}
+	ok := true
+	switch typ {
+	case "integer", "int", "bigint":
+		_, ok = v.(int64)
+	case "real", "float", "double":
+		_, ok = v.(float64)
+	case "text", "varchar":
+		_, ok = v.(string)
+	case "boolean", "bool":
+		_, ok = v.(bool)
+	case "blob":
+		_, ok = v.([]byte)
+	case "timestamp", "datetime":
+		_, ok = v.(time.Time)
+	}
+	if !ok {
+		return fmt.Errorf("%T is not %s", v, typ)
+	}
+	return nil
+}
+
+func equal(a, b driver.Value) bool {
+	switch x := a.(type) {
+	case []byte:
+		y, ok := b.([]byte)
+		return ok && string(x) == string(y)
+	case time.Time:
+		y, ok := b.(time.Time)
+		return ok && x.Equal(y)
+	}
+	return a == b
+}
+
+type rows struct {
+	cols []string
+	rows [][]driver.Value
+	i    int
+}
+
+func (r *rows) Columns() []string { return r.cols }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.i >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.i])
+	r.i++
+	return nil
+}
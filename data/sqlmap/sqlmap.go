@@ -0,0 +1,344 @@
+// Package sqlmap 按 data.tag() 中 User 的 field、type 标签把结构体映射为数据表
+//
+//	type User struct {
+//		ID   int    `field:"uid" type:"integer primary key"`
+//		Name string `field:"name" type:"text"`
+//		Point       // 嵌入的结构体展开为同一张表的列，和 mem() 中 Value 的 Point 一样
+//	}
+//
+// 生成 CREATE TABLE、INSERT、SELECT 语句，把 *sql.Rows 按列名扫描到结构体中。
+// 只映射导出的字段；没有 field 标签时列名为字段名的蛇形小写，没有 type 标签时按 Go 类型推断
+package sqlmap
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Column 一列
+type Column struct {
+	Name  string // 列名
+	Type  string // 列定义中的类型部分
+	Field string // 字段名，嵌入的字段为 Point.X
+	Index []int  // 字段的索引路径，用于 reflect.Value.FieldByIndex
+}
+
+// Table 一个结构体类型对应的表
+type Table struct {
+	Name    string
+	Type    reflect.Type
+	Columns []Column
+	byName  map[string]int
+}
+
+// Tabler 自定义表名，默认为类型名的蛇形小写
+type Tabler interface {
+	TableName() string
+}
+
+var tables sync.Map // reflect.Type -> *Table
+
+// Of 取得 v（结构体或结构体指针）对应的表，结果按类型缓存
+func Of(v any) (*Table, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("sqlmap: %T is not a struct", v)
+	}
+	if tb, ok := tables.Load(t); ok {
+		return tb.(*Table), nil
+	}
+
+	tb := &Table{Name: snake(t.Name()), Type: t, byName: make(map[string]int)}
+	if n, ok := reflect.New(t).Interface().(Tabler); ok {
+		tb.Name = n.TableName()
+	}
+	if err := tb.add(t, nil, ""); err != nil {
+		return nil, err
+	}
+	if len(tb.Columns) == 0 {
+		return nil, fmt.Errorf("sqlmap: %s has no columns", t)
+	}
+	tb2, _ := tables.LoadOrStore(t, tb)
+	return tb2.(*Table), nil
+}
+
+// MustOf 同 Of，出错时 panic，用于包级变量
+func MustOf(v any) *Table {
+	tb, err := Of(v)
+	if err != nil {
+		panic(err)
+	}
+	return tb
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// add 添加结构体 t 的字段，嵌入的结构体递归展开
+func (tb *Table) add(t reflect.Type, index []int, prefix string) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Tag.Get("field")
+		if name == "-" {
+			continue
+		}
+		idx := append(append([]int(nil), index...), i)
+
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct && !isValue(f.Type) {
+			if err := tb.add(f.Type, idx, prefix+f.Name+"."); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Pointer && name == "" {
+			return fmt.Errorf("sqlmap: %s.%s: embedded pointer is not supported", tb.Type, f.Name)
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = snake(f.Name)
+		}
+		typ := f.Tag.Get("type")
+		if typ == "" {
+			if typ = sqlType(f.Type); typ == "" {
+				return fmt.Errorf("sqlmap: %s.%s: no type tag for %s", tb.Type, f.Name, f.Type)
+			}
+		}
+		if _, dup := tb.byName[name]; dup {
+			return fmt.Errorf("sqlmap: %s: duplicate column %s", tb.Type, name)
+		}
+		tb.byName[name] = len(tb.Columns)
+		tb.Columns = append(tb.Columns, Column{Name: name, Type: typ, Field: prefix + f.Name, Index: idx})
+	}
+	return nil
+}
+
+// isValue 作为单个值存储的结构体，不展开
+func isValue(t reflect.Type) bool {
+	return t == timeType || reflect.PointerTo(t).Implements(scannerType)
+}
+
+// sqlType 按 Go 类型（命名类型按底层类型）推断列类型，sql.Null* 等需要 type 标签
+func sqlType(t reflect.Type) string {
+	if t == timeType {
+		return "timestamp"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "real"
+	case reflect.String:
+		return "text"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "blob"
+		}
+	}
+	return ""
+}
+
+// snake UserID -> user_id
+func snake(s string) string {
+	var b strings.Builder
+	rs := []rune(s)
+	for i, r := range rs {
+		if unicode.IsUpper(r) {
+			// 前一个是小写，或处在连续大写的末尾（IDName 中的 N）
+			if i > 0 && (unicode.IsLower(rs[i-1]) || i+1 < len(rs) && unicode.IsUpper(rs[i-1]) && unicode.IsLower(rs[i+1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (tb *Table) names() string {
+	var b strings.Builder
+	for i, c := range tb.Columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(c.Name)
+	}
+	return b.String()
+}
+
+// CreateTable 建表语句
+func (tb *Table) CreateTable() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE %s (\n", tb.Name)
+	for i, c := range tb.Columns {
+		fmt.Fprintf(&b, "\t%s %s", c.Name, c.Type)
+		if i < len(tb.Columns)-1 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// Insert 插入所有列的语句，参数由 Values 提供
+func (tb *Table) Insert() string {
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		tb.Name, tb.names(), strings.TrimSuffix(strings.Repeat("?, ", len(tb.Columns)), ", "))
+}
+
+// Select 查询所有列的语句，where 非空时追加为条件
+func (tb *Table) Select(where string) string {
+	q := fmt.Sprintf("SELECT %s FROM %s", tb.names(), tb.Name)
+	if where != "" {
+		q += " WHERE " + where
+	}
+	return q
+}
+
+// value 结构体 v 的反射值，v 必须是该表的结构体或其指针
+func (tb *Table) value(v any, addr bool) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if addr {
+		if rv.Kind() != reflect.Pointer || rv.IsNil() {
+			return reflect.Value{}, fmt.Errorf("sqlmap: need non-nil pointer, got %T", v)
+		}
+	}
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Type() != tb.Type {
+		return reflect.Value{}, fmt.Errorf("sqlmap: %T is not %s", v, tb.Type)
+	}
+	return rv, nil
+}
+
+// Values Insert 语句的参数，按列的顺序
+func (tb *Table) Values(v any) ([]any, error) {
+	rv, err := tb.value(v, false)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]any, len(tb.Columns))
+	for i, c := range tb.Columns {
+		args[i] = rv.FieldByIndex(c.Index).Interface()
+	}
+	return args, nil
+}
+
+// Dest 按列名取得 v 中字段的指针，作为 Rows.Scan 的参数
+func (tb *Table) Dest(v any, columns []string) ([]any, error) {
+	rv, err := tb.value(v, true)
+	if err != nil {
+		return nil, err
+	}
+	dest := make([]any, len(columns))
+	for i, name := range columns {
+		j, ok := tb.byName[name]
+		if !ok {
+			return nil, fmt.Errorf("sqlmap: %s: unknown column %s", tb.Name, name)
+		}
+		dest[i] = rv.FieldByIndex(tb.Columns[j].Index).Addr().Interface()
+	}
+	return dest, nil
+}
+
+// Scan 读取 rows 的下一行到 dst（结构体指针）并关闭 rows，没有数据时返回 sql.ErrNoRows
+func Scan(rows *sql.Rows, dst any) error {
+	defer rows.Close()
+	tb, err := Of(dst)
+	if err != nil {
+		return err
+	}
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	dest, err := tb.Dest(dst, cols)
+	if err != nil {
+		return err
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// ScanAll 读取 rows 的所有行，查询的列可以是表中列的任意子集和顺序
+func ScanAll[T any](rows *sql.Rows) ([]T, error) {
+	defer rows.Close()
+	var zero T
+	tb, err := Of(&zero)
+	if err != nil {
+		return nil, err
+	}
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var out []T
+	for rows.Next() {
+		var v T
+		dest, err := tb.Dest(&v, cols)
+		if err != nil {
+			return nil, err
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, rows.Close()
+}
+
+// Execer 用于 Insert 和 CreateTable，*sql.DB 和 *sql.Tx 都满足
+type Execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// Insert 插入一行
+func Insert(db Execer, v any) (sql.Result, error) {
+	tb, err := Of(v)
+	if err != nil {
+		return nil, err
+	}
+	args, err := tb.Values(v)
+	if err != nil {
+		return nil, err
+	}
+	return db.Exec(tb.Insert(), args...)
+}
+
+// CreateTable 按 v 的类型建表
+func CreateTable(db Execer, v any) error {
+	tb, err := Of(v)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(tb.CreateTable())
+	return err
+}
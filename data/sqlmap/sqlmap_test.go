@@ -0,0 +1,205 @@
+package sqlmap
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	_ "yuhen/data/sqlmap/fakedb"
+)
+
+// 对应 data.mem() 中的 Point 和 Value
+type Point struct {
+	X, Y int
+}
+
+type Value struct {
+	ID      int    `field:"uid" type:"integer primary key"`
+	Name    string `field:"name" type:"text"`
+	Data    []byte `field:"data"`
+	Score   float64
+	Active  bool
+	Created time.Time
+	Note    sql.NullString `type:"text"`
+	Point
+	next *Value
+	Skip int `field:"-"`
+}
+
+func open(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("fakedb", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestDDL(t *testing.T) {
+	tb := MustOf(Value{})
+	want := `CREATE TABLE value (
+	uid integer primary key,
+	name text,
+	data blob,
+	score real,
+	active boolean,
+	created timestamp,
+	note text,
+	x integer,
+	y integer
+)`
+	if got := tb.CreateTable(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := tb.Insert(); got != "INSERT INTO value (uid, name, data, score, active, created, note, x, y) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)" {
+		t.Error(got)
+	}
+	if got := tb.Select("uid = ?"); got != "SELECT uid, name, data, score, active, created, note, x, y FROM value WHERE uid = ?" {
+		t.Error(got)
+	}
+	if c := tb.Columns[7]; c.Field != "Point.X" || !reflect.DeepEqual(c.Index, []int{7, 0}) {
+		t.Errorf("%+v", c)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	db := open(t)
+	if err := CreateTable(db, Value{}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	vs := []Value{
+		{ID: 1, Name: "a", Data: []byte{1, 2}, Score: 1.5, Active: true, Created: now, Point: Point{100, 200}},
+		{ID: 2, Name: "b", Note: sql.NullString{String: "n", Valid: true}, Point: Point{X: 3}},
+	}
+	for i := range vs {
+		if _, err := Insert(db, &vs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tb := MustOf(Value{})
+	rows, err := db.Query(tb.Select(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ScanAll[Value](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, vs) {
+		t.Errorf("got  %+v\nwant %+v", got, vs)
+	}
+
+	var v Value
+	rows, err = db.Query(tb.Select("uid = ?"), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Scan(rows, &v); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(v, vs[1]) {
+		t.Errorf("got %+v", v)
+	}
+
+	rows, err = db.Query(tb.Select("uid = ?"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Scan(rows, &v); !errors.Is(err, sql.ErrNoRows) {
+		t.Error(err)
+	}
+}
+
+// 查询的列可以是子集，顺序和表不同
+func TestScanColumns(t *testing.T) {
+	db := open(t)
+	if err := CreateTable(db, Value{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Insert(db, Value{ID: 7, Name: "g", Point: Point{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.Query("SELECT y, name, x FROM value")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ScanAll[Value](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Value{{Name: "g", Point: Point{1, 2}}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v", got)
+	}
+
+	rows, err = db.Query("SELECT uid, skip FROM value")
+	if err == nil {
+		_, err = ScanAll[Value](rows)
+	}
+	if err == nil || !strings.Contains(err.Error(), "skip") {
+		t.Errorf("unknown column: %v", err)
+	}
+}
+
+type user struct {
+	UserID   int64
+	HTTPName string
+}
+
+func (user) TableName() string { return "users" }
+
+func TestNames(t *testing.T) {
+	tb := MustOf(&user{})
+	if tb.Name != "users" || tb.Columns[0].Name != "user_id" || tb.Columns[1].Name != "http_name" {
+		t.Errorf("%s %+v", tb.Name, tb.Columns)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		v    any
+		want string
+	}{
+		{1, "not a struct"},
+		{struct{ C chan int }{}, "no type tag for chan int"},
+		{struct {
+			A int `field:"a"`
+			B int `field:"a"`
+		}{}, "duplicate column a"},
+		{struct{ *Point }{}, "embedded pointer"},
+		{struct{ a int }{}, "has no columns"},
+	}
+	for _, tt := range tests {
+		if _, err := Of(tt.v); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%T: got %v, want %q", tt.v, err, tt.want)
+		}
+	}
+
+	if _, err := MustOf(Value{}).Values(Point{}); err == nil {
+		t.Error("Values accepted another type")
+	}
+	if _, err := MustOf(Value{}).Dest(Value{}, nil); err == nil {
+		t.Error("Dest accepted a non-pointer")
+	}
+}
+
+// 驱动按列类型检查参数，type 标签和字段类型不一致时插入失败
+func TestTypeMismatch(t *testing.T) {
+	type Bad struct {
+		ID string `type:"integer"`
+	}
+	db := open(t)
+	if err := CreateTable(db, Bad{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Insert(db, Bad{ID: "x"}); err == nil || !strings.Contains(err.Error(), "string is not integer") {
+		t.Error(err)
+	}
+}
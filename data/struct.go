@@ -82,6 +82,8 @@ func mem() {
 		0xc00020a038, 56, 8		int
 		0xc00020a040, 64, 8		int
 	*/
+	// 同样的偏移表可以由 data/memview.Fields[Value]() 得到，FieldPtr 按名字（包括提升的 x、y）取字段指针
+	// data/bincodec 按同样的规则编码：默认保留填充，Packed 去掉填充
 
 	// 对齐以所有字段中最长的基础类型宽度为准, 划重点 为基础类型
 	// 编译器目的：为了最大限度减少读写所需要的指令，也因为某些架构平台自身的要求
@@ -298,6 +300,7 @@ func tag() {
 
 	// 运行期 可用反射获取标签信息 经常被用作格式校验 数据库关系映射
 	// 感觉还是挺方便的
+	// 按 validate 标签校验的实现见 data/validate，按 field、type 标签映射数据表见 data/sqlmap
 	type User struct {
 		id   int    `field:"uid" type:"integer"`
 		name string `field:"name" type:"text"`
@@ -366,6 +369,7 @@ func equals() {
 	d2 := data{x: 100}
 	//_ = d1 == d2 //data/struct.go:40:6: invalid operation: d1 == d2 (struct containing map[string]int cannot be compared)
 	fmt.Println(d1, d2)
+	// 逐字段比较（包括 map、切片和循环引用）可以用 data/deep 的 Equal 和 Diff
 
 	// 类型不同 不能比较
 	type data1 struct {
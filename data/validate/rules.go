@@ -0,0 +1,135 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// 内置规则，required、omitempty 和 dive 由编译器直接处理
+var builtins = map[string]Rule{
+	"min":   compare(func(n, p float64) bool { return n >= p }),
+	"max":   compare(func(n, p float64) bool { return n <= p }),
+	"len":   length,
+	"regex": regex,
+	"oneof": oneof,
+}
+
+// size 数值取值本身，字符串取字符数，切片、数组、map 取长度
+func size(t reflect.Type) (func(v reflect.Value) float64, error) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(v reflect.Value) float64 { return float64(v.Int()) }, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return func(v reflect.Value) float64 { return float64(v.Uint()) }, nil
+	case reflect.Float32, reflect.Float64:
+		return func(v reflect.Value) float64 { return v.Float() }, nil
+	case reflect.String:
+		return func(v reflect.Value) float64 { return float64(utf8.RuneCountInString(v.String())) }, nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return func(v reflect.Value) float64 { return float64(v.Len()) }, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// compare min、max：数值比较大小，其余比较长度
+func compare(ok func(n, p float64) bool) Rule {
+	return func(t reflect.Type, param string) (Check, error) {
+		p, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil, err
+		}
+		n, err := size(t)
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value) bool { return ok(n(v), p) }, nil
+	}
+}
+
+// length 字符串的字符数、切片或 map 的长度等于参数
+func length(t reflect.Type, param string) (Check, error) {
+	p, err := strconv.Atoi(param)
+	if err != nil {
+		return nil, err
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return func(v reflect.Value) bool { return utf8.RuneCountInString(v.String()) == p }, nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return func(v reflect.Value) bool { return v.Len() == p }, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// regex 字符串匹配正则表达式，表达式只编译一次
+func regex(t reflect.Type, param string) (Check, error) {
+	if t.Kind() != reflect.String {
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+	re, err := regexp.Compile(param)
+	if err != nil {
+		return nil, err
+	}
+	return func(v reflect.Value) bool { return re.MatchString(v.String()) }, nil
+}
+
+// oneof 值是以空格分隔的参数之一，支持字符串和整数
+func oneof(t reflect.Type, param string) (Check, error) {
+	opts := strings.Fields(param)
+	if len(opts) == 0 {
+		return nil, fmt.Errorf("no options")
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return func(v reflect.Value) bool {
+			s := v.String()
+			for _, o := range opts {
+				if s == o {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		ns := make([]int64, len(opts))
+		for i, o := range opts {
+			n, err := strconv.ParseInt(o, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			ns[i] = n
+		}
+		return func(v reflect.Value) bool {
+			n := v.Int()
+			for _, o := range ns {
+				if n == o {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		ns := make([]uint64, len(opts))
+		for i, o := range opts {
+			n, err := strconv.ParseUint(o, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			ns[i] = n
+		}
+		return func(v reflect.Value) bool {
+			n := v.Uint()
+			for _, o := range ns {
+				if n == o {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
@@ -0,0 +1,480 @@
+// Package validate 根据结构体标签校验字段，是 data.tag() 中"标签经常被用作格式校验"的实现
+//
+//	type User struct {
+//		Name  string            `json:"name" validate:"required,min=1,max=64,regex=^[a-z]+$"`
+//		Role  string            `json:"role" validate:"oneof=admin user"`
+//		Tags  []string          `json:"tags" validate:"max=8,dive,min=1"`
+//		Items []Item            `json:"items"`            // 嵌套的结构体自动校验
+//		Meta  map[string]*Item  `json:"meta"`
+//	}
+//
+// 标签中的规则以逗号分隔。regex 的参数可能包含逗号，参数到下一个以已知规则开头的逗号为止，
+// 如 regex=^[a-z]{1,3}$,oneof=a b；也可以用单引号括起来，如 regex='^(a|b),c$'。
+// dive 之后的规则作用于切片、数组或 map 的元素。
+// 规则按类型编译一次后缓存，之后的校验只遍历字段，不再解析标签
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Check 编译后的规则，返回值是否满足
+type Check func(v reflect.Value) bool
+
+// Rule 根据字段类型和参数编译出 Check，类型或参数不合适时返回错误
+// t 为去掉指针后的字段类型
+type Rule func(t reflect.Type, param string) (Check, error)
+
+// FieldError 一个字段没有通过校验
+type FieldError struct {
+	Path  string // JSON 路径，如 items[0].name、meta.key
+	Rule  string // 规则名，必填字段为 required
+	Param string
+	Value any
+}
+
+func (e *FieldError) Error() string {
+	if e.Param == "" {
+		return fmt.Sprintf("validate: %s: failed %s", e.Path, e.Rule)
+	}
+	return fmt.Sprintf("validate: %s: failed %s=%s", e.Path, e.Rule, e.Param)
+}
+
+// Errors 一次校验中所有失败的字段，按字段顺序排列
+type Errors []*FieldError
+
+func (es Errors) Error() string {
+	var b strings.Builder
+	for i, e := range es {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(e.Error())
+	}
+	return b.String()
+}
+
+// ErrNotStruct 参数不是结构体或结构体指针
+var ErrNotStruct = errors.New("validate: not a struct")
+
+// Validator 保存规则和按类型编译的结果，并发安全
+type Validator struct {
+	mu    sync.Mutex
+	rules map[string]Rule
+	cache sync.Map // reflect.Type -> *plan
+}
+
+// New 只包含内置规则：required omitempty min max len regex oneof
+func New() *Validator {
+	v := &Validator{rules: make(map[string]Rule)}
+	for name, r := range builtins {
+		v.rules[name] = r
+	}
+	return v
+}
+
+var std = New()
+
+// Register 在默认的 Validator 中注册规则
+func Register(name string, r Rule) error {
+	return std.Register(name, r)
+}
+
+// Struct 使用默认的 Validator 校验
+func Struct(v any) error {
+	return std.Struct(v)
+}
+
+// Register 注册规则，同名的规则被替换，已编译的类型重新编译
+func (x *Validator) Register(name string, r Rule) error {
+	if name == "" || strings.ContainsAny(name, ",= ") || name == "required" || name == "omitempty" || name == "dive" {
+		return fmt.Errorf("validate: invalid rule name %q", name)
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.rules[name] = r
+	x.cache.Range(func(k, _ any) bool {
+		x.cache.Delete(k)
+		return true
+	})
+	return nil
+}
+
+// Struct 校验结构体 v，v 可以是指针
+// 校验失败返回 Errors；标签中的规则有误时返回描述错误的 error
+func (x *Validator) Struct(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ErrNotStruct
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return ErrNotStruct
+	}
+
+	p, err := x.plan(rv.Type())
+	if err != nil {
+		return err
+	}
+	w := walkers.Get().(*walker)
+	w.plan(p, rv)
+	errs := w.errs
+	w.reset()
+	walkers.Put(w)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// plan 一个结构体类型中需要校验的字段
+type plan struct {
+	fields []field
+}
+
+type field struct {
+	index int
+	name  string // JSON 名字，嵌入的结构体为空（字段提升到外层）
+	node  *node
+}
+
+// node 一个值上的规则
+type node struct {
+	required  bool
+	omitempty bool
+	checks    []check
+	sub       *plan // 结构体（去掉指针后）的字段
+	elem      *node // 切片、数组、map 的元素
+}
+
+type check struct {
+	rule, param string
+	fn          Check
+}
+
+func (x *Validator) plan(t reflect.Type) (*plan, error) {
+	if p, ok := x.cache.Load(t); ok {
+		return p.(*plan), nil
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	// 递归的类型（如链表）在编译完成前就放入 building，引用自身时直接返回
+	c := &compiler{rules: x.rules, building: make(map[reflect.Type]*plan)}
+	p, err := c.plan(t)
+	if err != nil {
+		return nil, err
+	}
+	for t, p := range c.building {
+		x.cache.Store(t, p)
+	}
+	return p, nil
+}
+
+type compiler struct {
+	rules    map[string]Rule
+	building map[reflect.Type]*plan
+}
+
+func (c *compiler) plan(t reflect.Type) (*plan, error) {
+	if p := c.building[t]; p != nil {
+		return p, nil
+	}
+	p := &plan{}
+	c.building[t] = p
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+		rules, err := c.splitRules(tag)
+		if err != nil {
+			return nil, fmt.Errorf("validate: %s.%s: %w", t, sf.Name, err)
+		}
+		n, err := c.node(sf.Type, rules)
+		if err != nil {
+			return nil, fmt.Errorf("validate: %s.%s: %w", t, sf.Name, err)
+		}
+		if n != nil {
+			p.fields = append(p.fields, field{index: i, name: jsonName(sf), node: n})
+		}
+	}
+	return p, nil
+}
+
+// node 编译作用于类型 t 的规则，没有任何需要校验的内容时返回 nil
+func (c *compiler) node(t reflect.Type, rules []string) (*node, error) {
+	n := &node{}
+	bt := t
+	for bt.Kind() == reflect.Pointer {
+		bt = bt.Elem()
+	}
+loop:
+	for i, r := range rules {
+		name, param, _ := strings.Cut(r, "=")
+		switch name {
+		case "required":
+			n.required = true
+			continue
+		case "omitempty":
+			n.omitempty = true
+			continue
+		case "dive":
+			switch bt.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+			default:
+				return nil, fmt.Errorf("dive on %s", t)
+			}
+			elem, err := c.node(bt.Elem(), rules[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			n.elem = elem
+			break loop
+		}
+		rule := c.rules[name]
+		if rule == nil {
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+		fn, err := rule(bt, param)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r, err)
+		}
+		n.checks = append(n.checks, check{name, param, fn})
+	}
+
+	switch bt.Kind() {
+	case reflect.Struct:
+		sub, err := c.plan(bt)
+		if err != nil {
+			return nil, err
+		}
+		n.sub = sub
+	case reflect.Slice, reflect.Array, reflect.Map:
+		// 没有 dive 时也校验结构体元素中的字段
+		if n.elem == nil {
+			elem, err := c.node(bt.Elem(), nil)
+			if err != nil {
+				return nil, err
+			}
+			n.elem = elem
+		}
+	}
+
+	// 编译中的递归类型 sub 可能暂时为空，不能据此省略
+	if !n.required && len(n.checks) == 0 && n.sub == nil && n.elem == nil {
+		return nil, nil
+	}
+	return n, nil
+}
+
+// splitRules 以逗号分隔，regex 的参数单独处理
+func (c *compiler) splitRules(tag string) ([]string, error) {
+	var rules []string
+	for tag != "" {
+		r, rest, _ := strings.Cut(tag, ",")
+		if strings.HasPrefix(strings.TrimSpace(r), "regex=") {
+			var err error
+			if r, rest, err = c.regexRule(strings.TrimSpace(tag)); err != nil {
+				return nil, err
+			}
+		}
+		if r = strings.TrimSpace(r); r != "" {
+			rules = append(rules, r)
+		}
+		tag = rest
+	}
+	return rules, nil
+}
+
+// regexRule 从 tag 开头切出 regex 规则：单引号括起的参数到引号为止，
+// 否则到下一个以已知规则开头的逗号为止
+func (c *compiler) regexRule(tag string) (rule, rest string, err error) {
+	param := strings.TrimPrefix(tag, "regex=")
+	if strings.HasPrefix(param, "'") {
+		end := strings.IndexByte(param[1:], '\'')
+		if end < 0 {
+			return "", "", fmt.Errorf("%s: unterminated quote", tag)
+		}
+		rest = param[end+2:]
+		if rest != "" && rest[0] != ',' {
+			return "", "", fmt.Errorf("%s: unexpected %q after quote", tag, rest)
+		}
+		return "regex=" + param[1:end+1], strings.TrimPrefix(rest, ","), nil
+	}
+	for i := 0; i < len(param); i++ {
+		if param[i] == ',' && c.isRule(param[i+1:]) {
+			return "regex=" + param[:i], param[i+1:], nil
+		}
+	}
+	return tag, "", nil
+}
+
+// isRule s 是否以规则名开头，后面跟着 =、逗号或结尾
+func (c *compiler) isRule(s string) bool {
+	name := s
+	if i := strings.IndexAny(s, "=,"); i >= 0 {
+		name = s[:i]
+	}
+	switch name = strings.TrimSpace(name); name {
+	case "required", "omitempty", "dive":
+		return true
+	}
+	return c.rules[name] != nil
+}
+
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	switch {
+	case name == "-":
+		return sf.Name
+	case name != "":
+		return name
+	case sf.Anonymous && sf.Type.Kind() == reflect.Struct:
+		return ""
+	}
+	return sf.Name
+}
+
+// seg 路径中的一段
+type seg struct {
+	name  string
+	index int
+	key   reflect.Value
+	kind  uint8 // 0 字段 1 索引 2 map 键
+}
+
+// walker 一次校验的状态，路径只在出错时转换为字符串
+// 复用 walker 而不是在栈上链接路径：递归中互相引用的局部变量会被逃逸分析移到堆上
+type walker struct {
+	path []seg
+	errs Errors
+}
+
+var walkers = sync.Pool{New: func() any { return new(walker) }}
+
+// reset 清除路径中的 map 键，避免池中的 walker 引用已校验的数据
+func (w *walker) reset() {
+	w.path = w.path[:cap(w.path)]
+	for i := range w.path {
+		w.path[i] = seg{}
+	}
+	w.path, w.errs = w.path[:0], nil
+}
+
+func (w *walker) String() string {
+	var b strings.Builder
+	for _, s := range w.path {
+		switch s.kind {
+		case 0:
+			if s.name == "" {
+				continue
+			}
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(s.name)
+		case 1:
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(s.index))
+			b.WriteByte(']')
+		case 2:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			fmt.Fprint(&b, s.key.Interface())
+		}
+	}
+	return b.String()
+}
+
+func (w *walker) fail(rule, param string, v reflect.Value) {
+	e := &FieldError{Path: w.String(), Rule: rule, Param: param}
+	if v.IsValid() {
+		e.Value = v.Interface()
+	}
+	w.errs = append(w.errs, e)
+}
+
+func (w *walker) plan(p *plan, v reflect.Value) {
+	for i := range p.fields {
+		f := &p.fields[i]
+		w.path = append(w.path, seg{name: f.name})
+		w.node(f.node, v.Field(f.index))
+		w.path = w.path[:len(w.path)-1]
+	}
+}
+
+func (w *walker) node(n *node, v reflect.Value) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			// 可选的指针为 nil 时不校验其余规则
+			if n.required {
+				w.fail("required", "", reflect.Value{})
+			}
+			return
+		}
+		if v.Kind() == reflect.Interface {
+			// 接口中的动态类型不在编译的规则中，只校验是否为 nil
+			return
+		}
+		v = v.Elem()
+	}
+	if isEmpty(v) {
+		if n.required {
+			w.fail("required", "", v)
+			return
+		}
+		if n.omitempty {
+			return
+		}
+	}
+	for _, c := range n.checks {
+		if !c.fn(v) {
+			// 同一个值只报告第一条失败的规则
+			w.fail(c.rule, c.param, v)
+			return
+		}
+	}
+	if n.sub != nil {
+		w.plan(n.sub, v)
+	}
+	if n.elem != nil {
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				w.path = append(w.path, seg{index: i, kind: 1})
+				w.node(n.elem, v.Index(i))
+				w.path = w.path[:len(w.path)-1]
+			}
+		case reflect.Map:
+			// MapRange 返回的迭代器分配在堆上
+			var it reflect.MapIter
+			it.Reset(v)
+			for it.Next() {
+				w.path = append(w.path, seg{key: it.Key(), kind: 2})
+				w.node(n.elem, it.Value())
+				w.path = w.path[:len(w.path)-1]
+			}
+		}
+	}
+}
+
+// isEmpty 零值，以及长度为 0 的切片和 map
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
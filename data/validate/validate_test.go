@@ -0,0 +1,304 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Address struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"omitempty,len=6,regex=^[0-9]+$"`
+}
+
+type Item struct {
+	Name  string `json:"name" validate:"min=1,max=8"`
+	Count int    `json:"count" validate:"min=1"`
+}
+
+type Base struct {
+	ID uint64 `json:"id" validate:"required"`
+}
+
+type User struct {
+	Base
+	Name    string           `json:"name" validate:"required,min=1,max=64,regex=^[a-z]+$"`
+	Role    string           `json:"role" validate:"oneof=admin user"`
+	Level   int              `json:"level" validate:"oneof=1 2 3"`
+	Tags    []string         `json:"tags" validate:"max=3,dive,min=1"`
+	Addr    *Address         `json:"addr"`
+	Items   []Item           `json:"items" validate:"required"`
+	Meta    map[string]*Item `json:"meta"`
+	Comment string           `validate:"-"`
+	secret  string           `validate:"required"`
+}
+
+func valid() *User {
+	return &User{
+		Base:  Base{ID: 1},
+		Name:  "alice",
+		Role:  "admin",
+		Level: 2,
+		Tags:  []string{"a", "b"},
+		Addr:  &Address{City: "sh", Zip: "200000"},
+		Items: []Item{{Name: "pen", Count: 1}},
+		Meta:  map[string]*Item{"x": {Name: "cup", Count: 2}},
+	}
+}
+
+// paths 按 path:rule 列出错误
+func paths(t *testing.T, err error) []string {
+	t.Helper()
+	var es Errors
+	if !errors.As(err, &es) {
+		t.Fatalf("got %v, want Errors", err)
+	}
+	var out []string
+	for _, e := range es {
+		out = append(out, e.Path+":"+e.Rule)
+	}
+	return out
+}
+
+func TestValid(t *testing.T) {
+	if err := Struct(valid()); err != nil {
+		t.Fatal(err)
+	}
+	if err := Struct(*valid()); err != nil {
+		t.Fatal(err)
+	}
+	// 可选的指针为 nil，omitempty 的字段为空
+	u := valid()
+	u.Addr, u.Meta = nil, nil
+	if err := Struct(u); err != nil {
+		t.Fatal(err)
+	}
+	u = valid()
+	u.Addr.Zip = ""
+	if err := Struct(u); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	u := valid()
+	u.ID = 0
+	u.Name = "Alice"
+	u.Role = "root"
+	u.Level = 4
+	u.Tags = []string{"a", ""}
+	u.Addr = &Address{Zip: "12345a"}
+	u.Items = append(u.Items, Item{Name: "notebooks", Count: 0})
+	u.Meta["y"] = &Item{Name: "", Count: 1}
+
+	err := Struct(u)
+	want := []string{
+		"id:required",
+		"name:regex",
+		"role:oneof",
+		"level:oneof",
+		"tags[1]:min",
+		"addr.city:required",
+		"addr.zip:regex",
+		"items[1].name:max",
+		"items[1].count:min",
+		"meta.y.name:min",
+	}
+	if got := paths(t, err); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %v\nwant %v", got, want)
+	}
+	if !strings.Contains(err.Error(), "validate: items[1].name: failed max=8") {
+		t.Error(err)
+	}
+
+	var es Errors
+	errors.As(err, &es)
+	if es[1].Value != "Alice" {
+		t.Errorf("value %v", es[1].Value)
+	}
+
+	u = valid()
+	u.Items = []Item{}
+	u.Tags = []string{"a", "b", "c", "d"}
+	if got := paths(t, Struct(u)); !reflect.DeepEqual(got, []string{"tags:max", "items:required"}) {
+		t.Error(got)
+	}
+}
+
+// regex 之后的规则照常解析，参数中的逗号保留
+func TestRegexComma(t *testing.T) {
+	type T struct {
+		Name string `json:"name" validate:"required,min=1,max=64,regex=^[a-z]+$,oneof=a b"`
+		Code string `json:"code" validate:"omitempty,regex=^[a-z]{1,2}$,min=2"`
+		Pair string `json:"pair" validate:"omitempty,regex='^(a|b),c$',len=3"`
+	}
+	for _, c := range []struct {
+		v    T
+		want []string
+	}{
+		{T{Name: "a"}, nil},
+		{T{Name: "zzz"}, []string{"name:oneof"}},
+		{T{Name: "A"}, []string{"name:regex"}}, // 每个字段只报告第一条失败的规则
+		{T{Name: "b", Code: "ab", Pair: "b,c"}, nil},
+		{T{Name: "b", Code: "a", Pair: "c,c"}, []string{"code:min", "pair:regex"}},
+	} {
+		err := Struct(c.v)
+		if c.want == nil {
+			if err != nil {
+				t.Errorf("%+v: %v", c.v, err)
+			}
+			continue
+		}
+		if got := paths(t, err); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%+v: got %v, want %v", c.v, got, c.want)
+		}
+	}
+	var es Errors
+	errors.As(Struct(T{Name: "zzz"}), &es)
+	if es[0].Param != "a b" {
+		t.Errorf("param %q", es[0].Param)
+	}
+}
+
+func TestRecursive(t *testing.T) {
+	type Node struct {
+		Name string `json:"name" validate:"required"`
+		Next *Node  `json:"next"`
+	}
+	n := &Node{Name: "a", Next: &Node{Name: "b", Next: &Node{}}}
+	if got := paths(t, Struct(n)); !reflect.DeepEqual(got, []string{"next.next.name:required"}) {
+		t.Error(got)
+	}
+}
+
+func TestRuleErrors(t *testing.T) {
+	tests := []struct {
+		v    any
+		want string
+	}{
+		{struct {
+			A int `validate:"regex=a"`
+		}{}, "A: regex=a: unsupported type int"},
+		{struct {
+			A string `validate:"min=x"`
+		}{}, `A: min=x: strconv.ParseFloat`},
+		{struct {
+			A string `validate:"foo"`
+		}{}, `A: unknown rule "foo"`},
+		{struct {
+			A string `validate:"dive"`
+		}{}, "A: dive on string"},
+		{struct {
+			A []string `validate:"dive,regex=("`
+		}{}, "A: regex=(: error parsing regexp"},
+		{struct {
+			A string `validate:"regex='a,min=1"`
+		}{}, "A: regex='a,min=1: unterminated quote"},
+	}
+	for _, tt := range tests {
+		err := New().Struct(tt.v)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("got %v, want %q", err, tt.want)
+		}
+	}
+
+	if err := Struct(1); err != ErrNotStruct {
+		t.Error(err)
+	}
+	if err := Struct((*User)(nil)); err != ErrNotStruct {
+		t.Error(err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	type Order struct {
+		Qty int `json:"qty" validate:"even"`
+	}
+	v := New()
+	if err := v.Struct(Order{Qty: 3}); err == nil || !strings.Contains(err.Error(), `unknown rule "even"`) {
+		t.Fatal(err)
+	}
+
+	err := v.Register("even", func(t reflect.Type, param string) (Check, error) {
+		if t.Kind() != reflect.Int {
+			return nil, errors.New("even: int only")
+		}
+		return func(v reflect.Value) bool { return v.Int()%2 == 0 }, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := paths(t, v.Struct(Order{Qty: 3})); !reflect.DeepEqual(got, []string{"qty:even"}) {
+		t.Error(got)
+	}
+	if err := v.Struct(Order{Qty: 4}); err != nil {
+		t.Error(err)
+	}
+	// 不影响默认的 Validator
+	if err := Struct(Order{Qty: 3}); err == nil {
+		t.Error("default validator should not know even")
+	}
+
+	if err := v.Register("a,b", nil); err == nil {
+		t.Error("invalid name accepted")
+	}
+}
+
+// 缓存编译结果后校验本身不分配内存
+// map 的每个非指针键由 reflect 复制一次，所以这里去掉 Meta
+func TestAllocs(t *testing.T) {
+	u := valid()
+	u.Meta = nil
+	if err := Struct(u); err != nil {
+		t.Fatal(err)
+	}
+	if n := testing.AllocsPerRun(100, func() { Struct(u) }); n != 0 {
+		t.Errorf("%v allocs per run", n)
+	}
+}
+
+func BenchmarkStruct(b *testing.B) {
+	u := valid()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := Struct(u); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// 每次使用新的 Validator，包括解析标签和编译规则
+func BenchmarkStructUncached(b *testing.B) {
+	u := valid()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := New().Struct(u); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStructInvalid(b *testing.B) {
+	u := valid()
+	u.Name = "Alice"
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := Struct(u); err == nil {
+			b.Fatal("want error")
+		}
+	}
+}
+
+// go test -bench . -benchmem ./data/validate
+/*
+goos: linux
+goarch: amd64
+pkg: yuhen/data/validate
+cpu: Intel(R) Xeon(R) Processor
+BenchmarkStruct                   704232              1499 ns/op              16 B/op          1 allocs/op
+BenchmarkStructUncached            60260             27003 ns/op            8531 B/op        138 allocs/op
+BenchmarkStructInvalid            866871              1841 ns/op             168 B/op          7 allocs/op
+
+缓存后唯一的分配来自 Meta 的 map 键，失败时才构造路径字符串和 FieldError
+*/
@@ -0,0 +1,113 @@
+package fun
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// deferError 里的问题：循环内 defer f.Close() 要等到函数返回才执行，1000 个文件一直占着
+// 解决思路就是把 defer 本身做成一个栈，由调用方决定何时清空
+
+// Closer 延迟清理栈，和 defer 一样 LIFO，但可以在任意时刻 CloseAll
+// 零值可用，并发安全
+type Closer struct {
+	mu  sync.Mutex
+	fns []func() error
+}
+
+// Push 压入 io.Closer，nil 直接忽略
+func (c *Closer) Push(x io.Closer) {
+	if x == nil {
+		return
+	}
+	c.PushFunc(x.Close)
+}
+
+// PushFunc 压入清理函数
+func (c *Closer) PushFunc(f func() error) {
+	if f == nil {
+		return
+	}
+	c.mu.Lock()
+	c.fns = append(c.fns, f)
+	c.mu.Unlock()
+}
+
+// Len 还未执行的清理函数个数
+func (c *Closer) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.fns)
+}
+
+// CloseAll 按 LIFO 顺序执行全部清理函数，某个失败不影响后续执行
+// 所有错误通过 errors.Join 打包返回，和 defer 中 panic 不影响后续延迟调用是一个道理
+func (c *Closer) CloseAll() error {
+	c.mu.Lock()
+	fns := c.fns
+	c.fns = nil
+	c.mu.Unlock()
+
+	var errs []error
+	for i := len(fns) - 1; i >= 0; i-- {
+		if err := fns[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Scope 作用域，相当于把循环体封装成函数，函数结束时统一清理
+type Scope struct {
+	Closer
+	closed bool
+	where  string // 仅 debug 构建记录创建位置
+}
+
+// NewScope 手动管理的作用域，必须调用 Close
+// 使用 -tags debug 构建时，未 Close 的 Scope 被回收时会输出泄漏警告
+func NewScope() *Scope {
+	return newScope()
+}
+
+// newScope 由 NewScope、WithScope 直接调用，trackScope 记录的是它们的调用方
+func newScope() *Scope {
+	s := &Scope{}
+	trackScope(s)
+	return s
+}
+
+// Close 清理作用域内压入的全部资源，重复调用无副作用
+func (s *Scope) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	return s.Closer.CloseAll()
+}
+
+// CloseAll 同 Close，覆盖嵌入的 Closer.CloseAll，否则 debug 构建会误报泄漏
+func (s *Scope) CloseAll() error {
+	return s.Close()
+}
+
+// WithScope 执行 f，f 返回（包括 panic）后立即清理 f 中压入的资源
+// 适合循环体:
+//
+//	for _, name := range names {
+//		err := WithScope(func(s *Scope) error {
+//			f, err := os.Open(name)
+//			if err != nil {
+//				return err
+//			}
+//			s.Push(f)
+//			...
+//		})
+//	}
+func WithScope(f func(s *Scope) error) (err error) {
+	s := newScope()
+	defer func() {
+		err = errors.Join(err, s.Close())
+	}()
+	return f(s)
+}
@@ -0,0 +1,26 @@
+//go:build debug
+
+package fun
+
+import (
+	"fmt"
+	"log"
+	"runtime"
+)
+
+// debug 构建：记录 Scope 的创建位置，GC 回收时还没 Close 就输出警告
+func trackScope(s *Scope) {
+	// 0: trackScope 1: newScope 2: NewScope 或 WithScope 3: 调用方
+	if _, file, line, ok := runtime.Caller(3); ok {
+		s.where = fmt.Sprintf("%s:%d", file, line)
+	}
+
+	runtime.SetFinalizer(s, func(s *Scope) {
+		s.mu.Lock()
+		closed, n := s.closed, len(s.fns)
+		s.mu.Unlock()
+		if !closed {
+			log.Printf("fun: scope created at %s was never closed (%d pending)", s.where, n)
+		}
+	})
+}
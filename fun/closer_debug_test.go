@@ -0,0 +1,76 @@
+//go:build debug
+
+package fun
+
+import (
+	"log"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// logBuffer finalizer 在单独的 goroutine 中写日志
+type logBuffer struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (w *logBuffer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.b.Write(p)
+}
+
+func (w *logBuffer) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.b.String()
+}
+
+// scopes 创建三个作用域后全部丢弃：CloseAll 关闭的、WithScope 中的、忘记关闭的
+//
+//go:noinline
+func scopes() (closed, with, leaked string) {
+	s := NewScope()
+	s.CloseAll()
+	closed = s.where
+
+	WithScope(func(s *Scope) error {
+		with = s.where
+		return nil
+	})
+
+	leaked = NewScope().where
+	return
+}
+
+func TestScopeLeakWarning(t *testing.T) {
+	var buf logBuffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+
+	closed, with, leaked := scopes()
+	// 记录的是调用方的位置，而不是 closer.go
+	for _, where := range []string{closed, with, leaked} {
+		if !strings.Contains(where, "closer_debug_test.go:") {
+			t.Errorf("where = %q", where)
+		}
+	}
+
+	for i := 0; i < 100 && !strings.Contains(buf.String(), leaked); i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	runtime.GC()
+	time.Sleep(10 * time.Millisecond)
+
+	out := buf.String()
+	if !strings.Contains(out, "scope created at "+leaked+" was never closed") {
+		t.Fatalf("no warning for %s:\n%s", leaked, out)
+	}
+	if strings.Contains(out, closed) || strings.Contains(out, with) {
+		t.Errorf("closed scopes reported:\n%s", out)
+	}
+}
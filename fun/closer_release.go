@@ -0,0 +1,6 @@
+//go:build !debug
+
+package fun
+
+// 非 debug 构建不设置 finalizer，避免额外开销
+func trackScope(*Scope) {}
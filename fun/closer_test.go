@@ -0,0 +1,120 @@
+package fun
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func openFDs(t *testing.T) int {
+	t.Helper()
+	es, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		t.Skip("no /proc/self/fd:", err)
+	}
+	return len(es)
+}
+
+func TestCloserLIFO(t *testing.T) {
+	var c Closer
+	var order []int
+	for i := 0; i < 3; i++ {
+		i := i
+		c.PushFunc(func() error {
+			order = append(order, i)
+			return nil
+		})
+	}
+
+	if err := c.CloseAll(); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 3 || order[0] != 2 || order[1] != 1 || order[2] != 0 {
+		t.Fatalf("order = %v, want [2 1 0]", order)
+	}
+	if c.Len() != 0 {
+		t.Fatalf("len = %d after CloseAll", c.Len())
+	}
+}
+
+func TestCloserJoinErrors(t *testing.T) {
+	e1, e2 := errors.New("e1"), errors.New("e2")
+	var c Closer
+	ran := 0
+	c.PushFunc(func() error { ran++; return e1 })
+	c.PushFunc(func() error { ran++; return nil })
+	c.PushFunc(func() error { ran++; return e2 })
+
+	err := c.CloseAll()
+	if ran != 3 {
+		t.Fatalf("ran = %d, want 3", ran)
+	}
+	if !errors.Is(err, e1) || !errors.Is(err, e2) {
+		t.Fatalf("err = %v, want e1 and e2", err)
+	}
+}
+
+func TestWithScopePanic(t *testing.T) {
+	closed := false
+	func() {
+		defer func() { recover() }()
+		WithScope(func(s *Scope) error {
+			s.PushFunc(func() error { closed = true; return nil })
+			panic("p1")
+		})
+	}()
+	if !closed {
+		t.Fatal("scope not closed after panic")
+	}
+}
+
+// deferError 的改写：循环内打开真实文件，描述符数量不应随循环次数增长
+func TestWithScopeBoundedFDs(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "x")
+	if err := os.WriteFile(name, []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	base := openFDs(t)
+	for i := 0; i < 1000; i++ {
+		err := WithScope(func(s *Scope) error {
+			f, err := os.Open(name)
+			if err != nil {
+				return err
+			}
+			s.Push(f)
+
+			if n := openFDs(t); n > base+1 {
+				t.Fatalf("iteration %d: %d fds open, base %d", i, n, base)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := openFDs(t); n > base {
+		t.Fatalf("%d fds open after loop, base %d", n, base)
+	}
+}
+
+func TestScopeCloseFileTwice(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewScope()
+	s.Push(f)
+	s.Push(f) // 同一个文件压两次，第二次 Close 报错
+	err = s.Close()
+	if err == nil || !strings.Contains(err.Error(), "already closed") {
+		t.Fatalf("err = %v, want already closed", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("second Close = %v, want nil", err)
+	}
+}
@@ -0,0 +1,355 @@
+package fun
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// proxy 和 partial 只能处理固定签名 func() / func(int)
+// 这里用泛型统一成 Func[A, R]，装饰器之间可以任意组合，并且都能感知 context
+
+// Func 被装饰函数的统一签名，多参数用结构体打包，无参数用 struct{}
+type Func[A, R any] func(ctx context.Context, a A) (R, error)
+
+// Decorator 装饰器，输入输出签名相同，所以可以层层嵌套
+type Decorator[A, R any] func(Func[A, R]) Func[A, R]
+
+// Chain 依次应用装饰器，ds[0] 在最外层
+func Chain[A, R any](f Func[A, R], ds ...Decorator[A, R]) Func[A, R] {
+	for i := len(ds) - 1; i >= 0; i-- {
+		f = ds[i](f)
+	}
+	return f
+}
+
+// Lift 把普通函数转换为 Func，忽略 ctx
+func Lift[A, R any](f func(A) (R, error)) Func[A, R] {
+	return func(_ context.Context, a A) (R, error) {
+		return f(a)
+	}
+}
+
+// Clock 时间源，测试时替换为假时钟，让计时行为可控
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer AfterFunc 返回的定时器，*time.Timer 满足该接口
+type Timer interface {
+	Stop() bool
+}
+
+// SystemClock 真实时钟
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+func clockOr(c Clock) Clock {
+	if c == nil {
+		return SystemClock
+	}
+	return c
+}
+
+// Timed 对应 proxy，统计每次调用耗时并交给 report
+func Timed[A, R any](clock Clock, report func(d time.Duration, err error)) Decorator[A, R] {
+	clock = clockOr(clock)
+	return func(f Func[A, R]) Func[A, R] {
+		return func(ctx context.Context, a A) (r R, err error) {
+			start := clock.Now()
+			defer func() {
+				report(clock.Now().Sub(start), err)
+			}()
+			return f(ctx, a)
+		}
+	}
+}
+
+// RetryOptions 重试参数
+type RetryOptions struct {
+	Attempts  int              // 总尝试次数，<= 0 视为 1
+	Base      time.Duration    // 首次重试前的等待时间，之后每次翻倍
+	Max       time.Duration    // 等待时间上限，0 表示不限
+	Jitter    float64          // 抖动比例 [0, 1]，超出时截断，实际等待 delay * (1 - Jitter*rand)
+	Retryable func(error) bool // 为 nil 时所有错误都重试
+	Clock     Clock            // 为 nil 时使用 SystemClock
+	Rand      func() float64   // 为 nil 时使用 math/rand
+}
+
+func (o RetryOptions) delay(n int) time.Duration {
+	d := o.Base
+	for i := 0; i < n && (o.Max <= 0 || d < o.Max); i++ {
+		if d > math.MaxInt64/2 { // 没有上限时次数多了会溢出成负数，饱和到最大值
+			d = math.MaxInt64
+			break
+		}
+		d *= 2
+	}
+	if o.Max > 0 && d > o.Max {
+		d = o.Max
+	}
+	if j := o.Jitter; j > 0 {
+		if j > 1 { // 大于 1 时等待时间会变成负数
+			j = 1
+		}
+		rnd := o.Rand
+		if rnd == nil {
+			rnd = rand.Float64
+		}
+		d -= time.Duration(float64(d) * j * rnd())
+	}
+	return d
+}
+
+// Retry 失败后按指数退避重试，ctx 取消时立即返回
+func Retry[A, R any](o RetryOptions) Decorator[A, R] {
+	clock := clockOr(o.Clock)
+	if o.Attempts <= 0 {
+		o.Attempts = 1
+	}
+	return func(f Func[A, R]) Func[A, R] {
+		return func(ctx context.Context, a A) (r R, err error) {
+			for i := 0; i < o.Attempts; i++ {
+				if i > 0 {
+					select {
+					case <-ctx.Done():
+						return r, errors.Join(err, context.Cause(ctx))
+					case <-clock.After(o.delay(i - 1)):
+					}
+				}
+
+				r, err = f(ctx, a)
+				if err == nil || (o.Retryable != nil && !o.Retryable(err)) {
+					return r, err
+				}
+			}
+			return r, err
+		}
+	}
+}
+
+// ErrTimeout Timeout 超时返回的错误，同时作为 ctx 的 cause
+var ErrTimeout = errors.New("fun: timeout")
+
+// Timeout 超过 d 未返回则取消 ctx 并返回 ErrTimeout
+// f 应当响应 ctx，否则其 goroutine 会一直运行到 f 自行结束
+func Timeout[A, R any](clock Clock, d time.Duration) Decorator[A, R] {
+	clock = clockOr(clock)
+	return func(f Func[A, R]) Func[A, R] {
+		return func(ctx context.Context, a A) (R, error) {
+			ctx, cancel := context.WithCancelCause(ctx)
+			defer cancel(nil)
+			t := clock.AfterFunc(d, func() { cancel(ErrTimeout) })
+			defer t.Stop()
+
+			type result struct {
+				r   R
+				err error
+			}
+			ch := make(chan result, 1) // 带缓冲，超时后 f 返回也不会阻塞
+			go func() {
+				r, err := f(ctx, a)
+				ch <- result{r, err}
+			}()
+
+			select {
+			case res := <-ch:
+				return res.r, res.err
+			case <-ctx.Done():
+				var zero R
+				return zero, context.Cause(ctx)
+			}
+		}
+	}
+}
+
+// Memoize 按 key 缓存成功结果，ttl <= 0 表示永不过期，错误不缓存
+func Memoize[A any, K comparable, R any](clock Clock, key func(A) K, ttl time.Duration) Decorator[A, R] {
+	clock = clockOr(clock)
+	type entry struct {
+		r   R
+		exp time.Time
+	}
+	return func(f Func[A, R]) Func[A, R] {
+		var mu sync.Mutex
+		cache := make(map[K]entry)
+
+		return func(ctx context.Context, a A) (R, error) {
+			k := key(a)
+			now := clock.Now()
+
+			mu.Lock()
+			e, ok := cache[k]
+			if ok && (ttl <= 0 || now.Before(e.exp)) {
+				mu.Unlock()
+				return e.r, nil
+			}
+			delete(cache, k)
+			mu.Unlock()
+
+			r, err := f(ctx, a)
+			if err != nil {
+				return r, err
+			}
+
+			mu.Lock()
+			cache[k] = entry{r: r, exp: now.Add(ttl)}
+			mu.Unlock()
+			return r, nil
+		}
+	}
+}
+
+// Once 和 sync.Once 类似，但只缓存成功结果，失败后下次调用会重新执行
+func Once[T any](f func() (T, error)) func() (T, error) {
+	var (
+		mu   sync.Mutex
+		done bool
+		v    T
+	)
+	return func() (T, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if done {
+			return v, nil
+		}
+
+		r, err := f()
+		if err != nil {
+			return r, err
+		}
+		v, done = r, true
+		return v, nil
+	}
+}
+
+// ErrThrottled Throttle 丢弃的调用返回的错误
+var ErrThrottled = errors.New("fun: throttled")
+
+// Debounce 每次调用都会重新计时，安静 d 之后以最后一次调用的 ctx 和参数执行一次 f，
+// 期间的调用都等待并得到这次的结果。ctx 取消的调用立即返回 ctx.Err()，
+// 等待的调用全部取消后不再执行 f
+func Debounce[A, R any](clock Clock, d time.Duration) Decorator[A, R] {
+	clock = clockOr(clock)
+	type batch struct {
+		ctx     context.Context
+		a       A
+		waiters int
+		done    chan struct{}
+		r       R
+		err     error
+	}
+	return func(f Func[A, R]) Func[A, R] {
+		var (
+			mu  sync.Mutex
+			t   Timer
+			b   *batch
+			seq int // 已经开始执行、Stop 不掉的旧定时器按序号忽略
+		)
+		fire := func(n int) {
+			mu.Lock()
+			cur := b
+			if n != seq || cur == nil {
+				mu.Unlock()
+				return
+			}
+			b, t = nil, nil
+			mu.Unlock()
+
+			cur.r, cur.err = f(cur.ctx, cur.a)
+			close(cur.done)
+		}
+
+		return func(ctx context.Context, a A) (R, error) {
+			mu.Lock()
+			if b == nil {
+				b = &batch{done: make(chan struct{})}
+			}
+			cur := b
+			cur.ctx, cur.a = ctx, a
+			cur.waiters++
+			if t != nil {
+				t.Stop()
+			}
+			seq++
+			n := seq
+			t = clock.AfterFunc(d, func() { fire(n) })
+			mu.Unlock()
+
+			select {
+			case <-cur.done:
+				return cur.r, cur.err
+			case <-ctx.Done():
+				mu.Lock()
+				if cur.waiters--; cur.waiters == 0 && b == cur {
+					t.Stop()
+					b, t = nil, nil
+					seq++
+				}
+				mu.Unlock()
+				var zero R
+				return zero, ctx.Err()
+			}
+		}
+	}
+}
+
+// Throttle 每个 d 周期内最多执行一次 f（首次调用立即执行），其余调用返回 ErrThrottled
+// ctx 已经取消的调用返回 ctx.Err()，不占用本周期的执行机会
+func Throttle[A, R any](clock Clock, d time.Duration) Decorator[A, R] {
+	clock = clockOr(clock)
+	return func(f Func[A, R]) Func[A, R] {
+		var (
+			mu   sync.Mutex
+			last time.Time
+			ran  bool
+		)
+		return func(ctx context.Context, a A) (R, error) {
+			var zero R
+			if err := ctx.Err(); err != nil {
+				return zero, err
+			}
+			now := clock.Now()
+			mu.Lock()
+			if ran && now.Sub(last) < d {
+				mu.Unlock()
+				return zero, ErrThrottled
+			}
+			last, ran = now, true
+			mu.Unlock()
+
+			return f(ctx, a)
+		}
+	}
+}
+
+// Bind 系列对应 partial，绑定第一个参数，返回少一个参数的函数
+
+func Bind[A, R any](f func(A) R, a A) func() R {
+	return func() R { return f(a) }
+}
+
+func Bind2[A, B, R any](f func(A, B) R, a A) func(B) R {
+	return func(b B) R { return f(a, b) }
+}
+
+func Bind3[A, B, C, R any](f func(A, B, C) R, a A) func(B, C) R {
+	return func(b B, c C) R { return f(a, b, c) }
+}
+
+// BindCtx 绑定 Func 的参数，得到只依赖 ctx 的函数
+func BindCtx[A, R any](f Func[A, R], a A) func(ctx context.Context) (R, error) {
+	return func(ctx context.Context) (R, error) { return f(ctx, a) }
+}
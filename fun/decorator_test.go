@@ -0,0 +1,412 @@
+package fun
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock 假时钟，只有 Advance 才会推进时间并触发到期的定时器
+type fakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+type fakeTimer struct {
+	c       *fakeClock
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+func newFakeClock() *fakeClock {
+	c := &fakeClock{now: time.Unix(0, 0)}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.AfterFunc(d, func() { ch <- c.Now() })
+	return ch
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{c: c, at: c.now.Add(d), f: f}
+	c.waiters = append(c.waiters, t)
+	c.cond.Broadcast()
+	return t
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	for i, w := range t.c.waiters {
+		if w == t {
+			t.c.waiters = append(t.c.waiters[:i], t.c.waiters[i+1:]...)
+			t.stopped = true
+			return true
+		}
+	}
+	return false
+}
+
+// BlockUntil 等待至少 n 个定时器注册，用于和被测 goroutine 同步
+func (c *fakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+// BlockUntilDeadline 等待在 at 到期的定时器注册
+func (c *fakeClock) BlockUntilDeadline(at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for {
+		for _, w := range c.waiters {
+			if w.at.Equal(at) {
+				return
+			}
+		}
+		c.cond.Wait()
+	}
+}
+
+// Advance 推进时间，按到期顺序同步执行定时器
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due, rest []*fakeTimer
+	for _, w := range c.waiters {
+		if !w.at.After(c.now) {
+			due = append(due, w)
+		} else {
+			rest = append(rest, w)
+		}
+	}
+	c.waiters = rest
+	c.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].at.Before(due[j].at) })
+	for _, w := range due {
+		w.f()
+	}
+}
+
+func TestTimed(t *testing.T) {
+	clock := newFakeClock()
+	var got time.Duration
+	f := Chain(Lift(func(x int) (int, error) {
+		clock.Advance(3 * time.Second)
+		return x * 2, nil
+	}), Timed[int, int](clock, func(d time.Duration, err error) { got = d }))
+
+	r, err := f(context.Background(), 21)
+	if r != 42 || err != nil || got != 3*time.Second {
+		t.Fatalf("r=%d err=%v d=%v", r, err, got)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	clock := newFakeClock()
+	boom := errors.New("boom")
+	calls := 0
+	f := Chain(Lift(func(struct{}) (int, error) {
+		calls++
+		if calls < 4 {
+			return 0, boom
+		}
+		return calls, nil
+	}), Retry[struct{}, int](RetryOptions{
+		Attempts: 5,
+		Base:     time.Second,
+		Max:      3 * time.Second,
+		Jitter:   0.5,
+		Clock:    clock,
+		Rand:     func() float64 { return 0.5 }, // 固定抖动 25%
+	}))
+
+	done := make(chan int)
+	go func() {
+		r, _ := f(context.Background(), struct{}{})
+		done <- r
+	}()
+
+	// 1s 2s 3s(封顶) 各扣除 25%
+	for _, d := range []time.Duration{750 * time.Millisecond, 1500 * time.Millisecond, 2250 * time.Millisecond} {
+		clock.BlockUntil(1)
+		clock.Advance(d - time.Nanosecond)
+		select {
+		case <-done:
+			t.Fatalf("returned before %v elapsed", d)
+		default:
+		}
+		clock.Advance(time.Nanosecond)
+	}
+
+	if r := <-done; r != 4 {
+		t.Fatalf("r = %d, want 4", r)
+	}
+}
+
+// 没有 Max 时重试次数多了不能溢出
+func TestRetryDelaySaturates(t *testing.T) {
+	for _, o := range []RetryOptions{
+		{Base: time.Second},
+		{Base: time.Second, Max: math.MaxInt64},
+		{Base: time.Second, Jitter: 0.5, Rand: func() float64 { return 1 }},
+	} {
+		prev := time.Duration(0)
+		for n := 0; n < 100; n++ {
+			d := o.delay(n)
+			if d < prev {
+				t.Fatalf("%+v: delay(%d) = %v < delay(%d) = %v", o, n, d, n-1, prev)
+			}
+			prev = d
+		}
+		if want := time.Duration(math.MaxInt64 - int64(float64(math.MaxInt64)*o.Jitter)); prev != want {
+			t.Errorf("%+v: delay(99) = %v, want %v", o, prev, want)
+		}
+	}
+
+	// Jitter 大于 1 按 1 处理，等待时间不会是负数
+	o := RetryOptions{Base: time.Second, Jitter: 3, Rand: func() float64 { return 1 }}
+	if d := o.delay(2); d != 0 {
+		t.Errorf("delay = %v with Jitter 3", d)
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	clock := newFakeClock()
+	boom := errors.New("boom")
+	f := Chain(Lift(func(struct{}) (int, error) { return 0, boom }),
+		Retry[struct{}, int](RetryOptions{Attempts: 3, Base: time.Hour, Clock: clock}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := f(ctx, struct{}{})
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	cancel()
+	err := <-done
+	if !errors.Is(err, boom) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestRetryNotRetryable(t *testing.T) {
+	calls := 0
+	f := Chain(Lift(func(struct{}) (int, error) { calls++; return 0, EOF }),
+		Retry[struct{}, int](RetryOptions{Attempts: 3, Retryable: func(err error) bool { return err != EOF }}))
+	if _, err := f(context.Background(), struct{}{}); err != EOF || calls != 1 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	clock := newFakeClock()
+	f := Chain(func(ctx context.Context, _ struct{}) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}, Timeout[struct{}, int](clock, time.Second))
+
+	done := make(chan error)
+	go func() {
+		_, err := f(context.Background(), struct{}{})
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	if err := <-done; !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+}
+
+func TestTimeoutFast(t *testing.T) {
+	clock := newFakeClock()
+	f := Chain(Lift(func(x int) (int, error) { return x, nil }), Timeout[int, int](clock, time.Second))
+	if r, err := f(context.Background(), 7); r != 7 || err != nil {
+		t.Fatalf("r=%d err=%v", r, err)
+	}
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	if len(clock.waiters) != 0 {
+		t.Fatal("timer not stopped")
+	}
+}
+
+func TestMemoizeTTL(t *testing.T) {
+	clock := newFakeClock()
+	calls := 0
+	f := Chain(Lift(func(s string) (int, error) {
+		calls++
+		if s == "" {
+			return 0, EOF
+		}
+		return len(s), nil
+	}), Memoize[string, string, int](clock, func(s string) string { return s }, time.Minute))
+
+	ctx := context.Background()
+	f(ctx, "abc")
+	f(ctx, "abc")
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+
+	clock.Advance(time.Minute)
+	f(ctx, "abc")
+	if calls != 2 {
+		t.Fatalf("calls = %d after ttl, want 2", calls)
+	}
+
+	// 错误不缓存
+	f(ctx, "")
+	f(ctx, "")
+	if calls != 4 {
+		t.Fatalf("calls = %d, errors must not be cached", calls)
+	}
+}
+
+func TestOnce(t *testing.T) {
+	calls := 0
+	f := Once(func() (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, EOF
+		}
+		return calls, nil
+	})
+
+	if _, err := f(); err != EOF {
+		t.Fatalf("err = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if v, err := f(); v != 2 || err != nil {
+			t.Fatalf("v=%d err=%v", v, err)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d, want 2", calls)
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	clock := newFakeClock()
+	var args []int
+	f := Chain(Lift(func(x int) (int, error) {
+		args = append(args, x)
+		return x * 10, nil
+	}), Debounce[int, int](clock, time.Second))
+
+	type result struct {
+		r   int
+		err error
+	}
+	results := make(chan result, 3)
+	for i := 1; i <= 3; i++ {
+		go func(i int) {
+			r, err := f(context.Background(), i)
+			results <- result{r, err}
+		}(i)
+		clock.BlockUntilDeadline(clock.Now().Add(time.Second)) // 每次调用都重新计时
+		clock.Advance(500 * time.Millisecond)
+	}
+	if len(args) != 0 {
+		t.Fatalf("called with %v before quiet period", args)
+	}
+	clock.Advance(500 * time.Millisecond)
+	// 只执行一次，参数是最后一次调用的，所有调用得到同一个结果
+	for i := 0; i < 3; i++ {
+		if res := <-results; res.r != 30 || res.err != nil {
+			t.Fatalf("r=%d err=%v", res.r, res.err)
+		}
+	}
+	if len(args) != 1 || args[0] != 3 {
+		t.Fatalf("args = %v", args)
+	}
+
+	// 唯一等待的调用取消后不再执行
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := f(ctx, 4)
+		done <- err
+	}()
+	clock.BlockUntil(1)
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("err = %v", err)
+	}
+	clock.Advance(time.Hour)
+	if len(args) != 1 {
+		t.Fatalf("args = %v after cancel", args)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	clock := newFakeClock()
+	n := 0
+	f := Chain(Lift(func(struct{}) (int, error) { n++; return n, nil }),
+		Throttle[struct{}, int](clock, time.Second))
+	ctx := context.Background()
+
+	var errs []error
+	for i := 0; i < 4; i++ {
+		_, err := f(ctx, struct{}{})
+		errs = append(errs, err)
+		clock.Advance(400 * time.Millisecond)
+	}
+	// 0ms 执行，400/800 跳过，1200 执行
+	want := []error{nil, ErrThrottled, ErrThrottled, nil}
+	for i := range want {
+		if errs[i] != want[i] {
+			t.Fatalf("errs = %v, want %v", errs, want)
+		}
+	}
+
+	// 已取消的调用不占用下一个周期
+	clock.Advance(time.Second)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := f(canceled, struct{}{}); err != context.Canceled {
+		t.Fatalf("err = %v", err)
+	}
+	if r, err := f(ctx, struct{}{}); r != 3 || err != nil {
+		t.Fatalf("r=%d err=%v", r, err)
+	}
+}
+
+func TestBind(t *testing.T) {
+	add3 := func(a, b, c int) int { return a + b + c }
+	if r := Bind2(Bind3(add3, 1), 2)(3); r != 6 {
+		t.Fatalf("r = %d", r)
+	}
+	if r := Bind(func(x int) int { return x * x }, 9)(); r != 81 {
+		t.Fatalf("r = %d", r)
+	}
+
+	f := BindCtx(Lift(func(s string) (int, error) { return len(s), nil }), "hello")
+	if r, _ := f(context.Background()); r != 5 {
+		t.Fatalf("r = %d", r)
+	}
+}
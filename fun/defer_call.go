@@ -91,7 +91,7 @@ func deferError() {
 		}
 
 		// 实际上在循环内部不会执行，只有到函数结束的时候才会执行，无端延长了1000个f的生命周期，平白消耗资源
-		// 需要把for循环内部打开文件的动作封装为一个函数
+		// 需要把for循环内部打开文件的动作封装为一个函数，或者用 WithScope，见 closer.go
 		defer f.Close()
 	}
 }
@@ -13,6 +13,7 @@ import (
 
 // 必须检查所有返回错误，这会导致代码不太美观
 // 确实看起来有些丑
+// 用 Result 串联或 Try/Catch 的写法见 fun/result 的 TestErrorTest
 func errorTest() {
 	file, err := os.Open("xxx")
 	if err != nil {
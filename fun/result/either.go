@@ -0,0 +1,42 @@
+package result
+
+// Either 两种类型之一，习惯上 Left 表示失败或特殊情况，Right 表示正常的值
+// 和 Result 的区别是 Left 不必是 error，例如解析结果是数字或者原样保留的字符串
+type Either[L, R any] struct {
+	l     L
+	r     R
+	right bool
+}
+
+// Left 左值
+func Left[L, R any](v L) Either[L, R] {
+	return Either[L, R]{l: v}
+}
+
+// Right 右值
+func Right[L, R any](v R) Either[L, R] {
+	return Either[L, R]{r: v, right: true}
+}
+
+// IsRight 是否为右值
+func (e Either[L, R]) IsRight() bool {
+	return e.right
+}
+
+// Left 左值，右值时 ok 为 false
+func (e Either[L, R]) Left() (L, bool) {
+	return e.l, !e.right
+}
+
+// Right 右值，左值时 ok 为 false
+func (e Either[L, R]) Right() (R, bool) {
+	return e.r, e.right
+}
+
+// Fold 按实际的一侧调用对应的函数，合并为同一种类型
+func Fold[L, R, T any](e Either[L, R], left func(L) T, right func(R) T) T {
+	if e.right {
+		return right(e.r)
+	}
+	return left(e.l)
+}
@@ -0,0 +1,148 @@
+// Package result Option、Result、Either 以及 Try，对应 fun/error_opt.go 中 errorTest 的“确实看起来有些丑”
+//
+// Go 的方法不能有自己的类型参数，改变元素类型的 Map、AndThen 只能写成函数，
+// Result 使用 Map、AndThen，Option 使用 MapOption、AndThenOption
+package result
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Option 可能没有值，零值为 None
+type Option[T any] struct {
+	v  T
+	ok bool
+}
+
+// Some 有值
+func Some[T any](v T) Option[T] {
+	return Option[T]{v, true}
+}
+
+// None 没有值
+func None[T any]() Option[T] {
+	return Option[T]{}
+}
+
+// FromPtr nil 为 None，否则为指向的值
+func FromPtr[T any](p *T) Option[T] {
+	if p == nil {
+		return None[T]()
+	}
+	return Some(*p)
+}
+
+// Get ok-idiom 形式
+func (o Option[T]) Get() (T, bool) {
+	return o.v, o.ok
+}
+
+// IsSome 是否有值
+func (o Option[T]) IsSome() bool {
+	return o.ok
+}
+
+// Unwrap 取值，None 时 panic
+func (o Option[T]) Unwrap() T {
+	if !o.ok {
+		panic("result: Unwrap on None")
+	}
+	return o.v
+}
+
+// UnwrapOr None 时返回 d
+func (o Option[T]) UnwrapOr(d T) T {
+	if o.ok {
+		return o.v
+	}
+	return d
+}
+
+// UnwrapOrElse None 时返回 f()，f 只在需要时调用
+func (o Option[T]) UnwrapOrElse(f func() T) T {
+	if o.ok {
+		return o.v
+	}
+	return f()
+}
+
+// Or None 时使用 other
+func (o Option[T]) Or(other Option[T]) Option[T] {
+	if o.ok {
+		return o
+	}
+	return other
+}
+
+// Filter pred 不满足时变为 None
+func (o Option[T]) Filter(pred func(T) bool) Option[T] {
+	if o.ok && pred(o.v) {
+		return o
+	}
+	return None[T]()
+}
+
+// Ptr 值的副本的指针，None 为 nil
+func (o Option[T]) Ptr() *T {
+	if !o.ok {
+		return nil
+	}
+	v := o.v
+	return &v
+}
+
+// OkOr 转换为 Result，None 时为 err
+func (o Option[T]) OkOr(err error) Result[T] {
+	if o.ok {
+		return Ok(o.v)
+	}
+	return Err[T](err)
+}
+
+func (o Option[T]) String() string {
+	if !o.ok {
+		return "None"
+	}
+	return fmt.Sprintf("Some(%v)", o.v)
+}
+
+// MarshalJSON None 为 null，Some 为值本身
+// Option 是结构体，omitempty 不会省略 None，需要省略时用指针 *T
+func (o Option[T]) MarshalJSON() ([]byte, error) {
+	if !o.ok {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.v)
+}
+
+// UnmarshalJSON null 为 None，其他为 Some；字段缺失时保持零值 None
+func (o *Option[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*o = None[T]()
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*o = Some(v)
+	return nil
+}
+
+// MapOption 对值调用 f，None 保持 None
+func MapOption[T, R any](o Option[T], f func(T) R) Option[R] {
+	if !o.ok {
+		return None[R]()
+	}
+	return Some(f(o.v))
+}
+
+// AndThenOption f 本身可能没有结果，None 或 f 返回 None 时为 None
+func AndThenOption[T, R any](o Option[T], f func(T) Option[R]) Option[R] {
+	if !o.ok {
+		return None[R]()
+	}
+	return f(o.v)
+}
@@ -0,0 +1,154 @@
+package result
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Result 值或错误，零值为 Ok(零值)
+type Result[T any] struct {
+	v   T
+	err error
+}
+
+// Ok 成功
+func Ok[T any](v T) Result[T] {
+	return Result[T]{v: v}
+}
+
+// Err 失败，err 不能为 nil
+func Err[T any](err error) Result[T] {
+	if err == nil {
+		panic("result: Err with nil error")
+	}
+	return Result[T]{err: err}
+}
+
+// Of 由 (T, error) 构造，可以直接包住函数调用：Of(os.Open(name))
+func Of[T any](v T, err error) Result[T] {
+	return Result[T]{v, err}
+}
+
+// Get 转换回 (T, error)
+func (r Result[T]) Get() (T, error) {
+	return r.v, r.err
+}
+
+// IsOk 是否成功
+func (r Result[T]) IsOk() bool {
+	return r.err == nil
+}
+
+// Err 错误，成功时为 nil
+func (r Result[T]) Err() error {
+	return r.err
+}
+
+// Unwrap 取值，失败时以错误 panic
+func (r Result[T]) Unwrap() T {
+	if r.err != nil {
+		panic(r.err)
+	}
+	return r.v
+}
+
+// UnwrapOr 失败时返回 d
+func (r Result[T]) UnwrapOr(d T) T {
+	if r.err != nil {
+		return d
+	}
+	return r.v
+}
+
+// Option 丢弃错误
+func (r Result[T]) Option() Option[T] {
+	if r.err != nil {
+		return None[T]()
+	}
+	return Some(r.v)
+}
+
+// MapErr 对错误调用 f，常用于添加上下文
+func (r Result[T]) MapErr(f func(error) error) Result[T] {
+	if r.err == nil {
+		return r
+	}
+	return Err[T](f(r.err))
+}
+
+// Wrap 用 fmt.Errorf 添加上下文，保留错误链
+func (r Result[T]) Wrap(format string, args ...any) Result[T] {
+	return r.MapErr(func(err error) error {
+		return fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), err)
+	})
+}
+
+func (r Result[T]) String() string {
+	if r.err != nil {
+		return fmt.Sprintf("Err(%v)", r.err)
+	}
+	return fmt.Sprintf("Ok(%v)", r.v)
+}
+
+// Map 对成功的值调用 f，失败时直接传递错误
+func Map[T, R any](r Result[T], f func(T) R) Result[R] {
+	if r.err != nil {
+		return Err[R](r.err)
+	}
+	return Ok(f(r.v))
+}
+
+// AndThen f 本身可能失败，签名和普通的 Go 函数一致，不需要返回 Result
+func AndThen[T, R any](r Result[T], f func(T) (R, error)) Result[R] {
+	if r.err != nil {
+		return Err[R](r.err)
+	}
+	return Of(f(r.v))
+}
+
+// Collect 所有 Result 都成功时为值的列表，否则合并所有错误（errors.Join），而不是只保留第一个
+func Collect[T any](rs ...Result[T]) Result[[]T] {
+	vs := make([]T, 0, len(rs))
+	var errs []error
+	for _, r := range rs {
+		if r.err != nil {
+			errs = append(errs, r.err)
+			continue
+		}
+		vs = append(vs, r.v)
+	}
+	if len(errs) > 0 {
+		return Err[[]T](errors.Join(errs...))
+	}
+	return Ok(vs)
+}
+
+// tryError Try 引发的 panic，和其他 panic 区分开
+type tryError struct {
+	err error
+}
+
+// Try err 不为 nil 时 panic，由同一个函数中 defer 的 Catch 收回为返回值
+// 只是把 mainPanic 里的 panic/recover 用作提前返回，不能跨越没有 Catch 的函数
+func Try[T any](v T, err error) T {
+	if err != nil {
+		panic(tryError{err})
+	}
+	return v
+}
+
+// Catch 必须直接 defer 调用：defer Catch(&err)
+// 收回 Try 的错误并与 *errp 已有的错误合并，其他 panic 继续传递
+func Catch(errp *error) {
+	switch r := recover().(type) {
+	case nil:
+	case tryError:
+		if *errp == nil {
+			*errp = r.err
+		} else {
+			*errp = errors.Join(*errp, r.err)
+		}
+	default:
+		panic(r)
+	}
+}
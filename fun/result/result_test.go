@@ -0,0 +1,201 @@
+package result
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// errorTest 的三种写法：Open + ReadAll，每一步都可能出错
+
+func readAll(name string) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// readResult 用 Result 串联，错误自动向后传递
+func readResult(name string) Result[string] {
+	data := AndThen(Of(os.Open(name)), func(f *os.File) ([]byte, error) {
+		defer f.Close()
+		return io.ReadAll(f)
+	})
+	return Map(data, func(b []byte) string { return string(b) }).Wrap("read %s", filepath.Base(name))
+}
+
+// readTry 用 Try/Catch，写法接近没有错误处理的代码
+func readTry(name string) (s string, err error) {
+	defer Catch(&err)
+	f := Try(os.Open(name))
+	defer f.Close()
+	return string(Try(io.ReadAll(f))), nil
+}
+
+func tempFile(t *testing.T, name, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestErrorTest(t *testing.T) {
+	ok := tempFile(t, "a.txt", "hello")
+	missing := filepath.Join(t.TempDir(), "xxx")
+
+	if r := readResult(ok); r.UnwrapOr("") != "hello" || !r.IsOk() {
+		t.Error(r)
+	}
+	r := readResult(missing)
+	if r.IsOk() || !errors.Is(r.Err(), fs.ErrNotExist) || !strings.HasPrefix(r.Err().Error(), "read xxx: ") {
+		t.Error(r)
+	}
+	if r.UnwrapOr("default") != "default" || r.Option().IsSome() {
+		t.Error(r)
+	}
+
+	if s, err := readTry(ok); s != "hello" || err != nil {
+		t.Error(s, err)
+	}
+	if _, err := readTry(missing); !errors.Is(err, fs.ErrNotExist) {
+		t.Error(err)
+	}
+
+	// 读取目录：Open 成功，ReadAll 失败，三种写法的错误相同
+	dir := t.TempDir()
+	_, want := readAll(dir)
+	_, got := readTry(dir)
+	if want == nil || got == nil || got.Error() != want.Error() || readResult(dir).IsOk() {
+		t.Error(want, got)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	parse := func(s string) Result[int] { return Of(strconv.Atoi(s)) }
+	if r := Collect(parse("1"), parse("2")); !reflect.DeepEqual(r.UnwrapOr(nil), []int{1, 2}) {
+		t.Error(r)
+	}
+	// 所有错误都保留下来
+	r := Collect(parse("x"), parse("1"), parse("y"))
+	if r.IsOk() || !strings.Contains(r.Err().Error(), `"x"`) || !strings.Contains(r.Err().Error(), `"y"`) {
+		t.Error(r)
+	}
+	if !errors.Is(r.Err(), strconv.ErrSyntax) {
+		t.Error(r.Err())
+	}
+}
+
+func TestCatch(t *testing.T) {
+	first := errors.New("first")
+	f := func() (err error) {
+		defer Catch(&err)
+		defer func() { err = first }() // 先于 Catch 执行
+		Try(0, io.EOF)
+		return nil
+	}
+	if err := f(); !errors.Is(err, first) || !errors.Is(err, io.EOF) {
+		t.Error(err)
+	}
+
+	// 其他 panic 不会被吞掉
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Error(r)
+		}
+	}()
+	func() (err error) {
+		defer Catch(&err)
+		panic("boom")
+	}()
+}
+
+func TestOption(t *testing.T) {
+	s := Some(2)
+	n := None[int]()
+	double := func(v int) int { return v * 2 }
+	half := func(v int) Option[int] {
+		if v%2 != 0 {
+			return None[int]()
+		}
+		return Some(v / 2)
+	}
+	if MapOption(s, double).Unwrap() != 4 || MapOption(n, double).IsSome() {
+		t.Error("MapOption")
+	}
+	if AndThenOption(s, half).Unwrap() != 1 || AndThenOption(Some(3), half).IsSome() {
+		t.Error("AndThenOption")
+	}
+	if n.UnwrapOr(7) != 7 || n.UnwrapOrElse(func() int { return 8 }) != 8 || n.Or(s).Unwrap() != 2 {
+		t.Error("UnwrapOr")
+	}
+	if s.Filter(func(v int) bool { return v > 5 }).IsSome() || n.Ptr() != nil || *s.Ptr() != 2 {
+		t.Error("Filter/Ptr")
+	}
+	if FromPtr[int](nil).IsSome() || s.String() != "Some(2)" || n.String() != "None" {
+		t.Error("FromPtr/String")
+	}
+	if r := n.OkOr(io.EOF); r.Err() != io.EOF {
+		t.Error(r)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Unwrap on None did not panic")
+		}
+	}()
+	n.Unwrap()
+}
+
+func TestOptionJSON(t *testing.T) {
+	type user struct {
+		Name Option[string] `json:"name"`
+		Age  Option[int]    `json:"age"`
+	}
+	b, err := json.Marshal(user{Name: Some("a")})
+	if err != nil || string(b) != `{"name":"a","age":null}` {
+		t.Fatal(string(b), err)
+	}
+
+	var u user
+	if err := json.Unmarshal([]byte(`{"name":null,"age":0}`), &u); err != nil {
+		t.Fatal(err)
+	}
+	// null 和缺失都是 None，0 是 Some(0)
+	if u.Name.IsSome() || u.Age != Some(0) {
+		t.Error(u)
+	}
+	if err := json.Unmarshal([]byte(`{"age":"x"}`), &u); err == nil {
+		t.Error("type mismatch accepted")
+	}
+}
+
+func TestEither(t *testing.T) {
+	parse := func(s string) Either[string, int] {
+		if n, err := strconv.Atoi(s); err == nil {
+			return Right[string](n)
+		}
+		return Left[string, int](s)
+	}
+	show := func(e Either[string, int]) string {
+		return Fold(e, strconv.Quote, strconv.Itoa)
+	}
+	if show(parse("12")) != "12" || show(parse("ab")) != `"ab"` {
+		t.Error(show(parse("12")), show(parse("ab")))
+	}
+	if v, ok := parse("ab").Left(); !ok || v != "ab" {
+		t.Error(v, ok)
+	}
+	if _, ok := parse("ab").Right(); ok || !parse("1").IsRight() {
+		t.Error("Right")
+	}
+}
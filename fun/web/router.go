@@ -0,0 +1,140 @@
+// Package web 由 fun.handle 演化而来的小型路由和中间件工具
+// handle 通过闭包绑定具体的 Database，这里改为注入 Store 接口，路由支持方法匹配和路径参数
+package web
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Router 按注册顺序匹配路由，路径段 {name} 为参数，末尾 {name...} 匹配剩余全部路径
+// 路径匹配但方法不匹配时返回 405 并设置 Allow
+type Router struct {
+	routes   []route
+	mw       []Middleware
+	NotFound http.Handler
+}
+
+type route struct {
+	method string // 空表示任意方法
+	segs   []string
+	h      http.Handler
+}
+
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Use 追加全局中间件，对之后调用 ServeHTTP 的所有请求生效，包括 404/405
+func (rt *Router) Use(mw ...Middleware) {
+	rt.mw = append(rt.mw, mw...)
+}
+
+// Handle 注册路由，pattern 必须以 / 开头
+func (rt *Router) Handle(method, pattern string, h http.Handler) {
+	if !strings.HasPrefix(pattern, "/") {
+		panic("web: pattern must begin with /: " + pattern)
+	}
+	rt.routes = append(rt.routes, route{
+		method: method,
+		segs:   split(pattern),
+		h:      h,
+	})
+}
+
+func (rt *Router) HandleFunc(method, pattern string, h http.HandlerFunc) {
+	rt.Handle(method, pattern, h)
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	Chain(http.HandlerFunc(rt.dispatch), rt.mw...).ServeHTTP(w, r)
+}
+
+func (rt *Router) dispatch(w http.ResponseWriter, r *http.Request) {
+	segs := split(r.URL.Path)
+	var allow []string
+	for _, rr := range rt.routes {
+		params, ok := match(rr.segs, segs)
+		if !ok {
+			continue
+		}
+		if rr.method != "" && rr.method != r.Method {
+			allow = append(allow, rr.method)
+			continue
+		}
+		if len(params) > 0 {
+			r = r.WithContext(context.WithValue(r.Context(), paramsKey{}, params))
+		}
+		rr.h.ServeHTTP(w, r)
+		return
+	}
+
+	if len(allow) > 0 {
+		sort.Strings(allow)
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	if rt.NotFound != nil {
+		rt.NotFound.ServeHTTP(w, r)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+type paramsKey struct{}
+
+// Param 返回路径参数，不存在时为空串
+func Param(r *http.Request, name string) string {
+	m, _ := r.Context().Value(paramsKey{}).(map[string]string)
+	return m[name]
+}
+
+func split(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func match(pattern, segs []string) (map[string]string, bool) {
+	var params map[string]string
+	set := func(k, v string) {
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[k] = v
+	}
+
+	for i, p := range pattern {
+		name, isParam := strings.CutPrefix(p, "{")
+		if isParam {
+			name = strings.TrimSuffix(name, "}")
+		}
+
+		// {rest...} 吞掉剩余路径，可以为空
+		if isParam && strings.HasSuffix(name, "...") && i == len(pattern)-1 {
+			set(strings.TrimSuffix(name, "..."), strings.Join(segs[min(i, len(segs)):], "/"))
+			return params, true
+		}
+		if i >= len(segs) {
+			return nil, false
+		}
+		if isParam {
+			set(name, segs[i])
+		} else if p != segs[i] {
+			return nil, false
+		}
+	}
+	return params, len(pattern) == len(segs)
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
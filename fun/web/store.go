@@ -0,0 +1,120 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrNotFound Store 中不存在对应的 key
+var ErrNotFound = errors.New("web: not found")
+
+// Store 取代 fun.Database，handler 只依赖接口，测试和生产可以注入不同实现
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	Put(ctx context.Context, key, value string) error
+	Delete(ctx context.Context, key string) error
+}
+
+// MemStore 内存实现，并发安全
+type MemStore struct {
+	mu sync.RWMutex
+	m  map[string]string
+}
+
+func NewMemStore() *MemStore {
+	return &MemStore{m: make(map[string]string)}
+}
+
+func (s *MemStore) Get(_ context.Context, key string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.m[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+func (s *MemStore) Put(_ context.Context, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = value
+	return nil
+}
+
+func (s *MemStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.m[key]; !ok {
+		return ErrNotFound
+	}
+	delete(s.m, key)
+	return nil
+}
+
+// 对应 fun.handle，db 换成了 Store
+func get(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		v, err := s.Get(r.Context(), Param(r, "key"))
+		if err != nil {
+			writeErr(w, err)
+			return
+		}
+		io.WriteString(w, v)
+	}
+}
+
+func put(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.Put(r.Context(), Param(r, "key"), string(b)); err != nil {
+			writeErr(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func del(s Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := s.Delete(r.Context(), Param(r, "key")); err != nil {
+			writeErr(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func writeErr(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// NewServer 组装完整的服务：请求ID、日志、panic 恢复、超时，以及 /url/{key} 的增删查
+func NewServer(s Store, l *log.Logger, timeout time.Duration) http.Handler {
+	rt := NewRouter()
+	rt.Use(WithRequestID(), Logging(l), Recovery(l))
+	if timeout > 0 {
+		rt.Use(Timeout(timeout))
+	}
+
+	rt.Handle(http.MethodGet, "/url/{key}", get(s))
+	rt.Handle(http.MethodPut, "/url/{key}", put(s))
+	rt.Handle(http.MethodDelete, "/url/{key}", del(s))
+	return rt
+}
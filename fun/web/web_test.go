@@ -0,0 +1,144 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func do(t *testing.T, c *http.Client, method, url, body string, hdr ...string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(hdr); i += 2 {
+		req.Header.Set(hdr[i], hdr[i+1])
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp, string(b)
+}
+
+func TestServerCRUD(t *testing.T) {
+	var logs bytes.Buffer
+	srv := httptest.NewServer(NewServer(NewMemStore(), log.New(&logs, "", 0), time.Second))
+	defer srv.Close()
+	c := srv.Client()
+
+	resp, _ := do(t, c, http.MethodGet, srv.URL+"/url/a", "")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("get missing: %d", resp.StatusCode)
+	}
+
+	resp, _ = do(t, c, http.MethodPut, srv.URL+"/url/a", "https://go.dev")
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("put: %d", resp.StatusCode)
+	}
+
+	resp, body := do(t, c, http.MethodGet, srv.URL+"/url/a", "", RequestIDHeader, "req-1")
+	if resp.StatusCode != http.StatusOK || body != "https://go.dev" {
+		t.Fatalf("get: %d %q", resp.StatusCode, body)
+	}
+	if id := resp.Header.Get(RequestIDHeader); id != "req-1" {
+		t.Fatalf("request id = %q, want req-1", id)
+	}
+	if !strings.Contains(logs.String(), "req-1 GET /url/a 200 14") {
+		t.Fatalf("log missing request line:\n%s", logs.String())
+	}
+
+	resp, _ = do(t, c, http.MethodPost, srv.URL+"/url/a", "")
+	if resp.StatusCode != http.StatusMethodNotAllowed || resp.Header.Get("Allow") != "DELETE, GET, PUT" {
+		t.Fatalf("post: %d allow=%q", resp.StatusCode, resp.Header.Get("Allow"))
+	}
+
+	resp, _ = do(t, c, http.MethodDelete, srv.URL+"/url/a", "")
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete: %d", resp.StatusCode)
+	}
+	resp, _ = do(t, c, http.MethodGet, srv.URL+"/url/a", "")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("get deleted: %d", resp.StatusCode)
+	}
+
+	resp, _ = do(t, c, http.MethodGet, srv.URL+"/nope", "")
+	if resp.StatusCode != http.StatusNotFound || resp.Header.Get(RequestIDHeader) == "" {
+		t.Fatalf("not found: %d id=%q", resp.StatusCode, resp.Header.Get(RequestIDHeader))
+	}
+}
+
+func TestRouterParams(t *testing.T) {
+	rt := NewRouter()
+	rt.HandleFunc(http.MethodGet, "/users/{id}/posts/{post}", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, Param(r, "id")+","+Param(r, "post"))
+	})
+	rt.HandleFunc("", "/static/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "static:"+Param(r, "path"))
+	})
+	srv := httptest.NewServer(rt)
+	defer srv.Close()
+
+	for url, want := range map[string]string{
+		"/users/7/posts/42": "7,42",
+		"/static/css/a.css": "static:css/a.css",
+		"/static":           "static:",
+	} {
+		resp, body := do(t, srv.Client(), http.MethodGet, srv.URL+url, "")
+		if resp.StatusCode != http.StatusOK || body != want {
+			t.Errorf("%s: %d %q, want %q", url, resp.StatusCode, body, want)
+		}
+	}
+
+	resp, _ := do(t, srv.Client(), http.MethodGet, srv.URL+"/users/7/posts", "")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("short path: %d", resp.StatusCode)
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	var logs bytes.Buffer
+	l := log.New(&logs, "", 0)
+	rt := NewRouter()
+	rt.Use(WithRequestID(), Recovery(l))
+	rt.HandleFunc(http.MethodGet, "/panic", func(http.ResponseWriter, *http.Request) {
+		panic("p1")
+	})
+	srv := httptest.NewServer(rt)
+	defer srv.Close()
+
+	resp, _ := do(t, srv.Client(), http.MethodGet, srv.URL+"/panic", "")
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	if !strings.Contains(logs.String(), "panic: p1") {
+		t.Fatalf("log = %q", logs.String())
+	}
+}
+
+// slowStore 模拟响应缓慢的存储，尊重 ctx
+type slowStore struct{ *MemStore }
+
+func (s slowStore) Get(ctx context.Context, key string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestTimeout(t *testing.T) {
+	srv := httptest.NewServer(NewServer(slowStore{NewMemStore()}, log.New(io.Discard, "", 0), 20*time.Millisecond))
+	defer srv.Close()
+
+	resp, _ := do(t, srv.Client(), http.MethodGet, srv.URL+"/url/a", "")
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", resp.StatusCode)
+	}
+}
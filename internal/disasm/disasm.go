@@ -0,0 +1,381 @@
+// Package disasm 编译包并检查反汇编，代替注释里手工粘贴的 objdump 输出
+//
+// 粘贴的清单随工具链版本悄悄过时，这里只断言语义上的事实：
+// 调用了哪个 go.shape 实例、是否有堆分配、字典是否装入了 AX 等。
+// 地址被替换为函数内的指令序号，重定位的符号填回操作数，去掉 ABI 后缀，
+// 因此无论反汇编的是可执行文件还是包归档，得到的指令文本都一样
+//
+// 测试中使用的缓存和断言在 disasmtest 中，这里不依赖 testing
+package disasm
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Inst 一条规范化的指令
+type Inst struct {
+	File string
+	Line int
+	Op   string
+	Args []string
+}
+
+func (in Inst) String() string {
+	if len(in.Args) == 0 {
+		return in.Op
+	}
+	return in.Op + " " + strings.Join(in.Args, ", ")
+}
+
+// Pos file:line，和 objdump 的第一列相同
+func (in Inst) Pos() string {
+	return in.File + ":" + strconv.Itoa(in.Line)
+}
+
+// Target 直接调用的目标符号，不是直接调用时为空
+func (in Inst) Target() string {
+	if in.Op != "CALL" || len(in.Args) != 1 || !strings.HasSuffix(in.Args[0], "(SB)") {
+		return ""
+	}
+	return strings.TrimSuffix(in.Args[0], "(SB)")
+}
+
+// Func 一个函数的指令
+type Func struct {
+	Sym   string
+	File  string // 源文件，编译器生成的包装函数为 <autogenerated>
+	Insts []Inst
+}
+
+// Object 一个包编译后的全部函数
+type Object struct {
+	Pkg    string
+	Funcs  map[string]*Func
+	prefix string // 符号前缀，main 包为 main.
+}
+
+// Build 编译包 pkg 并反汇编，gcflags 通常为 "-l"，和注释里 go build -gcflags "-l" 的操作步骤一致
+func Build(pkg, gcflags string) (*Object, error) {
+	path, err := exec.Command("go", "list", "-f", "{{.ImportPath}}", pkg).Output()
+	if err != nil {
+		return nil, fmt.Errorf("disasm: go list %s: %v", pkg, err)
+	}
+	o := &Object{Pkg: strings.TrimSpace(string(path)), Funcs: make(map[string]*Func)}
+
+	tmp, err := os.MkdirTemp("", "disasm")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	// 非 main 包输出归档，未被引用的函数和泛型实例也都保留
+	out := filepath.Join(tmp, "out")
+	cmd := exec.Command("go", "build", "-gcflags="+gcflags, "-o", out, pkg)
+	if b, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("disasm: go build: %v\n%s", err, b)
+	}
+	fns, err := Objdump(out, "")
+	if err != nil {
+		return nil, err
+	}
+
+	o.prefix = o.Pkg + "."
+	if name, _ := exec.Command("go", "list", "-f", "{{.Name}}", pkg).Output(); strings.TrimSpace(string(name)) == "main" {
+		o.prefix = "main."
+	}
+	for _, f := range fns {
+		if strings.HasPrefix(f.Sym, o.prefix) {
+			o.Funcs[f.Sym] = f
+		}
+	}
+	return o, nil
+}
+
+// Objdump 对编译产物（可执行文件或包归档）执行 go tool objdump，re 不为空时只输出符号匹配的函数
+func Objdump(file, re string) ([]*Func, error) {
+	args := []string{"tool", "objdump"}
+	if re != "" {
+		args = append(args, "-s", re)
+	}
+	b, err := exec.Command("go", append(args, file)...).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("disasm: go tool objdump: %v\n%s", err, b)
+	}
+	return Parse(b), nil
+}
+
+var (
+	textRE  = regexp.MustCompile(`^TEXT (\S+)\(SB\)(?: (.*))?$`)
+	relocRE = regexp.MustCompile(`R_(?:CALL|PCREL):(\S+)`)
+	abiRE   = regexp.MustCompile(`<\d+>$`)
+	addrRE  = regexp.MustCompile(`^0x[0-9a-f]+$`)
+)
+
+// Parse 解析 objdump 输出，每行指令为 file:line、地址、编码、指令、重定位，以 tab 分隔
+func Parse(out []byte) []*Func {
+	var fns []*Func
+	var cur *Func
+	var addrs []string
+
+	finish := func() {
+		if cur == nil {
+			return
+		}
+		// 跳转目标换成函数内的指令序号
+		index := make(map[string]int)
+		for i, a := range addrs {
+			index[a] = i
+		}
+		for i, in := range cur.Insts {
+			if len(in.Args) == 1 && strings.HasPrefix(in.Op, "J") && addrRE.MatchString(in.Args[0]) {
+				if n, ok := index[in.Args[0]]; ok {
+					cur.Insts[i].Args[0] = "L" + strconv.Itoa(n)
+				}
+			}
+		}
+	}
+
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		if m := textRE.FindStringSubmatch(line); m != nil {
+			finish()
+			cur, addrs = &Func{Sym: strings.ReplaceAll(m[1], "·", "."), File: m[2]}, nil
+			fns = append(fns, cur)
+			continue
+		}
+		if cur == nil || !strings.HasPrefix(line, "  ") {
+			continue
+		}
+		var fields []string
+		for _, f := range strings.Split(line, "\t") {
+			if f = strings.TrimSpace(f); f != "" {
+				fields = append(fields, f)
+			}
+		}
+		if len(fields) < 4 {
+			continue
+		}
+		in := parseInst(fields[3])
+		if i := strings.LastIndex(fields[0], ":"); i > 0 {
+			in.File = fields[0][:i]
+			in.Line, _ = strconv.Atoi(fields[0][i+1:])
+		}
+		if len(fields) > 4 {
+			relocate(&in, fields[4])
+		}
+		for i, a := range in.Args {
+			in.Args[i] = normalizeSym(a)
+		}
+		cur.Insts = append(cur.Insts, in)
+		addrs = append(addrs, fields[1])
+	}
+	finish()
+	return fns
+}
+
+func parseInst(text string) Inst {
+	op, rest, _ := strings.Cut(text, " ")
+	in := Inst{Op: op}
+	depth, start := 0, 0
+	for i, r := range rest {
+		switch r {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				in.Args = append(in.Args, strings.TrimSpace(rest[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if s := strings.TrimSpace(rest[start:]); s != "" {
+		in.Args = append(in.Args, s)
+	}
+	return in
+}
+
+// relocate 包归档中的符号只出现在重定位里，填回对应的操作数
+func relocate(in *Inst, reloc string) {
+	m := relocRE.FindStringSubmatch(reloc)
+	if m == nil {
+		return
+	}
+	sym := abiRE.ReplaceAllString(m[1], "")
+	for i, a := range in.Args {
+		switch {
+		case in.Op == "CALL" && addrRE.MatchString(a):
+			in.Args[i] = sym + "(SB)"
+			return
+		case strings.HasSuffix(a, "(IP)"):
+			in.Args[i] = sym + "(SB)"
+			return
+		}
+	}
+}
+
+// normalizeSym 去掉可执行文件中的 .abi0 后缀，局部类型名中的 · 统一为 .
+func normalizeSym(a string) string {
+	if !strings.HasSuffix(a, "(SB)") {
+		return a
+	}
+	s := strings.TrimSuffix(a, "(SB)")
+	s = strings.TrimSuffix(abiRE.ReplaceAllString(s, ""), ".abi0")
+	return strings.ReplaceAll(s, "·", ".") + "(SB)"
+}
+
+// Qualify 补全包内符号的包路径：testGenImp[go.shape.int] 或 .dict.testGenImp[int]
+func (o *Object) Qualify(name string) string {
+	if strings.Contains(strings.SplitN(name, "[", 2)[0], "/") ||
+		strings.HasPrefix(name, "runtime.") || strings.Contains(name, ":") {
+		return name
+	}
+	return o.prefix + name
+}
+
+// Instances 泛型函数 name 的全部实例（符号中 [] 里的部分），按名字排序
+func (o *Object) Instances(name string) []string {
+	prefix := o.Qualify(name) + "["
+	var out []string
+	for sym := range o.Funcs {
+		if strings.HasPrefix(sym, prefix) {
+			out = append(out, strings.TrimPrefix(sym, o.Qualify(name)))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// String 规范化的指令清单，失败时输出便于对照
+func (f *Func) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "TEXT %s\n", f.Sym)
+	for i, in := range f.Insts {
+		fmt.Fprintf(&b, "  L%-3d %s:%d\t%s\n", i, filepath.Base(in.File), in.Line, in)
+	}
+	return b.String()
+}
+
+// Calls 直接调用的全部目标符号，按出现顺序
+func (f *Func) Calls() []string {
+	var out []string
+	for _, in := range f.Insts {
+		if t := in.Target(); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Lines 源码第 line 行对应的指令
+func (f *Func) Lines(line int) []Inst {
+	var out []Inst
+	for _, in := range f.Insts {
+		if in.Line == line {
+			out = append(out, in)
+		}
+	}
+	return out
+}
+
+// body 去掉栈增长分支：第一条 RET 之后的指令只在 morestack 时执行
+func (f *Func) body() []Inst {
+	for i, in := range f.Insts {
+		if in.Op == "RET" {
+			return f.Insts[:i+1]
+		}
+	}
+	return f.Insts
+}
+
+// Call 一次调用以及之前为它准备参数的指令
+type Call struct {
+	Target string
+	Setup  []Inst // 上一次调用之后到本次调用之前的指令
+}
+
+// Loaded 调用前最后一次写入 reg 的源操作数，没有写入时返回空串
+func (c Call) Loaded(reg string) string {
+	for i := len(c.Setup) - 1; i >= 0; i-- {
+		in := c.Setup[i]
+		if len(in.Args) == 2 && in.Args[1] == reg && (strings.HasPrefix(in.Op, "MOV") || strings.HasPrefix(in.Op, "LEA")) {
+			return strings.TrimSuffix(in.Args[0], "(SB)")
+		}
+	}
+	return ""
+}
+
+// CallsTo 对 target 的全部直接调用
+func (f *Func) CallsTo(target string) []Call {
+	var out []Call
+	start := 0
+	for i, in := range f.Insts {
+		if in.Op != "CALL" {
+			continue
+		}
+		if len(in.Args) == 1 && in.Args[0] == target+"(SB)" {
+			out = append(out, Call{Target: target, Setup: f.Insts[start:i]})
+		}
+		start = i + 1
+	}
+	return out
+}
+
+// ReadsBeforeWrite 函数体中是否在写入 reg 之前读取了它，用来判断寄存器参数是否被使用
+func (f *Func) ReadsBeforeWrite(reg string) bool {
+	for _, in := range f.body() {
+		for i, a := range in.Args {
+			last := i == len(in.Args)-1 && len(in.Args) > 1
+			if a == reg && last && (strings.HasPrefix(in.Op, "MOV") || strings.HasPrefix(in.Op, "LEA")) {
+				return false
+			}
+			if a == reg || strings.Contains(a, "("+reg+")") {
+				return true
+			}
+		}
+		if in.Op == "CALL" {
+			return false
+		}
+	}
+	return false
+}
+
+// 堆分配：newobject，以及新版本工具链按大小类直接调用的 mallocgc 系列
+func isAlloc(sym string) bool {
+	return sym == "runtime.newobject" || strings.HasPrefix(sym, "runtime.mallocgc") ||
+		strings.HasPrefix(sym, "runtime.makeslice")
+}
+
+// Allocs 堆分配调用所在的源码行
+func (f *Func) Allocs() []int {
+	var lines []int
+	for _, in := range f.Insts {
+		if t := in.Target(); t != "" && isAlloc(t) {
+			lines = append(lines, in.Line)
+		}
+	}
+	return lines
+}
+
+// DynamicCalls 通过寄存器的间接调用（接口方法、闭包）
+func (f *Func) DynamicCalls() []Inst {
+	var out []Inst
+	for _, in := range f.Insts {
+		if in.Op == "CALL" && len(in.Args) == 1 && !strings.HasSuffix(in.Args[0], "(SB)") {
+			out = append(out, in)
+		}
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package disasm
+
+import (
+	"strings"
+	"testing"
+)
+
+// 同一段代码在包归档和可执行文件中的反汇编，规范化之后应该相同
+const archive = "TEXT yuhen/data.impGen(SB) /root/module/data/generic.go\n" +
+	"  generic.go:295\t0xeacc2\t\t55\t\t\tPUSHQ BP\t\t\t\n" +
+	"  generic.go:295\t0xeacca\t\t488d0500000000\t\tLEAQ 0(IP), AX\t\t\t[3:7]R_PCREL:yuhen/data..dict.testGenImp[yuhen/data.X·2]\t\n" +
+	"  generic.go:295\t0xeacd8\t\te800000000\t\tCALL 0xeacdd\t\t\t[1:5]R_CALL:yuhen/data.testGenImp[go.shape.int]\t\n" +
+	"  generic.go:296\t0xeacdd\t\te800000000\t\tCALL 0xeace2\t\t\t[1:5]R_CALL:runtime.newobject<1>\t\n" +
+	"  generic.go:294\t0xeace2\t\teb00\t\t\tJMP 0xeacc2\t\t\n"
+
+const executable = "TEXT yuhen/data.impGen(SB) /root/module/data/generic.go\n" +
+	"  generic.go:295\t0x4f5cf0\t\t55\t\t\tPUSHQ BP\t\t\t\n" +
+	"  generic.go:295\t0x4f5cf4\t\t488d05edf21d00\t\tLEAQ yuhen/data..dict.testGenImp[yuhen/data.X·2](SB), AX\t\n" +
+	"  generic.go:295\t0x4f5d00\t\te8fb640000\t\tCALL yuhen/data.testGenImp[go.shape.int](SB)\t\n" +
+	"  generic.go:296\t0x4f5d05\t\te8fb640000\t\tCALL runtime.newobject(SB)\t\n" +
+	"  generic.go:294\t0x4f5d0a\t\teb00\t\t\tJMP 0x4f5cf0\t\t\n"
+
+func TestNormalize(t *testing.T) {
+	a, e := Parse([]byte(archive)), Parse([]byte(executable))
+	if len(a) != 1 || len(e) != 1 {
+		t.Fatalf("funcs: %d %d", len(a), len(e))
+	}
+	if a[0].String() != e[0].String() {
+		t.Errorf("archive:\n%s\nexecutable:\n%s", a[0], e[0])
+	}
+
+	want := []string{
+		"PUSHQ BP",
+		"LEAQ yuhen/data..dict.testGenImp[yuhen/data.X.2](SB), AX",
+		"CALL yuhen/data.testGenImp[go.shape.int](SB)",
+		"CALL runtime.newobject(SB)",
+		"JMP L0",
+	}
+	var got []string
+	for _, in := range a[0].Insts {
+		got = append(got, in.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	f := a[0]
+	if in := f.Insts[2]; f.File != "/root/module/data/generic.go" || in.Pos() != "generic.go:295" || in.Target() != "yuhen/data.testGenImp[go.shape.int]" {
+		t.Errorf("file %s, pos %s, target %s", f.File, in.Pos(), in.Target())
+	}
+	calls := f.CallsTo("yuhen/data.testGenImp[go.shape.int]")
+	if len(calls) != 1 || calls[0].Loaded("AX") != "yuhen/data..dict.testGenImp[yuhen/data.X.2]" {
+		t.Errorf("dictionary not found in AX: %+v", calls)
+	}
+	if lines := f.Allocs(); len(lines) != 1 || lines[0] != 296 {
+		t.Errorf("allocs = %v", lines)
+	}
+}
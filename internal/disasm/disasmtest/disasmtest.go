@@ -0,0 +1,126 @@
+// Package disasmtest 在测试中使用 disasm：按参数缓存编译结果，提供断言，short 模式下跳过
+package disasmtest
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"yuhen/internal/disasm"
+)
+
+// Object 带断言的 disasm.Object
+type Object struct {
+	*disasm.Object
+}
+
+type buildKey struct{ pkg, gcflags string }
+
+var (
+	mu    sync.Mutex
+	cache = make(map[buildKey]*Object)
+)
+
+// Build 编译包 pkg 并反汇编，同一进程内相同参数只编译一次
+func Build(tb testing.TB, pkg, gcflags string) *Object {
+	tb.Helper()
+	if testing.Short() {
+		tb.Skip("disasm: skipped in short mode")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	key := buildKey{pkg, gcflags}
+	if o := cache[key]; o != nil {
+		return o
+	}
+
+	do, err := disasm.Build(pkg, gcflags)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	o := &Object{do}
+	cache[key] = o
+	return o
+}
+
+// Func 返回包内函数 name（不带包路径），不存在时测试失败
+func (o *Object) Func(tb testing.TB, name string) *disasm.Func {
+	tb.Helper()
+	if f := o.Funcs[o.Qualify(name)]; f != nil {
+		return f
+	}
+	tb.Fatalf("disasm: no function %s in %s", o.Qualify(name), o.Pkg)
+	return nil
+}
+
+// AssertCalls 断言 f 直接调用了每个 target（包内符号可省略包路径）
+func (o *Object) AssertCalls(tb testing.TB, f *disasm.Func, targets ...string) {
+	tb.Helper()
+	for _, t := range targets {
+		if len(f.CallsTo(o.Qualify(t))) == 0 {
+			tb.Errorf("%s does not call %s\n%s", f.Sym, o.Qualify(t), f)
+		}
+	}
+}
+
+// AssertNotCalls 断言 f 没有调用任何 target
+func (o *Object) AssertNotCalls(tb testing.TB, f *disasm.Func, targets ...string) {
+	tb.Helper()
+	for _, t := range targets {
+		if len(f.CallsTo(o.Qualify(t))) > 0 {
+			tb.Errorf("%s calls %s\n%s", f.Sym, o.Qualify(t), f)
+		}
+	}
+}
+
+// AssertDictCall 断言 f 调用 shape 实例 target 前把字典 dict 装入了 AX
+func (o *Object) AssertDictCall(tb testing.TB, f *disasm.Func, dict, target string) {
+	tb.Helper()
+	dict, target = o.Qualify(dict), o.Qualify(target)
+	var got []string
+	for _, c := range f.CallsTo(target) {
+		d := c.Loaded("AX")
+		if d == dict {
+			return
+		}
+		got = append(got, d)
+	}
+	tb.Errorf("%s: no call to %s with %s in AX (AX holds %q)\n%s", f.Sym, target, dict, got, f)
+}
+
+// AssertAllocs 断言 f 在源码第 line 行有堆分配
+func (o *Object) AssertAllocs(tb testing.TB, f *disasm.Func, line int) {
+	tb.Helper()
+	for _, l := range f.Allocs() {
+		if l == line {
+			return
+		}
+	}
+	tb.Errorf("%s: no heap allocation at line %d (allocations at %v)\n%s", f.Sym, line, f.Allocs(), f)
+}
+
+// AssertNoAllocs 断言 f 没有堆分配
+func (o *Object) AssertNoAllocs(tb testing.TB, f *disasm.Func) {
+	tb.Helper()
+	if lines := f.Allocs(); len(lines) > 0 {
+		tb.Errorf("%s: heap allocations at lines %v\n%s", f.Sym, lines, f)
+	}
+}
+
+// LineOf 源文件中第一个包含 text 的行号，断言中用它定位源码，避免写死行号
+func LineOf(tb testing.TB, file, text string) int {
+	tb.Helper()
+	b, err := os.ReadFile(file)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for i, line := range strings.Split(string(b), "\n") {
+		if strings.Contains(line, text) {
+			return i + 1
+		}
+	}
+	tb.Fatalf("disasm: %q not found in %s", text, file)
+	return 0
+}
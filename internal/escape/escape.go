@@ -0,0 +1,235 @@
+// Package escape 解析编译器的逃逸分析输出（-gcflags=-m=2），按文件、行和函数整理结论（测试断言在 escapetest 中），
+// 代替注释中 go build -gcflags "-m" ./data 2>&1|grep heap 这样的手工步骤
+//
+// -m=2 对每个结论先输出一行带冒号的标题和若干缩进的 flow 说明，最后输出一行摘要，
+// 这里只保留摘要行：
+//
+//	moved to heap: n
+//	&N5{} escapes to heap
+//	n does not escape
+//	leaking param: n
+//
+// 没有改用 -json=0,<dir>：它记录的是得出结论的过程而不是结论本身，
+//   - 表达式逃逸的结论记录（code 为 escape）消息为空，表达式的文本只在解释路径的记录中出现
+//   - moved to heap 没有单独的记录，只能从同一位置有没有上面的空记录推断；
+//     内联后变量和表达式的位置都是调用处，推断会出错（fun/anonymous_fun.go 的 i）
+//   - 不输出 does not escape，NoEscape 断言无从判断
+//   - go/defer 生成的包装函数也会记录，-m 不输出这些
+//
+// 因此仍然解析 -m=2 的文本，两种输出的行号和列号相同
+package escape
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"yuhen/internal/source"
+)
+
+// Kind 逃逸分析结论的种类
+type Kind int
+
+const (
+	MovedToHeap Kind = iota // 局部变量分配在堆上
+	Escapes                 // 表达式的值逃逸到堆上
+	NoEscape                // 不逃逸
+	Leak                    // 参数泄漏（被保存到堆上或返回）
+)
+
+var kindNames = [...]string{"moved to heap", "escapes to heap", "does not escape", "leaking param"}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Diag 一条结论
+type Diag struct {
+	File      string // 相对模块根目录的路径，使用 /
+	Line, Col int
+	Kind      Kind
+	Expr      string // 变量名或表达式
+	Func      string // 所在的顶层函数，方法为 T.m 或 (*T).m
+}
+
+func (d Diag) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", d.File, d.Line, d.Col, d.Kind, d.Expr)
+}
+
+var (
+	lineRE  = regexp.MustCompile(`^(.+?\.go):(\d+):(\d+): (.*)$`)
+	moveRE  = regexp.MustCompile(`^moved to heap: (.+)$`)
+	escRE   = regexp.MustCompile(`^(.+) escapes to heap$`)
+	noEscRE = regexp.MustCompile(`^(.+) does not escape$`)
+	leakRE  = regexp.MustCompile(`^leaking param(?: content)?: (\S+)`)
+)
+
+// Parse 解析编译器输出，忽略内联等其他信息和 -m=2 的说明行
+func Parse(r io.Reader) ([]Diag, error) {
+	var diags []Diag
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		m := lineRE.FindStringSubmatch(sc.Text())
+		if m == nil {
+			continue
+		}
+		msg := m[4]
+		if strings.HasPrefix(msg, " ") || strings.HasSuffix(msg, ":") {
+			continue
+		}
+		d := Diag{File: path.Clean(filepath.ToSlash(m[1]))}
+		d.Line, _ = strconv.Atoi(m[2])
+		d.Col, _ = strconv.Atoi(m[3])
+		switch {
+		case moveRE.MatchString(msg):
+			d.Kind, d.Expr = MovedToHeap, moveRE.FindStringSubmatch(msg)[1]
+		case escRE.MatchString(msg):
+			d.Kind, d.Expr = Escapes, escRE.FindStringSubmatch(msg)[1]
+		case noEscRE.MatchString(msg):
+			d.Kind, d.Expr = NoEscape, noEscRE.FindStringSubmatch(msg)[1]
+		case leakRE.MatchString(msg):
+			d.Kind, d.Expr = Leak, leakRE.FindStringSubmatch(msg)[1]
+		default:
+			continue
+		}
+		diags = append(diags, d)
+	}
+	return diags, sc.Err()
+}
+
+// Report 一次编译得到的全部结论
+type Report struct {
+	Root  string // 模块根目录
+	Diags []Diag
+}
+
+// Run 在模块根目录 root 下编译 patterns 指定的包，收集逃逸分析结论
+// 只给这些包加 -m=2，依赖不受影响；结果由构建缓存重放，重复运行不会重新编译
+func Run(root, gcflags string, patterns ...string) (*Report, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	flags := strings.TrimSpace(gcflags + " -m=2")
+	var args []string
+	for _, p := range patterns {
+		args = append(args, "-gcflags="+p+"="+flags)
+	}
+	// 测试文件不参与，main 包的可执行文件丢弃
+	cmd := exec.Command("go", append(append([]string{"build", "-o", os.DevNull}, args...), patterns...)...)
+	cmd.Dir = root
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("escape: go build: %v\n%s", err, out)
+	}
+
+	diags, err := Parse(strings.NewReader(string(out)))
+	if err != nil {
+		return nil, err
+	}
+	r := &Report{Root: root, Diags: diags}
+	r.attribute()
+	r.sort()
+	return r, nil
+}
+
+// attribute 按行号为每条结论找到所在的函数
+func (r *Report) attribute() {
+	x := &source.Index{Root: r.Root}
+	for i, d := range r.Diags {
+		r.Diags[i].Func = x.Enclosing(d.File, d.Line)
+	}
+}
+
+func (r *Report) sort() {
+	sort.SliceStable(r.Diags, func(i, j int) bool {
+		a, b := r.Diags[i], r.Diags[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+}
+
+// Func 文件 file 中函数 fn 的结论
+func (r *Report) Func(file, fn string) []Diag {
+	var out []Diag
+	for _, d := range r.Diags {
+		if d.File == file && d.Func == fn {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Has 函数中是否有 kind 类结论涉及 expr
+func (r *Report) Has(file, fn string, kind Kind, expr string) bool {
+	for _, d := range r.Func(file, fn) {
+		if d.Kind == kind && d.Expr == expr {
+			return true
+		}
+	}
+	return false
+}
+
+// Heap 函数中分配到堆上的变量和逃逸的表达式
+func (r *Report) Heap(file, fn string) []Diag {
+	var out []Diag
+	for _, d := range r.Func(file, fn) {
+		if d.Kind == MovedToHeap || d.Kind == Escapes {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, d := range r.Diags {
+		fmt.Fprintf(&b, "%s\t%s\n", d, d.Func)
+	}
+	return b.String()
+}
+
+// diffKey 比较两个版本时不考虑行列号，代码移动不产生差异
+type diffKey struct {
+	file, fn string
+	kind     Kind
+	expr     string
+}
+
+// Diff 比较两次报告，返回只在 a 中和只在 b 中的结论
+// 同一函数中相同的结论按出现次数抵消
+func Diff(a, b *Report) (removed, added []Diag) {
+	count := make(map[diffKey]int)
+	for _, d := range b.Diags {
+		count[diffKey{d.File, d.Func, d.Kind, d.Expr}]++
+	}
+	for _, d := range a.Diags {
+		k := diffKey{d.File, d.Func, d.Kind, d.Expr}
+		if count[k] > 0 {
+			count[k]--
+			continue
+		}
+		removed = append(removed, d)
+	}
+	for _, d := range b.Diags {
+		k := diffKey{d.File, d.Func, d.Kind, d.Expr}
+		if count[k] > 0 {
+			count[k]--
+			added = append(added, d)
+		}
+	}
+	return removed, added
+}
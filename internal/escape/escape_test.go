@@ -0,0 +1,60 @@
+package escape
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// -m=2 的输出片段，包含标题行、缩进的 flow 说明、内联信息和根目录的包
+const output = `# yuhen/data
+data/interface.go:371:6: can inline testNew1 with cost 62 as: func(Ner) { n.B(9) }
+data/interface.go:65:8: Z3{} escapes to heap in z3Test:
+data/interface.go:65:8:   flow: {heap} = &{storage for Z3{}}:
+data/interface.go:65:8:     from Z3{} (spill) at data/interface.go:65:8
+data/interface.go:65:8: Z3{} escapes to heap
+data/interface.go:366:14: parameter n leaks to {heap} for testNew with derefs=0:
+data/interface.go:366:14:   flow: {heap} = n:
+data/interface.go:366:14: leaking param: n
+data/interface.go:376:6: n escapes to heap in main:
+data/interface.go:376:6: moved to heap: n
+data/interface.go:103:15: n does not escape
+data/map.go:20:10: leaking param content: m
+# yuhen
+./main.go:12:6: moved to heap: x
+<autogenerated>:1: .this does not escape
+`
+
+func TestParse(t *testing.T) {
+	diags, err := Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Diag{
+		{File: "data/interface.go", Line: 65, Col: 8, Kind: Escapes, Expr: "Z3{}"},
+		{File: "data/interface.go", Line: 366, Col: 14, Kind: Leak, Expr: "n"},
+		{File: "data/interface.go", Line: 376, Col: 6, Kind: MovedToHeap, Expr: "n"},
+		{File: "data/interface.go", Line: 103, Col: 15, Kind: NoEscape, Expr: "n"},
+		{File: "data/map.go", Line: 20, Col: 10, Kind: Leak, Expr: "m"},
+		{File: "main.go", Line: 12, Col: 6, Kind: MovedToHeap, Expr: "x"}, // 根目录的包带 ./ 前缀
+	}
+	if !reflect.DeepEqual(diags, want) {
+		t.Errorf("got:\n%v\nwant:\n%v", diags, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := &Report{Diags: []Diag{
+		{File: "a.go", Line: 10, Kind: Escapes, Expr: "x", Func: "f"},
+		{File: "a.go", Line: 12, Kind: NoEscape, Expr: "y", Func: "f"},
+	}}
+	// 插入代码后行号变化，不算差异
+	b := &Report{Diags: []Diag{
+		{File: "a.go", Line: 20, Kind: Escapes, Expr: "x", Func: "f"},
+		{File: "a.go", Line: 22, Kind: MovedToHeap, Expr: "y", Func: "f"},
+	}}
+	removed, added := Diff(a, b)
+	if len(removed) != 1 || removed[0].Kind != NoEscape || len(added) != 1 || added[0].Kind != MovedToHeap {
+		t.Errorf("removed %v, added %v", removed, added)
+	}
+}
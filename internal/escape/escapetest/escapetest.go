@@ -0,0 +1,115 @@
+// Package escapetest 在测试中使用 escape：按包和 gcflags 缓存编译结果，提供断言，short 模式下跳过
+package escapetest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"yuhen/internal/escape"
+	"yuhen/internal/source"
+)
+
+// Report 带断言的 escape.Report
+type Report struct {
+	*escape.Report
+}
+
+var (
+	mu    sync.Mutex
+	cache = make(map[loadKey]*Report)
+)
+
+type loadKey struct {
+	root, pkg, gcflags string
+}
+
+// Load 编译文件 file（相对模块根目录）所在的包，供测试使用，结果按包和 gcflags 缓存
+// 测试的工作目录是包目录，file 按模块根目录解析，和编译器输出的路径一致
+func Load(tb testing.TB, file, gcflags string) *Report {
+	tb.Helper()
+	if testing.Short() {
+		tb.Skip("escape: skipped in short mode")
+	}
+
+	root, err := source.ModuleRoot(".")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	pkg := "./" + filepath.ToSlash(filepath.Dir(file))
+
+	mu.Lock()
+	defer mu.Unlock()
+	key := loadKey{root, pkg, gcflags}
+	if r := cache[key]; r != nil {
+		return r
+	}
+	er, err := escape.Run(root, gcflags, pkg)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	r := &Report{er}
+	cache[key] = r
+	return r
+}
+
+// AssertNoEscape 函数 fn 中没有变量分配到堆上，也没有值逃逸（参数泄漏不算）
+func AssertNoEscape(tb testing.TB, file, fn string) {
+	tb.Helper()
+	Load(tb, file, "").AssertNoEscape(tb, file, fn)
+}
+
+// AssertEscapes 函数 fn 中表达式 expr 逃逸到堆上
+func AssertEscapes(tb testing.TB, file, fn, expr string) {
+	tb.Helper()
+	Load(tb, file, "").AssertHas(tb, file, fn, escape.Escapes, expr)
+}
+
+// AssertMovedToHeap 函数 fn 中变量 name 分配在堆上
+func AssertMovedToHeap(tb testing.TB, file, fn, name string) {
+	tb.Helper()
+	Load(tb, file, "").AssertHas(tb, file, fn, escape.MovedToHeap, name)
+}
+
+// AssertNoEscape 同包级函数，用于指定了 gcflags 的报告
+func (r *Report) AssertNoEscape(tb testing.TB, file, fn string) {
+	tb.Helper()
+	r.assertFunc(tb, file, fn)
+	if h := r.Heap(file, fn); len(h) > 0 {
+		tb.Errorf("%s: %s escapes:\n%s", file, fn, lines(h))
+	}
+}
+
+// AssertHas 函数 fn 中有 kind 类结论涉及 expr
+func (r *Report) AssertHas(tb testing.TB, file, fn string, kind escape.Kind, expr string) {
+	tb.Helper()
+	r.assertFunc(tb, file, fn)
+	if !r.Has(file, fn, kind, expr) {
+		tb.Errorf("%s: %s: no %q for %s, got:\n%s", file, fn, kind, expr, lines(r.Func(file, fn)))
+	}
+}
+
+// assertFunc 函数必须存在于源文件中，名字写错时不至于让断言空过
+func (r *Report) assertFunc(tb testing.TB, file, fn string) {
+	tb.Helper()
+	fs, err := source.Funcs(filepath.Join(r.Root, file))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	for _, f := range fs {
+		if f.Name == fn {
+			return
+		}
+	}
+	tb.Fatalf("%s: no function %s", file, fn)
+}
+
+func lines(ds []escape.Diag) string {
+	var b strings.Builder
+	for _, d := range ds {
+		fmt.Fprintf(&b, "\t%s\n", d)
+	}
+	return b.String()
+}
@@ -0,0 +1,298 @@
+// Package inline 汇总编译器的内联决策和剩余的边界检查
+//
+// 用 -gcflags=-m=2 -d=ssa/check_bce/debug=1 编译，解析其中三类信息：
+//
+//	data/slice.go:346:6: can inline (*Queue).Put with cost 7 as: ...
+//	data/slice.go:395:6: cannot inline (*LoopQueue).Put: unhandled op DEFER
+//	data/slice.go:397:8: inlining call to sync.(*Mutex).Lock
+//	data/slice.go:406:8: Found IsInBounds
+//
+// 内联的原因和开销只有 -m=2 才输出；逃逸分析的信息由 internal/escape 处理，这里忽略
+package inline
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"yuhen/internal/source"
+)
+
+// Decision 函数能否被内联
+type Decision struct {
+	Name      string // 编译器输出的名字，包含闭包（f.func1）和泛型 shape（f[go.shape.int]）
+	Inlinable bool
+	Cost      int    // 内联开销，超出预算时为实际开销，未知为 -1
+	Reason    string // 不能内联的原因
+}
+
+func (d Decision) String() string {
+	if d.Inlinable {
+		return fmt.Sprintf("can inline %s (cost %d)", d.Name, d.Cost)
+	}
+	return fmt.Sprintf("cannot inline %s: %s", d.Name, d.Reason)
+}
+
+// Item 一条位置信息
+type Item struct {
+	File      string // 相对模块根目录的路径
+	Line, Col int
+	Func      string // 所在的顶层函数
+	// 以下三者之一
+	Decision *Decision
+	Inlined  string // 在此处内联的被调用函数
+	Bounds   string // 剩余的边界检查：IsInBounds（索引）或 IsSliceInBounds（切片）
+}
+
+// Fact 不含位置的描述，用于比较两个版本
+func (it Item) Fact() string {
+	switch {
+	case it.Decision != nil:
+		return it.Decision.String()
+	case it.Inlined != "":
+		return "inlines " + it.Inlined
+	}
+	return "bounds check " + it.Bounds
+}
+
+func (it Item) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", it.File, it.Line, it.Col, it.Fact())
+}
+
+var (
+	lineRE   = regexp.MustCompile(`^(.+?\.go):(\d+):(\d+): (.*)$`)
+	canRE    = regexp.MustCompile(`^can inline (\S+) with cost (\d+)`)
+	cannotRE = regexp.MustCompile(`^cannot inline (\S+): (.*)$`)
+	costRE   = regexp.MustCompile(`cost (\d+) exceeds budget`)
+	callRE   = regexp.MustCompile(`^inlining call to (\S+)`)
+	boundsRE = regexp.MustCompile(`^Found (Is(?:Slice)?InBounds)$`)
+)
+
+// Parse 解析编译器输出，Func 字段留空
+func Parse(r io.Reader) ([]Item, error) {
+	var items []Item
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		m := lineRE.FindStringSubmatch(sc.Text())
+		if m == nil {
+			continue
+		}
+		msg := m[4]
+		it := Item{File: m[1]}
+		it.Line, _ = strconv.Atoi(m[2])
+		it.Col, _ = strconv.Atoi(m[3])
+		switch {
+		case canRE.MatchString(msg):
+			c := canRE.FindStringSubmatch(msg)
+			cost, _ := strconv.Atoi(c[2])
+			it.Decision = &Decision{Name: c[1], Inlinable: true, Cost: cost}
+		case cannotRE.MatchString(msg):
+			c := cannotRE.FindStringSubmatch(msg)
+			d := &Decision{Name: c[1], Reason: c[2], Cost: -1}
+			if n := costRE.FindStringSubmatch(d.Reason); n != nil {
+				d.Cost, _ = strconv.Atoi(n[1])
+			}
+			it.Decision = d
+		case callRE.MatchString(msg):
+			it.Inlined = callRE.FindStringSubmatch(msg)[1]
+		case boundsRE.MatchString(msg):
+			it.Bounds = boundsRE.FindStringSubmatch(msg)[1]
+		default:
+			continue
+		}
+		// 同一位置的信息可能输出多次（如 defer 展开的两条路径）
+		if k := it.String(); !seen[k] {
+			seen[k] = true
+			items = append(items, it)
+		}
+	}
+	return items, sc.Err()
+}
+
+// Report 一次编译的结果
+type Report struct {
+	Root  string
+	Items []Item
+}
+
+// Run 在模块根目录 root 下编译 patterns 指定的包，gcflags 为额外的编译参数
+func Run(root, gcflags string, patterns ...string) (*Report, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	flags := strings.TrimSpace(gcflags + " -m=2 -d=ssa/check_bce/debug=1")
+	var args []string
+	for _, p := range patterns {
+		args = append(args, "-gcflags="+p+"="+flags)
+	}
+	cmd := exec.Command("go", append(append([]string{"build", "-o", os.DevNull}, args...), patterns...)...)
+	cmd.Dir = root
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("inline: go build: %v\n%s", err, out)
+	}
+
+	items, err := Parse(strings.NewReader(string(out)))
+	if err != nil {
+		return nil, err
+	}
+	x := &source.Index{Root: root}
+	for i, it := range items {
+		items[i].Func = x.Enclosing(it.File, it.Line)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+	return &Report{Root: root, Items: items}, nil
+}
+
+// Decision 按编译器输出的名字查找内联决策
+func (r *Report) Decision(name string) *Decision {
+	for _, it := range r.Items {
+		if it.Decision != nil && it.Decision.Name == name {
+			return it.Decision
+		}
+	}
+	return nil
+}
+
+// Bounds 顶层函数 fn 中剩余的边界检查
+func (r *Report) Bounds(fn string) []Item {
+	var out []Item
+	for _, it := range r.Items {
+		if it.Bounds != "" && it.Func == fn {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// String 按目录和函数分组输出
+//
+//	data
+//	  (*LoopQueue).Put
+//	    slice.go:395:6: cannot inline (*LoopQueue).Put: unhandled op DEFER
+//	    slice.go:406:8: bounds check IsInBounds
+func (r *Report) String() string {
+	var b strings.Builder
+	dir, fn := "", "\x00"
+	for _, it := range r.Items {
+		d, file := splitDir(it.File)
+		if d != dir {
+			dir, fn = d, "\x00"
+			fmt.Fprintf(&b, "%s\n", dir)
+		}
+		if it.Func != fn {
+			fn = it.Func
+			name := fn
+			if name == "" {
+				name = "(package)"
+			}
+			fmt.Fprintf(&b, "  %s\n", name)
+		}
+		fmt.Fprintf(&b, "    %s:%d:%d: %s\n", file, it.Line, it.Col, it.Fact())
+	}
+	return b.String()
+}
+
+func splitDir(file string) (dir, name string) {
+	i := strings.LastIndex(file, "/")
+	if i < 0 {
+		return ".", file
+	}
+	return file[:i], file[i+1:]
+}
+
+// Change 一个函数在两个版本间的变化
+type Change struct {
+	File, Func string
+	Removed    []string // 只在旧版本中的描述
+	Added      []string // 只在新版本中的描述
+}
+
+func (c Change) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", c.File, c.Func)
+	for _, s := range c.Removed {
+		fmt.Fprintf(&b, "  - %s\n", s)
+	}
+	for _, s := range c.Added {
+		fmt.Fprintf(&b, "  + %s\n", s)
+	}
+	return b.String()
+}
+
+type funcKey struct {
+	file, fn string
+}
+
+// Diff 按文件和函数比较两次报告的描述，忽略行号变化
+// 开销变化表现为一对 -/+，边界检查和内联调用按次数比较
+func Diff(a, b *Report) []Change {
+	facts := func(r *Report) map[funcKey][]string {
+		m := make(map[funcKey][]string)
+		for _, it := range r.Items {
+			k := funcKey{it.File, it.Func}
+			m[k] = append(m[k], it.Fact())
+		}
+		return m
+	}
+	fa, fb := facts(a), facts(b)
+
+	var keys []funcKey
+	for k := range fa {
+		keys = append(keys, k)
+	}
+	for k := range fb {
+		if _, ok := fa[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].file != keys[j].file {
+			return keys[i].file < keys[j].file
+		}
+		return keys[i].fn < keys[j].fn
+	})
+
+	var changes []Change
+	for _, k := range keys {
+		removed, added := subtract(fa[k], fb[k]), subtract(fb[k], fa[k])
+		if len(removed) > 0 || len(added) > 0 {
+			changes = append(changes, Change{File: k.file, Func: k.fn, Removed: removed, Added: added})
+		}
+	}
+	return changes
+}
+
+// subtract 多重集合 a - b
+func subtract(a, b []string) []string {
+	n := make(map[string]int)
+	for _, s := range b {
+		n[s]++
+	}
+	var out []string
+	for _, s := range a {
+		if n[s] > 0 {
+			n[s]--
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package inline
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const output = `# yuhen/data
+data/slice.go:346:6: can inline (*Queue).Put with cost 7 as: method(*Queue) func(int) { *q = append(*q, v) }
+data/slice.go:395:6: cannot inline (*LoopQueue).Put: unhandled op DEFER
+data/slice.go:397:8: inlining call to sync.(*Mutex).Lock
+data/slice.go:397:8: inlining call to sync.(*Mutex).Lock
+data/slice.go:406:8: Found IsInBounds
+data/array.go:31:6: cannot inline Array: function too complex: cost 423 exceeds budget 80
+data/array.go:36:13: ... argument does not escape
+data/slice.go:470:18: Found IsSliceInBounds
+`
+
+func TestParse(t *testing.T) {
+	items, err := Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, it := range items {
+		got = append(got, it.String())
+	}
+	want := []string{
+		"data/slice.go:346:6: can inline (*Queue).Put (cost 7)",
+		"data/slice.go:395:6: cannot inline (*LoopQueue).Put: unhandled op DEFER",
+		"data/slice.go:397:8: inlines sync.(*Mutex).Lock",
+		"data/slice.go:406:8: bounds check IsInBounds",
+		"data/array.go:31:6: cannot inline Array: function too complex: cost 423 exceeds budget 80",
+		"data/slice.go:470:18: bounds check IsSliceInBounds",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if c := items[4].Decision.Cost; c != 423 {
+		t.Errorf("cost %d, want 423", c)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	put := func(line int, cost int, bounds int) *Report {
+		r := &Report{Items: []Item{{File: "a.go", Line: line, Func: "Put", Decision: &Decision{Name: "Put", Inlinable: true, Cost: cost}}}}
+		for i := 0; i < bounds; i++ {
+			r.Items = append(r.Items, Item{File: "a.go", Line: line + 1 + i, Func: "Put", Bounds: "IsInBounds"})
+		}
+		return r
+	}
+
+	// 只有行号变化
+	if c := Diff(put(10, 7, 1), put(20, 7, 1)); len(c) != 0 {
+		t.Errorf("unexpected changes: %v", c)
+	}
+
+	c := Diff(put(10, 7, 1), put(10, 9, 2))
+	want := []Change{{
+		File: "a.go", Func: "Put",
+		Removed: []string{"can inline Put (cost 7)"},
+		Added:   []string{"can inline Put (cost 9)", "bounds check IsInBounds"},
+	}}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("got %v, want %v", c, want)
+	}
+}
@@ -0,0 +1,84 @@
+// Package inlinetest 在测试中使用 inline：按包缓存编译结果，提供断言，short 模式下跳过
+package inlinetest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"yuhen/internal/inline"
+	"yuhen/internal/source"
+)
+
+// Report 带断言的 inline.Report
+type Report struct {
+	*inline.Report
+}
+
+var (
+	mu    sync.Mutex
+	cache = make(map[string]*Report)
+)
+
+// Load 编译模块中的包 pkg（如 ./data），供测试使用，结果按包缓存
+func Load(tb testing.TB, pkg string) *Report {
+	tb.Helper()
+	if testing.Short() {
+		tb.Skip("inline: skipped in short mode")
+	}
+
+	root, err := source.ModuleRoot(".")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	key := root + "\x00" + pkg
+	if r := cache[key]; r != nil {
+		return r
+	}
+	ir, err := inline.Run(root, "", pkg)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	r := &Report{ir}
+	cache[key] = r
+	return r
+}
+
+// AssertInlinable 函数 name（编译器输出的名字）可以内联
+func (r *Report) AssertInlinable(tb testing.TB, name string) {
+	tb.Helper()
+	d := r.Decision(name)
+	switch {
+	case d == nil:
+		tb.Errorf("no inlining decision for %s", name)
+	case !d.Inlinable:
+		tb.Errorf("%s", d)
+	}
+}
+
+// AssertNotInlinable 函数不能内联，原因包含 reason
+func (r *Report) AssertNotInlinable(tb testing.TB, name, reason string) {
+	tb.Helper()
+	d := r.Decision(name)
+	switch {
+	case d == nil:
+		tb.Errorf("no inlining decision for %s", name)
+	case d.Inlinable || !strings.Contains(d.Reason, reason):
+		tb.Errorf("%s, want reason %q", d, reason)
+	}
+}
+
+// AssertBounds 顶层函数 fn 中最多剩余 n 处边界检查
+func (r *Report) AssertBounds(tb testing.TB, fn string, n int) {
+	tb.Helper()
+	if b := r.Bounds(fn); len(b) > n {
+		var s strings.Builder
+		for _, it := range b {
+			s.WriteString("\t" + it.String() + "\n")
+		}
+		tb.Errorf("%s: %d bounds checks, want at most %d:\n%s", fn, len(b), n, &s)
+	}
+}
@@ -0,0 +1,126 @@
+package methodset
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"unsafe"
+)
+
+// 运行期接口值的结构，即 internal/abi.ITab（Go 1.22 起去掉了 hash 后的填充字段）：
+//
+//	type iface struct {
+//		tab  *itab
+//		data unsafe.Pointer
+//	}
+//	type itab struct {
+//		inter *interfacetype
+//		_type *_type
+//		hash  uint32
+//		fun   [1]uintptr // 实际长度为接口的方法数，按指针对齐：64 位从 +0x18 开始，32 位从 +0xc 开始
+//	}
+//
+// 布局随 Go 版本可能变化，Itab 用 reflect 得到的类型指针核对 inter 和 _type，
+// 再核对方法表中的函数名，不一致时返回错误
+
+type iface struct {
+	tab  *itab
+	data unsafe.Pointer
+}
+
+type itab struct {
+	inter unsafe.Pointer
+	typ   unsafe.Pointer
+	hash  uint32
+	fun   [1]uintptr
+}
+
+type eface struct {
+	typ  unsafe.Pointer
+	data unsafe.Pointer
+}
+
+// typePtr reflect.Type 的动态值即运行时的 *_type
+func typePtr(t reflect.Type) unsafe.Pointer {
+	return (*iface)(unsafe.Pointer(&t)).data
+}
+
+// Entry 方法表中的一项
+type Entry struct {
+	Method string  // 接口方法名
+	Offset uintptr // 相对 itab 起始地址的偏移，即汇编中 MOVQ off(AX), CX 的 off
+	PC     uintptr
+	Func   string // 实际调用的函数，接收者为 data；data 是指向副本的指针时，值接收者的方法为编译器生成的 (*T).m 包装函数
+}
+
+// Table 一个 itab
+type Table struct {
+	Interface string
+	Type      string
+	Hash      uint32
+	Entries   []Entry
+}
+
+// Itab 接口值 v 的 itab，I 必须是非空接口类型，v 不能为 nil
+// 方法表按接口方法名排序，和 reflect 对接口类型列出方法的顺序相同
+func Itab[I any](v I) (*Table, error) {
+	it := reflect.TypeOf((*I)(nil)).Elem()
+	if it.Kind() != reflect.Interface || it.NumMethod() == 0 {
+		return nil, fmt.Errorf("methodset: %s is not a non-empty interface", it)
+	}
+	tab := (*iface)(unsafe.Pointer(&v)).tab
+	if tab == nil {
+		return nil, fmt.Errorf("methodset: nil %s", it)
+	}
+	dyn := any(v)
+	layoutErr := fmt.Errorf("methodset: itab layout of %s does not match", runtime.Version())
+	if tab.inter != typePtr(it) || tab.typ != (*eface)(unsafe.Pointer(&dyn)).typ {
+		return nil, layoutErr
+	}
+
+	t := &Table{
+		Interface: it.String(),
+		Type:      reflect.TypeOf(dyn).String(),
+		Hash:      tab.hash,
+	}
+	fun := unsafe.Pointer(&tab.fun[0])
+	for i := 0; i < it.NumMethod(); i++ {
+		// fun 的实际长度超出声明的 [1]uintptr，只能用 unsafe.Add 访问
+		pc := *(*uintptr)(unsafe.Add(fun, uintptr(i)*unsafe.Sizeof(uintptr(0))))
+		e := Entry{
+			Method: it.Method(i).Name,
+			Offset: unsafe.Offsetof(tab.fun) + uintptr(i)*unsafe.Sizeof(uintptr(0)),
+			PC:     pc,
+		}
+		if f := runtime.FuncForPC(pc); f != nil {
+			e.Func = f.Name()
+		}
+		// inter、_type 核对不出 fun 的偏移，再用函数名核对；
+		// 从未经接口调用的方法被链接器替换为 runtime.unreachableMethod，无法核对
+		if e.Func != "runtime.unreachableMethod" && !strings.HasSuffix(e.Func, "."+e.Method) {
+			return nil, layoutErr
+		}
+		t.Entries = append(t.Entries, e)
+	}
+	return t, nil
+}
+
+// Offset 方法 name 在方法表中的偏移，不存在时 ok 为 false
+func (t *Table) Offset(name string) (off uintptr, ok bool) {
+	for _, e := range t.Entries {
+		if e.Method == name {
+			return e.Offset, true
+		}
+	}
+	return 0, false
+}
+
+func (t *Table) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "itab %s, %s (hash %#x)\n", t.Type, t.Interface, t.Hash)
+	for _, e := range t.Entries {
+		fmt.Fprintf(&b, "\t+%#x\t%s\t%s\n", e.Offset, e.Method, e.Func)
+	}
+	return b.String()
+}
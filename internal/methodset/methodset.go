@@ -0,0 +1,285 @@
+// Package methodset 列出类型 T 和 *T 的方法集，检查它们实现了哪些接口，并解释没有实现的原因
+//
+// 反射只能看到导出的方法（data.show 看不到 Z2.test），这里从源码做类型检查：
+// 目标包解析源码，依赖使用 go list -export 得到的导出数据，和编译器看到的完全一致。
+// 运行期的 itab 方法表见 itab.go
+package methodset
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Package 类型检查后的包
+type Package struct {
+	Path  string
+	Types *types.Package
+}
+
+type listed struct {
+	ImportPath string
+	Dir        string
+	GoFiles    []string
+	Export     string
+	DepOnly    bool
+}
+
+// Inspect 在模块根目录 root 下加载包 path，测试文件不参与
+func Inspect(root, path string) (*Package, error) {
+	cmd := exec.Command("go", "list", "-e", "-export", "-deps", "-json=ImportPath,Dir,GoFiles,Export,DepOnly", path)
+	cmd.Dir = root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("methodset: go list: %v\n%s", err, stderr.Bytes())
+	}
+
+	exports := make(map[string]string)
+	var target *listed
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		p := new(listed)
+		if err := dec.Decode(p); err != nil {
+			return nil, err
+		}
+		exports[p.ImportPath] = p.Export
+		if !p.DepOnly {
+			target = p
+		}
+	}
+	if target == nil {
+		return nil, fmt.Errorf("methodset: package %s not found", path)
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range target.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(target.Dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	imp := importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		file := exports[path]
+		if file == "" {
+			return nil, fmt.Errorf("methodset: no export data for %s", path)
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{bufio.NewReader(f), f}, nil
+	})
+	conf := types.Config{Importer: imp}
+	pkg, err := conf.Check(target.ImportPath, fset, files, nil)
+	if err != nil {
+		return nil, fmt.Errorf("methodset: %v", err)
+	}
+	return &Package{Path: target.ImportPath, Types: pkg}, nil
+}
+
+// Lookup 包中的类型，也可以是 "io.Writer" 这样依赖包中的类型，按导入包的名字查找
+func (p *Package) Lookup(name string) (types.Type, error) {
+	pkg := p.Types
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		pkg = nil
+		for _, imp := range p.Types.Imports() {
+			if imp.Name() == name[:i] || imp.Path() == name[:i] {
+				pkg = imp
+				break
+			}
+		}
+		if pkg == nil {
+			return nil, fmt.Errorf("methodset: package of %s is not imported by %s", name, p.Path)
+		}
+		name = name[i+1:]
+	}
+	tn, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("methodset: type %s not found in %s", name, pkg.Path())
+	}
+	return tn.Type(), nil
+}
+
+// Method 方法集中的一个方法
+type Method struct {
+	Name      string
+	Signature string // 不含接收者，如 func(int) string
+	Pointer   bool   // 接收者为指针
+	Promoted  bool   // 来自嵌入字段
+}
+
+func (m Method) String() string {
+	recv := "T"
+	if m.Pointer {
+		recv = "*T"
+	}
+	s := fmt.Sprintf("(%s) %s%s", recv, m.Name, strings.TrimPrefix(m.Signature, "func"))
+	if m.Promoted {
+		s += " [promoted]"
+	}
+	return s
+}
+
+// Sets 类型 T 和 *T 的方法集，按名字排序
+type Sets struct {
+	Type    string
+	Value   []Method // T.set
+	Pointer []Method // *T.set = T + *T
+}
+
+// MethodSets 类型 name 的方法集
+func (p *Package) MethodSets(name string) (*Sets, error) {
+	t, err := p.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Sets{
+		Type:    types.TypeString(t, p.qualifier),
+		Value:   p.methods(t),
+		Pointer: p.methods(types.NewPointer(t)),
+	}, nil
+}
+
+func (p *Package) methods(t types.Type) []Method {
+	ms := types.NewMethodSet(t)
+	out := make([]Method, 0, ms.Len())
+	for i := 0; i < ms.Len(); i++ {
+		sel := ms.At(i)
+		f := sel.Obj().(*types.Func)
+		sig := f.Type().(*types.Signature)
+		_, ptr := sig.Recv().Type().(*types.Pointer)
+		out = append(out, Method{
+			Name:      f.Name(),
+			Signature: sigString(sig, p.qualifier),
+			Pointer:   ptr,
+			Promoted:  len(sel.Index()) > 1,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func (p *Package) qualifier(pkg *types.Package) string {
+	if pkg == p.Types {
+		return ""
+	}
+	return pkg.Name()
+}
+
+func (s *Sets) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:\n", s.Type)
+	for _, m := range s.Value {
+		fmt.Fprintf(&b, "\t%s\n", m)
+	}
+	fmt.Fprintf(&b, "*%s:\n", s.Type)
+	for _, m := range s.Pointer {
+		fmt.Fprintf(&b, "\t%s\n", m)
+	}
+	return b.String()
+}
+
+// Satisfaction 类型对一个接口的实现情况
+type Satisfaction struct {
+	Type, Interface string
+	Value, Pointer  bool     // T、*T 是否实现
+	ValueWhy        []string // T 没有实现的原因，每个方法一条
+	PointerWhy      []string
+}
+
+func (s Satisfaction) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s -> %s: T %v, *T %v", s.Type, s.Interface, s.Value, s.Pointer)
+	for _, w := range s.ValueWhy {
+		fmt.Fprintf(&b, "\n\tT: %s", w)
+	}
+	for _, w := range s.PointerWhy {
+		fmt.Fprintf(&b, "\n\t*T: %s", w)
+	}
+	return b.String()
+}
+
+// Satisfies 类型 name 对各接口的实现情况，原因和编译器的报错相同：
+// missing method X、method X has pointer receiver、wrong type for method X
+func (p *Package) Satisfies(name string, ifaces ...string) ([]Satisfaction, error) {
+	t, err := p.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Satisfaction, 0, len(ifaces))
+	for _, in := range ifaces {
+		it, err := p.Lookup(in)
+		if err != nil {
+			return nil, err
+		}
+		iface, ok := it.Underlying().(*types.Interface)
+		if !ok {
+			return nil, fmt.Errorf("methodset: %s is not an interface", in)
+		}
+		s := Satisfaction{
+			Type:      types.TypeString(t, p.qualifier),
+			Interface: types.TypeString(it, p.qualifier),
+		}
+		s.ValueWhy = Explain(t, iface, p.qualifier)
+		s.PointerWhy = Explain(types.NewPointer(t), iface, p.qualifier)
+		s.Value, s.Pointer = len(s.ValueWhy) == 0, len(s.PointerWhy) == 0
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// Explain t 没有实现 iface 的原因，每个不满足的方法一条，实现时为空
+// 原因的写法和编译器的报错相同，qf 决定类型名的包前缀
+func Explain(t types.Type, iface *types.Interface, qf types.Qualifier) []string {
+	if types.Implements(t, iface) {
+		return nil
+	}
+	ms := types.NewMethodSet(t)
+	var ptr *types.MethodSet
+	if _, ok := t.(*types.Pointer); !ok {
+		ptr = types.NewMethodSet(types.NewPointer(t))
+	}
+	var out []string
+	for i := 0; i < iface.NumMethods(); i++ {
+		want := iface.Method(i)
+		if sel := ms.Lookup(want.Pkg(), want.Name()); sel != nil {
+			// Identical 比较签名时忽略接收者
+			if have := sel.Obj().Type(); !types.Identical(have, want.Type()) {
+				out = append(out, fmt.Sprintf("wrong type for method %s: have %s, want %s",
+					want.Name(), sigString(have, qf), sigString(want.Type(), qf)))
+			}
+			continue
+		}
+		if ptr != nil && ptr.Lookup(want.Pkg(), want.Name()) != nil {
+			out = append(out, fmt.Sprintf("method %s has pointer receiver", want.Name()))
+			continue
+		}
+		out = append(out, "missing method "+want.Name())
+	}
+	return out
+}
+
+// sigString 不含接收者的签名
+func sigString(t types.Type, qf types.Qualifier) string {
+	sig := t.(*types.Signature)
+	return types.TypeString(types.NewSignatureType(nil, nil, nil, sig.Params(), sig.Results(), sig.Variadic()), qf)
+}
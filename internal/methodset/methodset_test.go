@@ -0,0 +1,78 @@
+package methodset
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+type val int
+
+func (val) A()          {}
+func (*val) B(int) bool { return false }
+func (val) c() string   { return "" }
+
+type aber interface {
+	A()
+	B(int) bool
+	c() string
+}
+
+type ber interface {
+	B(int) bool
+}
+
+func TestItab(t *testing.T) {
+	v := val(1)
+	tab, err := Itab[aber](&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"A", "B", "c"}
+	ptr := unsafe.Sizeof(uintptr(0)) // fun 在 inter、_type、hash 之后，按指针对齐
+	for i, e := range tab.Entries {
+		if e.Method != want[i] || e.Offset != (3+uintptr(i))*ptr {
+			t.Errorf("entry %d = %+v\n%s", i, e, tab)
+		}
+	}
+	// 值接收者的方法 A 经指针调用，方法表中是编译器生成的 (*val).A 包装函数
+	if a, b := tab.Entries[0].Func, tab.Entries[1].Func; !strings.HasSuffix(a, ".(*val).A") || !strings.HasSuffix(b, ".(*val).B") {
+		t.Errorf("A = %s, B = %s\n%s", a, b, tab)
+	}
+	if !strings.HasPrefix(tab.String(), "itab *methodset.val, methodset.aber") {
+		t.Error(tab)
+	}
+
+	// 值装入接口时 data 是指向副本的指针，方法表中仍是 (*stringer).String 包装函数
+	tab, err = Itab[fmt.Stringer](stringer(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := tab.Entries[0].Func; !strings.HasSuffix(f, ".(*stringer).String") {
+		t.Error(tab)
+	}
+	// map 本身就是指针，直接存入 data，方法表中是值接收者的方法本身
+	tab, err = Itab[fmt.Stringer](mapStringer(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := tab.Entries[0].Func; !strings.HasSuffix(f, ".mapStringer.String") {
+		t.Error(tab)
+	}
+
+	if _, err := Itab[ber](nil); err == nil {
+		t.Error("nil interface accepted")
+	}
+	if _, err := Itab[any](1); err == nil {
+		t.Error("empty interface accepted")
+	}
+}
+
+type stringer int
+
+func (stringer) String() string { return "" }
+
+type mapStringer map[int]int
+
+func (mapStringer) String() string { return "" }
@@ -0,0 +1,84 @@
+// Package methodsettest 在测试中使用 methodset：按包缓存类型检查结果，提供断言，short 模式下跳过
+package methodsettest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"yuhen/internal/methodset"
+	"yuhen/internal/source"
+)
+
+// Package 带断言的 methodset.Package
+type Package struct {
+	*methodset.Package
+}
+
+var (
+	mu    sync.Mutex
+	cache = make(map[string]*Package)
+)
+
+// Load 加载模块中的包 pkg（如 ./data），供测试使用，结果按包缓存
+func Load(tb testing.TB, pkg string) *Package {
+	tb.Helper()
+	if testing.Short() {
+		tb.Skip("methodset: skipped in short mode")
+	}
+
+	root, err := source.ModuleRoot(".")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	key := root + "\x00" + pkg
+	if p := cache[key]; p != nil {
+		return p
+	}
+	mp, err := methodset.Inspect(root, pkg)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	p := &Package{mp}
+	cache[key] = p
+	return p
+}
+
+// AssertSatisfies 类型 name 对接口 iface 的实现情况，why 为 T 没有实现的原因，T 实现时为空
+func (p *Package) AssertSatisfies(tb testing.TB, name, iface string, value, pointer bool, why ...string) {
+	tb.Helper()
+	ss, err := p.Satisfies(name, iface)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	s := ss[0]
+	if s.Value != value || s.Pointer != pointer || strings.Join(s.ValueWhy, "\n") != strings.Join(why, "\n") {
+		tb.Errorf("got %s\nwant T %v, *T %v, why %q", s, value, pointer, why)
+	}
+}
+
+// AssertMethods 方法集中的方法名，依次为 T 和 *T 的
+func (p *Package) AssertMethods(tb testing.TB, name string, value, pointer []string) {
+	tb.Helper()
+	s, err := p.MethodSets(name)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if got := names(s.Value); got != strings.Join(value, " ") {
+		tb.Errorf("%s method set = %s, want %v\n%s", name, got, value, s)
+	}
+	if got := names(s.Pointer); got != strings.Join(pointer, " ") {
+		tb.Errorf("*%s method set = %s, want %v\n%s", name, got, pointer, s)
+	}
+}
+
+func names(ms []methodset.Method) string {
+	s := make([]string, len(ms))
+	for i, m := range ms {
+		s[i] = m.Name
+	}
+	return strings.Join(s, " ")
+}
@@ -0,0 +1,21 @@
+package methodsettest
+
+import "testing"
+
+func TestSatisfies(t *testing.T) {
+	// methodset 的非测试文件引用了 strings、io 等标准库的类型
+	p := Load(t, "./internal/methodset")
+	p.AssertSatisfies(t, "strings.Builder", "io.Writer", false, true, "method Write has pointer receiver")
+	p.AssertSatisfies(t, "strings.Reader", "io.Writer", false, false, "missing method Write")
+	p.AssertSatisfies(t, "Entry", "fmt.Stringer", false, false, "missing method String")
+	p.AssertSatisfies(t, "Table", "fmt.Stringer", false, true, "method String has pointer receiver")
+	p.AssertSatisfies(t, "Method", "fmt.Stringer", true, true)
+	p.AssertMethods(t, "Table", nil, []string{"Offset", "String"})
+
+	if _, err := p.Satisfies("Table", "Entry"); err == nil {
+		t.Error("non-interface accepted")
+	}
+	if _, err := p.MethodSets("nope"); err == nil {
+		t.Error("unknown type accepted")
+	}
+}
@@ -0,0 +1,67 @@
+package mock
+
+import "fmt"
+
+// Matcher 自定义的参数匹配
+type Matcher interface {
+	Match(v any) bool
+	String() string
+}
+
+type anyMatcher struct{}
+
+func (anyMatcher) Match(any) bool { return true }
+func (anyMatcher) String() string { return "mock.Any" }
+
+// Any 匹配任意参数
+var Any Matcher = anyMatcher{}
+
+type funcMatcher[T any] struct {
+	desc string
+	f    func(T) bool
+}
+
+func (m funcMatcher[T]) Match(v any) bool {
+	t, ok := v.(T)
+	if !ok && v != nil {
+		return false
+	}
+	return m.f(t)
+}
+
+func (m funcMatcher[T]) String() string { return m.desc }
+
+// Match 参数的类型为 T 且 f 返回 true，desc 用于报错
+func Match[T any](desc string, f func(T) bool) Matcher {
+	return funcMatcher[T]{desc, f}
+}
+
+// At 生成的代码用来取第 i 个参数或返回值，没有设置或者为 nil 时返回零值
+func At[T any](vs []any, i int) T {
+	var zero T
+	if i >= len(vs) || vs[i] == nil {
+		return zero
+	}
+	v, ok := vs[i].(T)
+	if !ok {
+		panic(fmt.Sprintf("mock: value %d is %T, want %T", i, vs[i], zero))
+	}
+	return v
+}
+
+// Rest 从第 i 个开始的可变参数
+func Rest[T any](vs []any, i int) []T {
+	var out []T
+	for ; i < len(vs); i++ {
+		out = append(out, At[T](vs, i))
+	}
+	return out
+}
+
+// Append 把可变参数展开追加到 args
+func Append[T any](args []any, vs []T) []any {
+	for _, v := range vs {
+		args = append(args, v)
+	}
+	return args
+}
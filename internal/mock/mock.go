@@ -0,0 +1,248 @@
+// Package mock 是 tools/cmd/mockgen 生成的 mock 使用的运行时
+//
+// 记录每次调用和参数，按参数匹配期望并返回预设值，同一方法的多个期望按声明顺序逐个消耗，
+// 因此每次调用可以返回不同的值；InOrder 要求期望按顺序发生；测试结束时（tb.Cleanup）报告没有满足的期望
+package mock
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Call 一次调用
+type Call struct {
+	Method string
+	Args   []any // 可变参数展开
+}
+
+func (c Call) String() string {
+	return c.Method + "(" + formatArgs(c.Args) + ")"
+}
+
+// Mock 一个 mock 对象的调用记录和期望
+type Mock struct {
+	tb      testing.TB
+	name    string
+	calls   []Call
+	expects []*Expect
+}
+
+// mu 保护所有 mock 的状态，InOrder 的前后期望可能属于不同的 mock，用一把锁避免互相等待
+var mu sync.Mutex
+
+// New 创建 mock，name 为类型名，用于报错
+func New(tb testing.TB, name string) *Mock {
+	m := &Mock{tb: tb, name: name}
+	tb.Cleanup(m.verify)
+	return m
+}
+
+// Expect 期望的一次或多次调用
+type Expect struct {
+	m        *Mock
+	method   string
+	args     []any
+	rets     []any
+	do       func(args []any) []any
+	min, max int // max < 0 不限次数
+	n        int
+	after    []*Expect
+	pos      string // 声明期望的位置
+}
+
+// Expect 期望方法 method 以 args 调用，默认一次
+// 参数是 Matcher 时调用它判断，否则和实参 reflect.DeepEqual 比较，nil 匹配所有 nil 值
+// 由生成的 ExpectXxx 调用，pos 记录的是 ExpectXxx 的调用者
+func (m *Mock) Expect(method string, args ...any) *Expect {
+	e := &Expect{m: m, method: method, args: args, min: 1, max: 1}
+	if _, file, line, ok := runtime.Caller(2); ok {
+		e.pos = fmt.Sprintf("%s:%d", file[strings.LastIndexByte(file, '/')+1:], line)
+	}
+	mu.Lock()
+	m.expects = append(m.expects, e)
+	mu.Unlock()
+	return e
+}
+
+// Return 设置返回值，没有设置的返回值为零值
+func (e *Expect) Return(vals ...any) *Expect {
+	mu.Lock()
+	e.rets, e.do = vals, nil
+	mu.Unlock()
+	return e
+}
+
+// Do 调用时执行 f，以其结果作为返回值
+func (e *Expect) Do(f func(args []any) []any) *Expect {
+	mu.Lock()
+	e.do = f
+	mu.Unlock()
+	return e
+}
+
+// Times 恰好调用 n 次
+func (e *Expect) Times(n int) *Expect {
+	mu.Lock()
+	e.min, e.max = n, n
+	mu.Unlock()
+	return e
+}
+
+// AnyTimes 调用任意次，包括 0 次
+func (e *Expect) AnyTimes() *Expect {
+	mu.Lock()
+	e.min, e.max = 0, -1
+	mu.Unlock()
+	return e
+}
+
+func (e *Expect) expect() *Expect { return e }
+
+func (e *Expect) String() string {
+	return fmt.Sprintf("%s: %s.%s(%s)", e.pos, e.m.name, e.method, formatArgs(e.args))
+}
+
+// Expectation 生成的 XxxCall 嵌入了 *Expect，都可以传给 InOrder
+type Expectation interface {
+	expect() *Expect
+}
+
+// InOrder 期望按顺序发生：前一个期望满足最少次数之前，后一个不会匹配
+// 可以跨越不同的 mock 对象
+func InOrder(es ...Expectation) {
+	for i := 1; i < len(es); i++ {
+		e := es[i].expect()
+		mu.Lock()
+		e.after = append(e.after, es[i-1].expect())
+		mu.Unlock()
+	}
+}
+
+// Call 记录调用，返回匹配的期望设置的返回值；没有匹配的期望时测试失败
+// 和 t.Fatal 一样，只能在测试的 goroutine 中失败退出
+func (m *Mock) Call(method string, args ...any) []any {
+	m.tb.Helper()
+	mu.Lock()
+	m.calls = append(m.calls, Call{method, args})
+	e, why := m.match(method, args)
+	if e == nil {
+		mu.Unlock()
+		m.tb.Fatalf("%s: unexpected call %s%s", m.name, Call{method, args}, why)
+		return nil
+	}
+	e.n++
+	rets, do := e.rets, e.do
+	mu.Unlock()
+
+	if do != nil {
+		return do(args)
+	}
+	return rets
+}
+
+// match 按声明顺序找第一个可用的期望，找不到时返回同名期望不匹配的原因
+func (m *Mock) match(method string, args []any) (*Expect, string) {
+	var why strings.Builder
+	for _, e := range m.expects {
+		if e.method != method {
+			continue
+		}
+		switch {
+		case !matchArgs(e.args, args):
+			fmt.Fprintf(&why, "\n\t%s: arguments do not match", e)
+		case e.max >= 0 && e.n >= e.max:
+			fmt.Fprintf(&why, "\n\t%s: already called %d times", e, e.n)
+		default:
+			if p := e.pending(); p != nil {
+				fmt.Fprintf(&why, "\n\t%s: must come after %s", e, p)
+				continue
+			}
+			return e, ""
+		}
+	}
+	return nil, why.String()
+}
+
+// pending 还没有满足最少次数的前置期望
+func (e *Expect) pending() *Expect {
+	for _, p := range e.after {
+		if p.n < p.min {
+			return p
+		}
+	}
+	return nil
+}
+
+func matchArgs(want, got []any) bool {
+	if len(want) != len(got) {
+		return false
+	}
+	for i, w := range want {
+		if !matchArg(w, got[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchArg(want, got any) bool {
+	if m, ok := want.(Matcher); ok {
+		return m.Match(got)
+	}
+	if want == nil {
+		return isNil(got)
+	}
+	return reflect.DeepEqual(want, got)
+}
+
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface, reflect.UnsafePointer:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// verify 测试结束时检查所有期望都满足了最少次数
+func (m *Mock) verify() {
+	m.tb.Helper()
+	mu.Lock()
+	defer mu.Unlock()
+	for _, e := range m.expects {
+		if e.n < e.min {
+			m.tb.Errorf("%s: missing call: called %d times, want %d", e, e.n, e.min)
+		}
+	}
+}
+
+// Calls 方法 method 的调用记录，method 为空时返回全部
+func (m *Mock) Calls(method string) []Call {
+	mu.Lock()
+	defer mu.Unlock()
+	var out []Call
+	for _, c := range m.calls {
+		if method == "" || c.Method == method {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func formatArgs(args []any) string {
+	ss := make([]string, len(args))
+	for i, a := range args {
+		if m, ok := a.(Matcher); ok {
+			ss[i] = m.String()
+			continue
+		}
+		ss[i] = fmt.Sprintf("%#v", a)
+	}
+	return strings.Join(ss, ", ")
+}
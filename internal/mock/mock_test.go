@@ -0,0 +1,156 @@
+package mock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeTB 记录失败信息，Fatalf 以 panic 代替 runtime.Goexit
+type fakeTB struct {
+	testing.TB
+	errs     []string
+	cleanups []func()
+}
+
+type fatal struct{}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Cleanup(fn func()) { f.cleanups = append(f.cleanups, fn) }
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errs = append(f.errs, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	f.Errorf(format, args...)
+	panic(fatal{})
+}
+
+func (f *fakeTB) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+// call 调用 fn，Fatalf 时返回 true
+func call(fn func()) (failed bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(fatal); !ok {
+				panic(r)
+			}
+			failed = true
+		}
+	}()
+	fn()
+	return false
+}
+
+func TestReturnPerCall(t *testing.T) {
+	tb := new(fakeTB)
+	m := New(tb, "MockX")
+	m.Expect("Get", "a").Return(1)
+	m.Expect("Get", "a").Return(2)
+	m.Expect("Get", Any).Return(0).AnyTimes()
+
+	var got []int
+	for _, k := range []string{"a", "a", "a", "b"} {
+		got = append(got, At[int](m.Call("Get", k), 0))
+	}
+	if fmt.Sprint(got) != "[1 2 0 0]" {
+		t.Error(got)
+	}
+	if cs := m.Calls("Get"); len(cs) != 4 || cs[3].String() != `Get("b")` {
+		t.Error(cs)
+	}
+	tb.finish()
+	if len(tb.errs) > 0 {
+		t.Error(tb.errs)
+	}
+}
+
+func TestUnexpected(t *testing.T) {
+	tb := new(fakeTB)
+	m := New(tb, "MockX")
+	m.Expect("Put", "a", 1)
+	if !call(func() { m.Call("Put", "a", 2) }) {
+		t.Fatal("unexpected call accepted")
+	}
+	if len(tb.errs) != 1 || !strings.Contains(tb.errs[0], `unexpected call Put("a", 2)`) || !strings.Contains(tb.errs[0], "arguments do not match") {
+		t.Error(tb.errs)
+	}
+
+	// 次数用完
+	tb.errs = nil
+	m.Call("Put", "a", 1)
+	if !call(func() { m.Call("Put", "a", 1) }) || !strings.Contains(tb.errs[0], "already called 1 times") {
+		t.Error(tb.errs)
+	}
+}
+
+func TestMissing(t *testing.T) {
+	tb := new(fakeTB)
+	m := New(tb, "MockX")
+	m.Expect("Close").Times(2)
+	m.Expect("Flush").AnyTimes()
+	m.Call("Close")
+	tb.finish()
+	if len(tb.errs) != 1 || !strings.Contains(tb.errs[0], "MockX.Close(): missing call: called 1 times, want 2") {
+		t.Error(tb.errs)
+	}
+}
+
+func TestInOrder(t *testing.T) {
+	tb := new(fakeTB)
+	a, b := New(tb, "MockA"), New(tb, "MockB")
+	open := a.Expect("Open")
+	write := b.Expect("Write", Any).Times(2)
+	closing := a.Expect("Close")
+	InOrder(open, write, closing)
+
+	if !call(func() { b.Call("Write", 1) }) || !strings.Contains(tb.errs[0], "must come after") {
+		t.Fatal(tb.errs)
+	}
+	tb.errs = nil
+	a.Call("Open")
+	b.Call("Write", 1)
+	// Write 还差一次，Close 不能匹配
+	if !call(func() { a.Call("Close") }) {
+		t.Fatal("Close before second Write accepted")
+	}
+	tb.errs = nil
+	b.Call("Write", 2)
+	a.Call("Close")
+	tb.finish()
+	if len(tb.errs) > 0 {
+		t.Error(tb.errs)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	var p *int
+	even := Match("even", func(n int) bool { return n%2 == 0 })
+	for _, c := range []struct {
+		want, got any
+		ok        bool
+	}{
+		{nil, p, true}, // nil 匹配有类型的 nil
+		{nil, 0, false},
+		{[]int{1}, []int{1}, true},
+		{even, 2, true},
+		{even, 3, false},
+		{even, "2", false},
+		{Any, nil, true},
+	} {
+		if matchArg(c.want, c.got) != c.ok {
+			t.Errorf("matchArg(%v, %#v) != %v", c.want, c.got, c.ok)
+		}
+	}
+
+	vs := Append([]any{"x"}, []int{1, 2})
+	if len(vs) != 3 || At[string](vs, 0) != "x" || fmt.Sprint(Rest[int](vs, 1)) != "[1 2]" || At[error](vs, 5) != nil {
+		t.Error(vs)
+	}
+}
@@ -0,0 +1,137 @@
+// Package source 为解析编译器诊断输出的工具提供源码位置相关的辅助：
+// 模块根目录、按行号查找所在函数、检出 git 版本
+package source
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Func 一个顶层函数的行范围
+type Func struct {
+	Name       string // 和编译器输出一致的名字：f、T.m、(*T).m
+	Start, End int
+}
+
+// Funcs 解析文件中的顶层函数
+func Funcs(file string) ([]Func, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	var fs []Func
+	for _, d := range f.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		fs = append(fs, Func{funcName(fd), fset.Position(fd.Pos()).Line, fset.Position(fd.End()).Line})
+	}
+	return fs, nil
+}
+
+func funcName(fd *ast.FuncDecl) string {
+	if fd.Recv == nil || len(fd.Recv.List) == 0 {
+		return fd.Name.Name
+	}
+	t := fd.Recv.List[0].Type
+	ptr := false
+	if s, ok := t.(*ast.StarExpr); ok {
+		t, ptr = s.X, true
+	}
+	switch x := t.(type) {
+	case *ast.IndexExpr:
+		t = x.X
+	case *ast.IndexListExpr:
+		t = x.X
+	}
+	name := fmt.Sprint(t)
+	if id, ok := t.(*ast.Ident); ok {
+		name = id.Name
+	}
+	if ptr {
+		return "(*" + name + ")." + fd.Name.Name
+	}
+	return name + "." + fd.Name.Name
+}
+
+// Index 按文件缓存函数范围，文件相对于 Root
+type Index struct {
+	Root  string
+	files map[string][]Func
+}
+
+// Enclosing 文件 file 第 line 行所在的顶层函数，不在函数中或文件无法解析时为空
+// （如 <autogenerated> 或模块外的文件）
+func (x *Index) Enclosing(file string, line int) string {
+	if x.files == nil {
+		x.files = make(map[string][]Func)
+	}
+	fs, ok := x.files[file]
+	if !ok {
+		fs, _ = Funcs(filepath.Join(x.Root, file))
+		x.files[file] = fs
+	}
+	for _, f := range fs {
+		if f.Start <= line && line <= f.End {
+			return f.Name
+		}
+	}
+	return ""
+}
+
+// ModuleRoot 包含 dir 的模块根目录
+func ModuleRoot(dir string) (string, error) {
+	cmd := exec.Command("go", "env", "GOMOD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	mod := strings.TrimSpace(string(out))
+	if mod == "" || mod == os.DevNull {
+		return "", fmt.Errorf("source: %s is not in a module", dir)
+	}
+	return filepath.Dir(mod), nil
+}
+
+// Checkout 把模块 root 所在仓库的版本 rev 检出到临时的 git worktree 中，
+// 返回该版本中对应的模块目录，用完后调用 cleanup 删除
+func Checkout(root, rev string) (dir string, cleanup func(), err error) {
+	// 模块在仓库中的相对位置，检出的是整个仓库
+	prefix, err := git(root, "rev-parse", "--show-prefix")
+	if err != nil {
+		return "", nil, err
+	}
+	tmp, err := os.MkdirTemp("", "checkout")
+	if err != nil {
+		return "", nil, err
+	}
+	wt := filepath.Join(tmp, "src")
+	if _, err := git(root, "worktree", "add", "--detach", wt, rev); err != nil {
+		os.RemoveAll(tmp)
+		return "", nil, err
+	}
+	cleanup = func() {
+		git(root, "worktree", "remove", "--force", wt)
+		os.RemoveAll(tmp)
+	}
+	return filepath.Join(wt, prefix), cleanup, nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
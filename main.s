@@ -1,3 +1,5 @@
+//go:build amd64
+
 #include "textflag.h"
 
 TEXT ·get(SB), NOSPLIT, $0-8
@@ -0,0 +1,208 @@
+// Package abiinspect 对照 ABIInternal 的寄存器分配规则注解函数的反汇编
+//
+// type/ 中的汇编都是基于栈的 ABI0，而 Go 编译的函数使用寄存器传参（ABIInternal），
+// 两者互相调用时编译器会插入包装函数。这里按规则计算每个参数和返回值所在的寄存器，
+// 编译目标包后用 go tool objdump 取出函数的指令，标出参数寄存器的使用和包装函数的位置
+package abiinspect
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// amd64 上 ABIInternal 使用的寄存器，按分配顺序
+var (
+	intRegs   = []string{"AX", "BX", "CX", "DI", "SI", "R8", "R9", "R10", "R11"}
+	floatRegs = []string{"X0", "X1", "X2", "X3", "X4", "X5", "X6", "X7", "X8", "X9", "X10", "X11", "X12", "X13", "X14"}
+	sizes     = types.SizesFor("gc", "amd64")
+)
+
+// Slot 一个参数或返回值的位置
+type Slot struct {
+	Name     string
+	Type     string
+	Regs     []string // 分配到的寄存器，按字段顺序
+	Parts    []string // 每个寄存器中保存的部分，如 s.ptr s.len
+	StackOff int      // 分不到寄存器时在栈上参数区的偏移，-1 表示在寄存器中
+}
+
+func (s Slot) String() string {
+	if s.StackOff >= 0 {
+		return fmt.Sprintf("%-8s %-12s stack+%d", s.Name, s.Type, s.StackOff)
+	}
+	if len(s.Regs) == 0 {
+		return fmt.Sprintf("%-8s %-12s (zero size)", s.Name, s.Type)
+	}
+	return fmt.Sprintf("%-8s %-12s %s", s.Name, s.Type, strings.Join(s.Regs, " "))
+}
+
+// Assignment 一个函数在 ABIInternal 下的参数和返回值位置
+type Assignment struct {
+	Params, Results []Slot
+	StackArgs       int // 栈上参数区的大小（不含寄存器参数的溢出区）
+}
+
+// Assign 按 ABIInternal 规则分配寄存器
+// dict 为 true 时第一个整数寄存器传递泛型字典（shape 实例化的函数）
+func Assign(sig *types.Signature, dict bool) *Assignment {
+	a := &Assignment{}
+	stack := 0
+
+	assign := func(vars []*types.Var, result bool) []Slot {
+		var slots []Slot
+		ni, nf := 0, 0
+		if dict {
+			slots = append(slots, Slot{Name: ".dict", Type: "*dictionary", Regs: []string{intRegs[0]}, Parts: []string{".dict"}, StackOff: -1})
+			ni = 1
+		}
+		for i, v := range vars {
+			name := v.Name()
+			if name == "" || name == "_" {
+				name = fmt.Sprintf("~p%d", i)
+				if result {
+					name = fmt.Sprintf("~r%d", i)
+				}
+			}
+			s := Slot{Name: name, Type: types.TypeString(v.Type(), nil), StackOff: -1}
+			i0, f0 := ni, nf
+			if regs, ok := regAssign(v.Type(), &ni, &nf); ok {
+				s.Regs, s.Parts = regs, labels(name, v.Type())
+			} else {
+				// 整个值放到栈上，已经分配的寄存器还回去
+				ni, nf = i0, f0
+				stack = align(stack, int(sizes.Alignof(v.Type())))
+				s.StackOff = stack
+				stack += int(sizes.Sizeof(v.Type()))
+			}
+			slots = append(slots, s)
+		}
+		return slots
+	}
+
+	var params []*types.Var
+	if r := sig.Recv(); r != nil {
+		params = append(params, r)
+	}
+	for i := 0; i < sig.Params().Len(); i++ {
+		params = append(params, sig.Params().At(i))
+	}
+	a.Params = assign(params, false)
+	stack = align(stack, 8)
+
+	dict = false
+	var results []*types.Var
+	for i := 0; i < sig.Results().Len(); i++ {
+		results = append(results, sig.Results().At(i))
+	}
+	a.Results = assign(results, true)
+	a.StackArgs = align(stack, 8)
+	return a
+}
+
+// regAssign 递归地把 t 拆成寄存器，任何一部分放不下就整体失败
+func regAssign(t types.Type, ni, nf *int) ([]string, bool) {
+	ints := func(n int) ([]string, bool) {
+		if *ni+n > len(intRegs) {
+			return nil, false
+		}
+		r := intRegs[*ni : *ni+n]
+		*ni += n
+		return r, true
+	}
+	floats := func(n int) ([]string, bool) {
+		if *nf+n > len(floatRegs) {
+			return nil, false
+		}
+		r := floatRegs[*nf : *nf+n]
+		*nf += n
+		return r, true
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Kind() == types.String:
+			return ints(2)
+		case u.Info()&types.IsComplex != 0:
+			return floats(2)
+		case u.Info()&types.IsFloat != 0:
+			return floats(1)
+		}
+		return ints(1)
+	case *types.Slice:
+		return ints(3)
+	case *types.Interface:
+		return ints(2)
+	case *types.Pointer, *types.Map, *types.Chan, *types.Signature:
+		return ints(1)
+	case *types.Array:
+		switch u.Len() {
+		case 0:
+			return nil, true
+		case 1:
+			return regAssign(u.Elem(), ni, nf)
+		}
+		return nil, false
+	case *types.Struct:
+		var regs []string
+		for i := 0; i < u.NumFields(); i++ {
+			r, ok := regAssign(u.Field(i).Type(), ni, nf)
+			if !ok {
+				return nil, false
+			}
+			regs = append(regs, r...)
+		}
+		return regs, true
+	}
+	return nil, false
+}
+
+// labels 按寄存器分配的顺序给值的每一部分命名，和 regAssign 一一对应
+func labels(name string, t types.Type) []string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Kind() == types.String:
+			return []string{name + ".ptr", name + ".len"}
+		case u.Info()&types.IsComplex != 0:
+			return []string{name + ".real", name + ".imag"}
+		}
+	case *types.Slice:
+		return []string{name + ".ptr", name + ".len", name + ".cap"}
+	case *types.Interface:
+		return []string{name + ".type", name + ".data"}
+	case *types.Array:
+		switch u.Len() {
+		case 0:
+			return nil
+		case 1:
+			return labels(name+"[0]", u.Elem())
+		}
+	case *types.Struct:
+		var ls []string
+		for i := 0; i < u.NumFields(); i++ {
+			ls = append(ls, labels(name+"."+u.Field(i).Name(), u.Field(i).Type())...)
+		}
+		return ls
+	}
+	return []string{name}
+}
+
+func align(n, a int) int {
+	return (n + a - 1) &^ (a - 1)
+}
+
+// String 参数和返回值表
+func (a *Assignment) String() string {
+	var b strings.Builder
+	b.WriteString("  params:\n")
+	for _, s := range a.Params {
+		fmt.Fprintf(&b, "    %s\n", s)
+	}
+	b.WriteString("  results:\n")
+	for _, s := range a.Results {
+		fmt.Fprintf(&b, "    %s\n", s)
+	}
+	return b.String()
+}
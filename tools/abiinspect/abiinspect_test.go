@@ -0,0 +1,155 @@
+package abiinspect
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func impl(t *testing.T, r *Report, sym string) *Impl {
+	t.Helper()
+	for _, im := range r.Impls {
+		if im.Sym == sym {
+			return im
+		}
+	}
+	t.Fatalf("no %s in report:\n%s", sym, r)
+	return nil
+}
+
+func regs(slots []Slot) string {
+	var ss []string
+	for _, s := range slots {
+		ss = append(ss, s.Name+":"+strings.Join(s.Regs, ","))
+	}
+	return strings.Join(ss, " ")
+}
+
+func contains(t *testing.T, lines []string, want ...string) {
+	t.Helper()
+	all := strings.Join(lines, "\n")
+	for _, w := range want {
+		if !strings.Contains(all, w) {
+			t.Errorf("missing %q in:\n%s", w, all)
+		}
+	}
+}
+
+func TestAddO(t *testing.T) {
+	r, err := Inspect("../../type", "add_o")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := impl(t, r, "yuhen/type.add_o")
+	if body.Kind != KindBody {
+		t.Errorf("kind = %s", body.Kind)
+	}
+	if got := regs(body.Assign.Params) + " | " + regs(body.Assign.Results); got != "x:AX y:BX | ~r0:AX" {
+		t.Errorf("assignment = %s", got)
+	}
+	contains(t, body.Listing, "ADDQ BX, AX", "// AX=x BX=y", "returns AX=~r0")
+
+	// output.s 中 CALL ·add_o(SB) 使编译器生成 ABI0 包装函数
+	w := impl(t, r, "yuhen/type.add_o.abi0")
+	if w.Kind != KindToInternal || w.Note != "inserted for assembly callers: output.s:11" {
+		t.Errorf("wrapper = %s %q", w.Kind, w.Note)
+	}
+	contains(t, w.Moves, "x+0(FP)          -> AX", "y+8(FP)          -> BX", "ret+16(FP)       <- AX")
+}
+
+func TestAsmCaller(t *testing.T) {
+	r, err := Inspect("../../type", "output_o")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := impl(t, r, "yuhen/type.output_o")
+	if w.Kind != KindToABI0 {
+		t.Errorf("kind = %s", w.Kind)
+	}
+	contains(t, w.Moves, "a+0(FP)          <- AX", "ret+16(FP)       -> AX")
+
+	if len(r.Callers) != 1 || r.Callers[0].Caller != "yuhen/type.Main_output" {
+		t.Fatalf("callers:\n%s", r)
+	}
+	contains(t, r.Callers[0].Setup, "direct ABI0 call", "MOVQ $0xa, 0(SP)", "// a+0(FP)", "// b+8(FP)")
+}
+
+func TestStringParam(t *testing.T) {
+	r, err := Inspect("../..", "stringParam")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := impl(t, r, "main.stringParam")
+	if got := regs(body.Assign.Params); got != "s:AX,BX" {
+		t.Errorf("assignment = %s", got)
+	}
+
+	// sliceTest 被内联进 main，字符串的指针和长度分别装入 AX BX
+	var setup []string
+	for _, c := range r.Callers {
+		setup = append(setup, c.Setup...)
+	}
+	contains(t, setup, "// AX=s.ptr", "MOVL $0x4, BX", "// BX=s.len")
+}
+
+func TestGeneric(t *testing.T) {
+	r, err := Inspect("../../data", "Max")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shape := impl(t, r, "yuhen/data.Max[go.shape.int]")
+	if shape.Kind != KindShape {
+		t.Errorf("kind = %s", shape.Kind)
+	}
+	// shape 实例的第一个寄存器是字典，参数顺延到 BX CX
+	if got := regs(shape.Assign.Params) + " | " + regs(shape.Assign.Results); got != ".dict:AX x:BX y:CX | ~r0:AX" {
+		t.Errorf("shape assignment = %s", got)
+	}
+	contains(t, shape.Listing, "CMPQ CX, BX", "// BX=x CX=y")
+
+	f := impl(t, r, "yuhen/data.Max[go.shape.float64]")
+	if got := regs(f.Assign.Params) + " | " + regs(f.Assign.Results); got != ".dict:AX x:X0 y:X1 | ~r0:X0" {
+		t.Errorf("float assignment = %s", got)
+	}
+
+	inst := impl(t, r, "yuhen/data.Max[int]")
+	if got := regs(inst.Assign.Params); got != "x:AX y:BX" {
+		t.Errorf("instantiation assignment = %s", got)
+	}
+}
+
+func TestAssign(t *testing.T) {
+	i := types.Typ[types.Int]
+	params := func(ts ...types.Type) *types.Tuple {
+		var vs []*types.Var
+		for _, t := range ts {
+			vs = append(vs, types.NewParam(0, nil, "", t))
+		}
+		return types.NewTuple(vs...)
+	}
+	big := types.NewStruct([]*types.Var{
+		types.NewField(0, nil, "a", types.NewSlice(i), false),
+		types.NewField(0, nil, "b", types.NewSlice(i), false),
+		types.NewField(0, nil, "c", types.NewSlice(i), false),
+		types.NewField(0, nil, "d", i, false),
+	}, nil)
+	pair := types.NewArray(i, 2)
+
+	// 9 个整数参数寄存器不够时整个结构体放到栈上，后面的参数仍然可以用寄存器
+	sig := types.NewSignatureType(nil, nil, nil,
+		params(types.Typ[types.String], big, pair, types.Typ[types.Complex128], i),
+		params(i, types.Typ[types.Bool]), false)
+	a := Assign(sig, false)
+	want := "~p0:AX,BX ~p1: ~p2: ~p3:X0,X1 ~p4:CX | ~r0:AX ~r1:BX"
+	if got := regs(a.Params) + " | " + regs(a.Results); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+	if a.Params[1].StackOff != 0 || a.Params[2].StackOff != 80 || a.StackArgs != 96 {
+		t.Errorf("stack offsets %d %d, size %d", a.Params[1].StackOff, a.Params[2].StackOff, a.StackArgs)
+	}
+	if got := strings.Join(a.Params[0].Parts, " "); got != "~p0.ptr ~p0.len" {
+		t.Errorf("parts = %s", got)
+	}
+}
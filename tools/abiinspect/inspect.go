@@ -0,0 +1,471 @@
+package abiinspect
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"yuhen/internal/disasm"
+	"yuhen/tools/asmcheck"
+)
+
+// Impl 函数的一个实现：Go 编译的函数体、泛型的一个实例、汇编函数体或 ABI 包装函数
+type Impl struct {
+	Sym     string
+	File    string
+	Kind    string      // 见下面的常量
+	Assign  *Assignment // ABIInternal 的寄存器分配，汇编函数体为 nil
+	Moves   []string    // 包装函数中 ABI0 栈上参数和寄存器的对应关系
+	Note    string
+	Listing []string // 带注解的指令
+}
+
+const (
+	KindBody       = "ABIInternal"                 // Go 编译的函数体
+	KindShape      = "ABIInternal, shape instance" // 泛型按 shape 实例化的函数体，第一个整数寄存器是字典
+	KindInstance   = "ABIInternal, instantiation"  // 泛型的具体实例，调用 shape 函数时补上字典
+	KindAsm        = "ABI0, assembly"              // 汇编实现的函数体
+	KindToABI0     = "wrapper ABIInternal -> ABI0" // Go 代码调用汇编函数：寄存器参数写到栈上
+	KindToInternal = "wrapper ABI0 -> ABIInternal" // 汇编调用 Go 函数：栈上参数装入寄存器
+)
+
+// CallSite 调用方在 CALL 之前准备参数的指令
+type CallSite struct {
+	Caller string
+	Pos    string
+	Target string
+	Setup  []string
+}
+
+// Report 一个函数的检查结果
+type Report struct {
+	Func    string
+	Sig     string
+	Impls   []*Impl
+	Callers []*CallSite
+}
+
+// Inspect 编译 dir 中的包，注解函数 name 的所有实现以及包内的调用点
+func Inspect(dir, name string) (*Report, error) {
+	pkg, decl, err := load(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	obj, _ := pkg.TypesInfo.Defs[decl.Name].(*types.Func)
+	if obj == nil {
+		return nil, fmt.Errorf("abiinspect: %s has no type information", name)
+	}
+	sig := obj.Type().(*types.Signature)
+
+	tmp, err := os.MkdirTemp("", "abiinspect")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	// main 包得到可执行文件，其他包得到归档，两者都可以交给 objdump
+	out := filepath.Join(tmp, "out")
+	cmd := exec.Command("go", "build", "-o", out, ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOARCH=amd64")
+	if b, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("abiinspect: go build: %v\n%s", err, b)
+	}
+
+	prefix := pkg.PkgPath + "."
+	if pkg.Name == "main" {
+		prefix = "main."
+	}
+	fns, err := disasm.Objdump(out, "^"+regexp.QuoteMeta(prefix))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{Func: prefix + name, Sig: types.TypeString(sig, types.RelativeTo(pkg.Types))}
+	for _, fn := range fns {
+		rest, ok := strings.CutPrefix(fn.Sym, prefix+name)
+		if !ok || rest != "" && rest != ".abi0" && !strings.HasPrefix(rest, "[") {
+			continue
+		}
+		im, err := newImpl(pkg, decl, sig, fn, rest)
+		if err != nil {
+			return nil, err
+		}
+		r.Impls = append(r.Impls, im)
+	}
+	if len(r.Impls) == 0 {
+		return nil, fmt.Errorf("abiinspect: no code for %s in the build output (inlined everywhere or unused?)", r.Func)
+	}
+
+	var abi0 *asmcheck.Layout
+	if decl.Body == nil {
+		abi0 = asmcheck.NewLayout(asmcheck.LookupArch("amd64"), decl, pkg.TypesInfo)
+	}
+	for _, fn := range fns {
+		if strings.HasPrefix(fn.Sym, prefix+name) && (len(fn.Sym) == len(prefix+name) || !isIdentByte(fn.Sym[len(prefix+name)])) {
+			continue
+		}
+		r.Callers = append(r.Callers, callSites(fn, r.Impls, Assign(sig, false), abi0)...)
+	}
+	return r, nil
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func load(dir, name string) (*packages.Package, *ast.FuncDecl, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir:  dir,
+		Env:  append(os.Environ(), "GOARCH=amd64"),
+	}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, nil, fmt.Errorf("abiinspect: expected one package in %s", dir)
+	}
+	pkg := pkgs[0]
+	for _, f := range pkg.Syntax {
+		for _, d := range f.Decls {
+			if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil && fd.Name.Name == name {
+				return pkg, fd, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("abiinspect: function %s not found in %s", name, pkg.PkgPath)
+}
+
+func newImpl(pkg *packages.Package, decl *ast.FuncDecl, sig *types.Signature, fn *disasm.Func, rest string) (*Impl, error) {
+	im := &Impl{Sym: fn.Sym, File: fn.File}
+	wrapper := fn.File == "<autogenerated>"
+
+	switch {
+	case strings.HasPrefix(rest, "["):
+		targs, shape, err := typeArgs(pkg, strings.TrimSuffix(rest[1:], "]"))
+		if err != nil {
+			return nil, err
+		}
+		inst, err := types.Instantiate(nil, sig, targs, false)
+		if err != nil {
+			return nil, err
+		}
+		im.Kind = KindInstance
+		if shape {
+			im.Kind = KindShape
+		}
+		im.Assign = Assign(inst.(*types.Signature), shape)
+		im.Listing = annotate(fn, im.Assign)
+		return im, nil
+
+	case decl.Body == nil && !wrapper:
+		im.Kind = KindAsm
+	case decl.Body == nil:
+		im.Kind = KindToABI0
+		im.Note = "direct calls in this package use ABI0; the wrapper serves func values and other callers"
+	case wrapper:
+		im.Kind = KindToInternal
+		// 包归档中包装函数和函数体同名，链接后才带 .abi0 后缀
+		if !strings.HasSuffix(im.Sym, ".abi0") {
+			im.Sym += ".abi0"
+		}
+		if calls := asmCalls(pkg, decl.Name.Name); len(calls) > 0 {
+			im.Note = "inserted for assembly callers: " + strings.Join(calls, ", ")
+		}
+	default:
+		im.Kind = KindBody
+		im.Assign = Assign(sig, false)
+		im.Listing = annotate(fn, im.Assign)
+		return im, nil
+	}
+
+	// 汇编函数和包装函数：列出 ABI0 栈上参数与 ABIInternal 寄存器的对应关系
+	a := Assign(sig, false)
+	layout := asmcheck.NewLayout(asmcheck.LookupArch("amd64"), decl, pkg.TypesInfo)
+	im.Moves = moves(layout, a, im.Kind == KindToABI0)
+	if im.Kind == KindToABI0 {
+		// 包装函数的入口是 ABIInternal，Go 调用方按寄存器传参
+		im.Assign = a
+	}
+	for _, in := range fn.Insts {
+		im.Listing = append(im.Listing, listing(in))
+	}
+	return im, nil
+}
+
+// asmCalls 包中汇编文件调用 name 的位置
+func asmCalls(pkg *packages.Package, name string) []string {
+	re := regexp.MustCompile(`\bCALL\s+·` + regexp.QuoteMeta(name) + `(<ABI0>)?\(SB\)`)
+	var calls []string
+	for _, f := range pkg.OtherFiles {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		for i, line := range strings.Split(string(b), "\n") {
+			if re.MatchString(line) {
+				calls = append(calls, fmt.Sprintf("%s:%d", filepath.Base(f), i+1))
+			}
+		}
+	}
+	return calls
+}
+
+// typeArgs 解析符号中的类型实参，go.shape.T 表示按 shape 实例化
+func typeArgs(pkg *packages.Package, s string) ([]types.Type, bool, error) {
+	var targs []types.Type
+	shape := false
+	depth, start := 0, 0
+	var parts []string
+	for i, c := range s {
+		switch c {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, s[start:])
+	for _, p := range parts {
+		if t, ok := strings.CutPrefix(p, "go.shape."); ok {
+			p, shape = t, true
+		}
+		tv, err := types.Eval(token.NewFileSet(), pkg.Types, token.NoPos, p)
+		if err != nil {
+			return nil, false, fmt.Errorf("abiinspect: type argument %s: %v", p, err)
+		}
+		targs = append(targs, tv.Type)
+	}
+	return targs, shape, nil
+}
+
+// annotate 顺序扫描指令（不跟踪分支），标出仍然保存着参数的寄存器在哪里被读取，
+// 以及 RET 时返回值所在的寄存器
+func annotate(fn *disasm.Func, a *Assignment) []string {
+	params := make(map[string]string)
+	for _, s := range a.Params {
+		for i, r := range s.Regs {
+			params[r] = s.Parts[i]
+		}
+	}
+	var results []string
+	for _, s := range a.Results {
+		for i, r := range s.Regs {
+			results = append(results, r+"="+s.Parts[i])
+		}
+	}
+
+	var lines []string
+	for _, in := range fn.Insts {
+		var notes []string
+		for _, r := range sortedKeys(params) {
+			if reads(in, r) {
+				notes = append(notes, r+"="+params[r])
+			}
+		}
+		if w := written(in); w != "" {
+			delete(params, w)
+		}
+		if in.Target() != "" {
+			// 调用会破坏所有参数寄存器
+			clear(params)
+		}
+		if in.Op == "RET" && len(results) > 0 {
+			notes = append(notes, "returns "+strings.Join(results, " "))
+		}
+		line := listing(in)
+		if len(notes) > 0 {
+			line = fmt.Sprintf("%-52s // %s", line, strings.Join(notes, " "))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func sortedKeys(m map[string]string) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+// moves 把 ABI0 布局中的每个基本变量和 ABIInternal 分配的寄存器配对
+// toABI0 为 true 时寄存器写到栈上，否则从栈上装入寄存器；返回值方向相反
+func moves(l *asmcheck.Layout, a *Assignment, toABI0 bool) []string {
+	var params, results []*asmcheck.Var
+	for _, v := range l.Vars {
+		if v.Composite {
+			continue
+		}
+		if v.Result {
+			results = append(results, v)
+		} else {
+			params = append(params, v)
+		}
+	}
+	var out []string
+	pair := func(vars []*asmcheck.Var, slots []Slot, in bool) {
+		i := 0
+		for _, s := range slots {
+			if s.StackOff >= 0 {
+				// 放不进寄存器的参数在两种 ABI 中都在栈上
+				out = append(out, fmt.Sprintf("%s stays on the stack", s.Name))
+				i += leafCount(vars[i:], s)
+				continue
+			}
+			for _, r := range s.Regs {
+				if i >= len(vars) {
+					return
+				}
+				if in {
+					out = append(out, fmt.Sprintf("%-16s -> %s", vars[i], r))
+				} else {
+					out = append(out, fmt.Sprintf("%-16s <- %s", vars[i], r))
+				}
+				i++
+			}
+		}
+	}
+	// 从 ABI0 进入 ABIInternal：参数从栈装入寄存器，返回值从寄存器写回栈
+	pair(params, a.Params, !toABI0)
+	pair(results, a.Results, toABI0)
+	return out
+}
+
+// leafCount 栈上参数在 ABI0 布局中占用的基本变量个数
+func leafCount(vars []*asmcheck.Var, s Slot) int {
+	n := 0
+	for _, v := range vars {
+		if v.Name != s.Name && !strings.HasPrefix(v.Name, s.Name+"_") {
+			break
+		}
+		n++
+	}
+	return max(n, 1)
+}
+
+// callSites 找出 fn 中调用目标函数的指令，以及调用前准备参数的指令
+// Go 函数按 ABIInternal 找最后一次写入各参数寄存器的指令；
+// abi0 不为 nil 时目标是汇编函数，本包的直接调用不经过包装函数，参数写在调用方栈顶
+func callSites(fn *disasm.Func, impls []*Impl, a *Assignment, abi0 *asmcheck.Layout) []*CallSite {
+	var sites []*CallSite
+	start := 0
+	for i, in := range fn.Insts {
+		target := in.Target()
+		if target == "" {
+			continue
+		}
+		var im *Impl
+		for _, c := range impls {
+			if c.Sym == target {
+				im = c
+			}
+		}
+		if im == nil {
+			start = i + 1
+			continue
+		}
+
+		cs := &CallSite{Caller: fn.Sym, Pos: in.Pos(), Target: target}
+		note := func(in disasm.Inst, s string) string {
+			return fmt.Sprintf("%-52s // %s", listing(in), s)
+		}
+		if abi0 != nil {
+			cs.Setup = append(cs.Setup, "(direct ABI0 call: arguments are stored at the top of the caller's frame)")
+			for _, prev := range fn.Insts[start:i] {
+				if off, ok := spOffset(written(prev)); ok {
+					if v := abi0.At(off); v != nil && !v.Result {
+						cs.Setup = append(cs.Setup, note(prev, v.String()))
+					}
+				}
+			}
+		} else {
+			// 泛型 shape 实例多一个字典参数，按调用的具体实现分配
+			if im.Assign != nil {
+				a = im.Assign
+			}
+			regs := make(map[string]string)
+			for _, s := range a.Params {
+				for j, r := range s.Regs {
+					regs[r] = s.Parts[j]
+				}
+			}
+			var setup []string
+			for j := i - 1; j >= start && len(regs) > 0; j-- {
+				w := written(fn.Insts[j])
+				if name, ok := regs[w]; ok {
+					setup = append(setup, note(fn.Insts[j], w+"="+name))
+					delete(regs, w)
+				}
+			}
+			for j := len(setup) - 1; j >= 0; j-- {
+				cs.Setup = append(cs.Setup, setup[j])
+			}
+		}
+		sites = append(sites, cs)
+		start = i + 1
+	}
+	return sites
+}
+
+// listing 清单中的一行：位置和指令
+func listing(in disasm.Inst) string {
+	return fmt.Sprintf("%-20s %s", in.Pos(), in)
+}
+
+// spOffset 解析 objdump 中 0x8(SP) 形式的操作数
+func spOffset(operand string) (int, bool) {
+	s, ok := strings.CutSuffix(operand, "(SP)")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(s, 0, 64)
+	return int(n), err == nil
+}
+
+// String 完整的报告
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n", r.Func, r.Sig)
+	for _, im := range r.Impls {
+		fmt.Fprintf(&b, "\nTEXT %s  [%s] %s\n", im.Sym, im.Kind, filepath.Base(im.File))
+		if im.Note != "" {
+			fmt.Fprintf(&b, "  %s\n", im.Note)
+		}
+		if im.Assign != nil {
+			b.WriteString(im.Assign.String())
+		}
+		for _, m := range im.Moves {
+			fmt.Fprintf(&b, "  %s\n", m)
+		}
+		for _, l := range im.Listing {
+			fmt.Fprintf(&b, "    %s\n", l)
+		}
+	}
+	for _, c := range r.Callers {
+		fmt.Fprintf(&b, "\ncall from %s at %s to %s\n", c.Caller, c.Pos, c.Target)
+		for _, l := range c.Setup {
+			fmt.Fprintf(&b, "    %s\n", l)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package abiinspect
+
+import (
+	"strings"
+
+	"yuhen/internal/disasm"
+)
+
+// 只读取操作数的指令，最后一个操作数不是写入目标
+var readOnlyOps = []string{"CMP", "TEST", "BT", "UCOMIS", "COMIS", "PTEST", "J", "CALL", "PUSH"}
+
+// written 指令写入的寄存器（最后一个操作数）
+func written(in disasm.Inst) string {
+	if len(in.Args) == 0 {
+		return ""
+	}
+	for _, p := range readOnlyOps {
+		if strings.HasPrefix(in.Op, p) {
+			return ""
+		}
+	}
+	return in.Args[len(in.Args)-1]
+}
+
+// reads 指令是否读取寄存器 reg，包括内存操作数中的基址和索引寄存器
+func reads(in disasm.Inst, reg string) bool {
+	for i, a := range in.Args {
+		if a == reg {
+			// 两操作数指令的目标同时也是源，MOV 和 LEA 除外
+			if i == len(in.Args)-1 && len(in.Args) > 1 && (strings.HasPrefix(in.Op, "MOV") || strings.HasPrefix(in.Op, "LEA")) {
+				continue
+			}
+			return true
+		}
+		if strings.Contains(a, "("+reg+")") || strings.Contains(a, "("+reg+"*") {
+			return true
+		}
+	}
+	return false
+}
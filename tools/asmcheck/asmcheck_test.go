@@ -0,0 +1,137 @@
+package asmcheck
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func check(t *testing.T, dir string, tags ...string) []string {
+	t.Helper()
+	probs, err := CheckDir(dir, "linux", "amd64", tags...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range probs {
+		p.File = filepath.Base(p.File)
+		got = append(got, p.String())
+	}
+	return got
+}
+
+func diff(t *testing.T, got, want []string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// type 包中的汇编是课程里的反例，每一处猜测的偏移都应被指出
+func TestTypePackage(t *testing.T) {
+	diff(t, check(t, "../../type"), []string{
+		"output.s:6: output_o: wrong argument size 8; expected $...-24",
+		"output.s:13: output_o: write to ret+16(FP) outside declared argument frame ($...-8)",
+		"stack_te.s:6: output: wrong argument size 48; expected $...-32",
+		"stack_te.s:7: output: 24(SP) reads outside the 8-byte local frame: hardware SP, this is the caller's frame; use arg+0(FP)",
+		"stack_te.s:8: output: unknown variable ret3; offset 24 is ret2+24(FP)",
+		"stack_te.s:9: output: perhapsArg1+16(SP) reads outside the 8-byte local frame: pseudo SP points to the top of the frame, this is the caller's frame; use arg+0(FP)",
+		"stack_te.s:10: output: invalid offset ret2+16(FP); expected ret2+24(FP)",
+		"stack_te.s:11: output: unknown variable arg1; offset 0 is arg+0(FP)",
+	})
+
+	// purego 下没有汇编文件参与构建
+	diff(t, check(t, "../../type", "purego"), nil)
+}
+
+func TestSIMDPackage(t *testing.T) {
+	diff(t, check(t, "../../data/simd"), nil)
+}
+
+func TestLayout(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "p.go", `package p
+
+func f(s string, b []byte, x int32, c complex128, e any, r error) (n int8, ok bool)
+`)
+	p, err := Load(dir, "linux", "amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 返回值从 96 开始，总大小 98 按指针大小取整为 104
+	want := `f: args 104
+	s_base+0(FP)         8 bytes string base
+	s_len+8(FP)          8 bytes string len
+	b_base+16(FP)        8 bytes slice base
+	b_len+24(FP)         8 bytes slice len
+	b_cap+32(FP)         8 bytes slice cap
+	x+40(FP)             4 bytes int32
+	c_real+48(FP)        8 bytes real(complex128)
+	c_imag+56(FP)        8 bytes imag(complex128)
+	e_type+64(FP)        8 bytes interface type
+	e_data+72(FP)        8 bytes interface data
+	r_itable+80(FP)      8 bytes interface itable
+	r_data+88(FP)        8 bytes interface data
+	n+96(FP)             1 bytes int8
+	ok+97(FP)            1 bytes bool
+`
+	if got := p.Layouts["f"].String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSynthetic(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "p.go", `package p
+
+func callee(a, b, c int) int
+
+func small(x int32) (y int32)
+
+func noret(x int) int
+
+func local(s string) int
+`)
+	write(t, dir, "p_amd64.s", `#include "textflag.h"
+
+TEXT ·callee(SB), NOSPLIT, $0-32
+	MOVQ a+0(FP), AX
+	ADDQ b+8(FP), AX
+	ADDQ c+16(FP), AX
+	MOVQ AX, ret+24(FP)
+	RET
+
+TEXT ·small(SB), NOSPLIT, $16-16
+	MOVQ x+0(FP), AX // 4 字节的参数
+	CALL ·callee(SB)
+	MOVL AX, y+8(FP); RET
+
+TEXT ·noret(SB), NOSPLIT, $0-16
+	MOVQ x+0(FP), AX
+	RET
+
+TEXT ·local(SB), NOSPLIT, $16
+	MOVQ s_base+0(FP), AX
+	MOVQ AX, t-8(SP)
+	MOVQ AX, u-24(SP)
+	MOVQ s_len+8(FP), AX
+	MOVQ AX, 8(SP)
+	MOVQ AX, ret+16(FP)
+	RET
+`)
+	diff(t, check(t, dir), []string{
+		"p_amd64.s:11: small: invalid MOVQ of x+0(FP); int32 is 4-byte value",
+		"p_amd64.s:12: small: frame size 16 too small for call to callee (needs 32 bytes of arguments)",
+		"p_amd64.s:15: noret: RET without writing to result ret+8(FP)",
+		"p_amd64.s:19: local: missing argument size; expected $16-24",
+		"p_amd64.s:22: local: u-24(SP) writes below the 16-byte local frame",
+	})
+}
+
+func write(t *testing.T, dir, name, src string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,474 @@
+package asmcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Problem 一条检查结果
+type Problem struct {
+	File string
+	Line int
+	Func string
+	Msg  string
+}
+
+func (p Problem) String() string {
+	if p.Func == "" {
+		return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", p.File, p.Line, p.Func, p.Msg)
+}
+
+// Package 一个包中参与构建的 Go 声明和汇编文件
+type Package struct {
+	Dir     string
+	Arch    *Arch
+	Layouts map[string]*Layout // 包内所有顶层函数，包括有函数体的（汇编中 CALL 的目标）
+	Asm     map[string]bool    // 没有函数体、需要汇编实现的函数
+	SFiles  []string
+}
+
+// Load 按 GOOS/GOARCH 和构建标签选出参与构建的文件，计算所有函数的布局
+func Load(dir, goos, goarch string, tags ...string) (*Package, error) {
+	arch := arches[goarch]
+	if arch == nil {
+		return nil, fmt.Errorf("asmcheck: unsupported GOARCH %s", goarch)
+	}
+
+	ctxt := build.Default
+	ctxt.GOOS, ctxt.GOARCH = goos, goarch
+	ctxt.BuildTags = tags
+	ctxt.CgoEnabled = false
+	bp, err := ctxt.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	// 只需要签名中的类型，函数体里的错误忽略
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	conf := types.Config{
+		Importer: importer.Default(),
+		Sizes:    arch.Sizes,
+		Error:    func(error) {},
+	}
+	conf.Check(bp.ImportPath, fset, files, info)
+
+	p := &Package{
+		Dir:     dir,
+		Arch:    arch,
+		Layouts: make(map[string]*Layout),
+		Asm:     make(map[string]bool),
+	}
+	for _, f := range files {
+		for _, d := range f.Decls {
+			fd, ok := d.(*ast.FuncDecl)
+			if !ok || fd.Recv != nil {
+				continue
+			}
+			p.Layouts[fd.Name.Name] = NewLayout(arch, fd, info)
+			if fd.Body == nil {
+				p.Asm[fd.Name.Name] = true
+			}
+		}
+	}
+	for _, s := range bp.SFiles {
+		p.SFiles = append(p.SFiles, filepath.Join(dir, s))
+	}
+	return p, nil
+}
+
+// CheckDir 加载并检查 dir 中的全部汇编文件
+func CheckDir(dir, goos, goarch string, tags ...string) ([]Problem, error) {
+	p, err := Load(dir, goos, goarch, tags...)
+	if err != nil {
+		return nil, err
+	}
+	var all []Problem
+	for _, s := range p.SFiles {
+		src, err := os.ReadFile(s)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, p.Check(s, string(src))...)
+	}
+	return all, nil
+}
+
+var (
+	// TEXT ·name(SB), FLAGS, $frame-args
+	textRE = regexp.MustCompile(`^TEXT\s+[\w./]*·(\w+)(?:<\w+>)?\(SB\)\s*(?:,\s*([^,$]*?)\s*)?(?:,\s*\$(-?\d+)(?:-(\d+))?)?\s*$`)
+	// name+off(FP)、off(SP)、name-off(SP)
+	memRE  = regexp.MustCompile(`^(?:([A-Za-z_]\w*)([+-]\d+)|(-?\d+))?\((FP|SP)\)$`)
+	callRE = regexp.MustCompile(`^[\w./]*·(\w+)(?:<\w+>)?\(SB\)$`)
+)
+
+// 指令的内存操作数宽度，只检查含义明确的 MOV 系列
+var widths = map[string]int{
+	"MOVB": 1, "MOVBLZX": 1, "MOVBQZX": 1, "MOVBLSX": 1, "MOVBQSX": 1,
+	"MOVW": 2, "MOVWLZX": 2, "MOVWQZX": 2, "MOVWLSX": 2, "MOVWQSX": 2,
+	"MOVL": 4, "MOVLQZX": 4, "MOVLQSX": 4, "MOVSS": 4,
+	"MOVQ": 8, "MOVSD": 8,
+	"MOVOU": 16, "MOVO": 16, "MOVUPD": 16, "MOVAPD": 16, "MOVUPS": 16, "MOVAPS": 16,
+}
+
+// 只读取操作数的指令，最后一个操作数不是写入目标
+func readOnly(op string) bool {
+	for _, p := range []string{"CMP", "TEST", "BT", "UCOMIS", "COMIS", "PTEST", "VPTEST"} {
+		if strings.HasPrefix(op, p) {
+			return true
+		}
+	}
+	return false
+}
+
+type textFunc struct {
+	name     string
+	line     int
+	frame    int
+	layout   *Layout
+	declared int // TEXT 中声明的参数大小，-1 表示未声明
+	wrote    map[*Var]bool
+	hasRET   bool
+}
+
+// Check 检查一个汇编文件
+func (p *Package) Check(file, src string) []Problem {
+	var probs []Problem
+	var fn *textFunc
+
+	report := func(line int, format string, args ...any) {
+		name := ""
+		if fn != nil {
+			name = fn.name
+		}
+		probs = append(probs, Problem{File: file, Line: line, Func: name, Msg: fmt.Sprintf(format, args...)})
+	}
+	finish := func() {
+		if fn == nil || fn.layout == nil || !fn.hasRET {
+			return
+		}
+		// 有返回值却从未写入，返回的是栈上残留的数据
+		var results []*Var
+		for _, v := range fn.layout.Vars {
+			if v.Result && !v.Composite {
+				results = append(results, v)
+			}
+		}
+		if len(results) == 0 {
+			return
+		}
+		for _, v := range results {
+			if fn.wrote[v] {
+				return
+			}
+		}
+		probs = append(probs, Problem{File: file, Line: fn.line, Func: fn.name,
+			Msg: fmt.Sprintf("RET without writing to result %s", results[0])})
+	}
+
+	for i, raw := range strings.Split(src, "\n") {
+		lineno := i + 1
+		for _, stmt := range strings.Split(stripComment(raw), ";") {
+			stmt = strings.TrimSpace(stmt)
+			// 标签
+			if j := strings.Index(stmt, ":"); j > 0 && isIdent(stmt[:j]) {
+				stmt = strings.TrimSpace(stmt[j+1:])
+			}
+			if stmt == "" || strings.HasPrefix(stmt, "#") {
+				continue
+			}
+
+			if strings.HasPrefix(stmt, "TEXT") {
+				finish()
+				var msgs []string
+				fn, msgs = p.text(stmt, lineno)
+				for _, m := range msgs {
+					report(lineno, "%s", m)
+				}
+				continue
+			}
+			if fn == nil || fn.layout == nil {
+				continue
+			}
+			p.instruction(fn, stmt, lineno, report)
+		}
+	}
+	finish()
+	return probs
+}
+
+// text 解析 TEXT 行，返回的问题由调用方在切换到新函数后报告
+func (p *Package) text(stmt string, line int) (*textFunc, []string) {
+	m := textRE.FindStringSubmatch(stmt)
+	if m == nil {
+		return nil, nil
+	}
+	fn := &textFunc{name: m[1], line: line, declared: -1, wrote: make(map[*Var]bool)}
+	if m[3] != "" {
+		fn.frame, _ = strconv.Atoi(m[3])
+	}
+	if m[4] != "" {
+		fn.declared, _ = strconv.Atoi(m[4])
+	}
+
+	if !p.Asm[fn.name] {
+		if p.Layouts[fn.name] != nil {
+			return fn, []string{"function has a Go body and an assembly implementation"}
+		}
+		return fn, []string{"function missing Go declaration"}
+	}
+	fn.layout = p.Layouts[fn.name]
+
+	switch {
+	case fn.declared < 0:
+		return fn, []string{fmt.Sprintf("missing argument size; expected $%d-%d", fn.frame, fn.layout.ArgSize)}
+	case fn.declared != fn.layout.ArgSize:
+		return fn, []string{fmt.Sprintf("wrong argument size %d; expected $...-%d", fn.declared, fn.layout.ArgSize)}
+	}
+	return fn, nil
+}
+
+func (p *Package) instruction(fn *textFunc, stmt string, line int, report func(int, string, ...any)) {
+	op, rest := stmt, ""
+	if i := strings.IndexAny(stmt, " \t"); i >= 0 {
+		op, rest = stmt[:i], stmt[i+1:]
+	}
+	operands := splitOperands(rest)
+
+	switch op {
+	case "RET":
+		fn.hasRET = true
+		return
+	case "CALL":
+		if len(operands) == 1 {
+			if m := callRE.FindStringSubmatch(operands[0]); m != nil {
+				if callee := p.Layouts[m[1]]; callee != nil && fn.frame < callee.ArgSize {
+					report(line, "frame size %d too small for call to %s (needs %d bytes of arguments)",
+						fn.frame, m[1], callee.ArgSize)
+				}
+			}
+		}
+		return
+	}
+
+	width := widths[op]
+	for i, o := range operands {
+		m := memRE.FindStringSubmatch(o)
+		if m == nil {
+			continue
+		}
+		write := i == len(operands)-1 && len(operands) > 1 && !readOnly(op)
+		name, reg := m[1], m[4]
+		off := 0
+		if name != "" {
+			off, _ = strconv.Atoi(m[2])
+		} else if m[3] != "" {
+			off, _ = strconv.Atoi(m[3])
+		}
+
+		switch {
+		case reg == "FP":
+			p.checkFP(fn, op, o, name, off, width, write, line, report)
+		case name == "":
+			p.checkHardwareSP(fn, o, off, width, write, line, report)
+		default:
+			p.checkPseudoSP(fn, o, off, width, write, line, report)
+		}
+	}
+}
+
+func (p *Package) checkFP(fn *textFunc, op, o, name string, off, width int, write bool, line int, report func(int, string, ...any)) {
+	l := fn.layout
+	if name == "" {
+		report(line, "use of %s without a variable name", o)
+		return
+	}
+
+	v := l.byName[name]
+	at := l.At(off)
+	switch {
+	case v == nil && at != nil:
+		report(line, "unknown variable %s; offset %d is %s", name, off, at)
+	case v == nil:
+		report(line, "unknown variable %s; argument frame is %d bytes", name, l.ArgSize)
+	case v.Off != off:
+		report(line, "invalid offset %s; expected %s", o, v)
+	case v.Composite:
+		report(line, "invalid %s of %s; %s is a %d-byte value, use its components", op, o, v.Type, v.Size)
+	case width != 0 && width != v.Size:
+		report(line, "invalid %s of %s; %s is %d-byte value", op, o, v.Type, v.Size)
+	}
+
+	if write {
+		if at != nil {
+			fn.wrote[at] = true
+		}
+		w := width
+		if w == 0 {
+			w = 1
+		}
+		if fn.declared >= 0 && off+w > fn.declared {
+			report(line, "write to %s outside declared argument frame ($...-%d)", o, fn.declared)
+		}
+	}
+}
+
+// extra 局部帧之上、参数区之下的字节数：返回地址，以及帧大小大于 0 时保存的 BP
+func (p *Package) extra(fn *textFunc) int {
+	n := p.Arch.PtrSize
+	if p.Arch.FramePointer && fn.frame > 0 {
+		n += p.Arch.PtrSize
+	}
+	return n
+}
+
+// callerFrame 描述硬件/伪 SP 越过局部帧之后实际访问的位置
+func (p *Package) callerFrame(fn *textFunc, fpOff int) string {
+	if fpOff < 0 {
+		if fpOff >= -p.Arch.PtrSize {
+			return "the return address"
+		}
+		return "the saved frame pointer"
+	}
+	if v := fn.layout.At(fpOff); v != nil {
+		if fpOff == v.Off {
+			return fmt.Sprintf("the caller's frame; use %s", v)
+		}
+		return fmt.Sprintf("the caller's frame, inside %s", v)
+	}
+	return fmt.Sprintf("the caller's frame at %d(FP), beyond the %d-byte argument frame", fpOff, fn.layout.ArgSize)
+}
+
+func access(write bool) string {
+	if write {
+		return "writes"
+	}
+	return "reads"
+}
+
+// checkHardwareSP off(SP) 是硬件 SP，局部帧为 [0, frame)
+func (p *Package) checkHardwareSP(fn *textFunc, o string, off, width int, write bool, line int, report func(int, string, ...any)) {
+	w := max(width, 1)
+	switch {
+	case off < 0:
+		report(line, "%s %s below the stack pointer", o, access(write))
+	case off+w <= fn.frame:
+		// 局部变量或者被调函数的参数区
+	case off < fn.frame:
+		report(line, "%s crosses the top of the %d-byte local frame", o, fn.frame)
+	default:
+		report(line, "%s %s outside the %d-byte local frame: hardware SP, this is %s",
+			o, access(write), fn.frame, p.callerFrame(fn, off-fn.frame-p.extra(fn)))
+	}
+	if write {
+		p.markWrite(fn, off-fn.frame-p.extra(fn))
+	}
+}
+
+// checkPseudoSP name-off(SP) 是伪 SP，指向局部帧顶部，局部变量为 [-frame, 0)
+func (p *Package) checkPseudoSP(fn *textFunc, o string, off, width int, write bool, line int, report func(int, string, ...any)) {
+	w := max(width, 1)
+	switch {
+	case off < -fn.frame:
+		report(line, "%s %s below the %d-byte local frame", o, access(write), fn.frame)
+	case off+w <= 0:
+	case off < 0:
+		report(line, "%s crosses the top of the %d-byte local frame", o, fn.frame)
+	default:
+		report(line, "%s %s outside the %d-byte local frame: pseudo SP points to the top of the frame, this is %s",
+			o, access(write), fn.frame, p.callerFrame(fn, off-p.extra(fn)))
+	}
+	if write {
+		p.markWrite(fn, off-p.extra(fn))
+	}
+}
+
+func (p *Package) markWrite(fn *textFunc, fpOff int) {
+	if v := fn.layout.At(fpOff); v != nil {
+		fn.wrote[v] = true
+	}
+}
+
+func stripComment(s string) string {
+	if i := strings.Index(s, "//"); i >= 0 {
+		s = s[:i]
+	}
+	for {
+		i := strings.Index(s, "/*")
+		if i < 0 {
+			return s
+		}
+		j := strings.Index(s[i:], "*/")
+		if j < 0 {
+			return s[:i]
+		}
+		s = s[:i] + s[i+j+2:]
+	}
+}
+
+func splitOperands(s string) []string {
+	var out []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				out = append(out, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if t := strings.TrimSpace(s[start:]); t != "" {
+		out = append(out, t)
+	}
+	return out
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || i > 0 && r >= '0' && r <= '9' {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// SortedLayouts 汇编函数的布局，按名字排序
+func (p *Package) SortedLayouts() []*Layout {
+	var ls []*Layout
+	for name := range p.Asm {
+		ls = append(ls, p.Layouts[name])
+	}
+	sort.Slice(ls, func(i, j int) bool { return ls[i].Func < ls[j].Func })
+	return ls
+}
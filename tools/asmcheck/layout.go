@@ -0,0 +1,202 @@
+// Package asmcheck 对照 Go 函数声明检查汇编中的帧大小和参数偏移
+//
+// type/output.s 通过硬件 SP 读取调用方的栈帧，stack_te.s 的注释靠猜偏移，
+// 这里按 ABI0 的规则计算每个参数、返回值的名字、偏移和大小，然后逐条检查汇编中的引用。
+// 命名规则和 go vet 的 asmdecl 一致：未命名参数为 arg arg1 ...，未命名返回值为 ret ret1 ...，
+// 切片拆为 _base _len _cap，字符串拆为 _base _len，接口拆为 _type(_itable) _data
+package asmcheck
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// Arch 目标平台的布局参数
+type Arch struct {
+	Name     string
+	PtrSize  int
+	IntSize  int
+	MaxAlign int
+	Sizes    types.Sizes
+	// 帧大小大于 0 时汇编器会额外保存 BP（amd64）
+	FramePointer bool
+}
+
+var arches = map[string]*Arch{
+	"amd64": {Name: "amd64", PtrSize: 8, IntSize: 8, MaxAlign: 8, Sizes: types.SizesFor("gc", "amd64"), FramePointer: true},
+}
+
+// LookupArch 返回 goarch 的布局参数，不支持时返回 nil
+func LookupArch(goarch string) *Arch {
+	return arches[goarch]
+}
+
+// Var 参数或返回值中可以在汇编里引用的一个变量
+type Var struct {
+	Name      string
+	Off, Size int
+	Type      string
+	Result    bool
+	Composite bool // 切片、字符串等，只能引用其分量
+}
+
+func (v *Var) String() string {
+	return fmt.Sprintf("%s+%d(FP)", v.Name, v.Off)
+}
+
+// Layout 一个函数的 ABI0 参数布局
+type Layout struct {
+	Func    string
+	Vars    []*Var // 按偏移排序
+	ArgSize int    // 参数和返回值的总大小，即 TEXT 中 $frame-args 的 args
+	byName  map[string]*Var
+}
+
+// At 偏移 off 处的基本变量
+func (l *Layout) At(off int) *Var {
+	for _, v := range l.Vars {
+		if !v.Composite && v.Off <= off && off < v.Off+v.Size {
+			return v
+		}
+	}
+	return nil
+}
+
+func (l *Layout) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: args %d\n", l.Func, l.ArgSize)
+	for _, v := range l.Vars {
+		if v.Composite {
+			continue
+		}
+		fmt.Fprintf(&b, "\t%-20s %d bytes %s\n", v, v.Size, v.Type)
+	}
+	return b.String()
+}
+
+// NewLayout 根据函数签名计算布局
+func NewLayout(arch *Arch, decl *ast.FuncDecl, info *types.Info) *Layout {
+	l := &Layout{Func: decl.Name.Name, byName: make(map[string]*Var)}
+	off := 0
+
+	add := func(fields *ast.FieldList, result bool) {
+		argnum := 0
+		for _, f := range fields.List {
+			t := info.TypeOf(f.Type)
+			if t == nil {
+				if ell, ok := f.Type.(*ast.Ellipsis); ok {
+					t = types.NewSlice(info.TypeOf(ell.Elt))
+				}
+			}
+			if t == nil {
+				t = types.Typ[types.Invalid]
+			}
+			align := int(arch.Sizes.Alignof(t))
+			size := int(arch.Sizes.Sizeof(t))
+			off += -off & (align - 1)
+
+			var names []string
+			for _, id := range f.Names {
+				names = append(names, id.Name)
+			}
+			if len(names) == 0 {
+				name := "arg"
+				if result {
+					name = "ret"
+				}
+				if argnum > 0 {
+					name += strconv.Itoa(argnum)
+				}
+				names = []string{name}
+			}
+			argnum += len(names)
+
+			for _, name := range names {
+				l.components(arch, name, t, off, result)
+				off += size
+			}
+		}
+	}
+
+	add(decl.Type.Params, false)
+	if decl.Type.Results != nil && len(decl.Type.Results.List) > 0 {
+		// 返回值从按最大对齐取整的位置开始
+		off += -off & (arch.MaxAlign - 1)
+		add(decl.Type.Results, true)
+	}
+	l.ArgSize = off + -off&(arch.PtrSize-1)
+	return l
+}
+
+func (l *Layout) addVar(v *Var) {
+	l.Vars = append(l.Vars, v)
+	l.byName[v.Name] = v
+}
+
+func (l *Layout) components(arch *Arch, name string, t types.Type, off int, result bool) {
+	size := int(arch.Sizes.Sizeof(t))
+	add := func(suffix string, off, size int, typ string) {
+		l.addVar(&Var{Name: name + suffix, Off: off, Size: size, Type: typ, Result: result})
+	}
+	composite := func() {
+		l.addVar(&Var{Name: name, Off: off, Size: size, Type: t.String(), Result: result, Composite: true})
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Kind() == types.String:
+			composite()
+			add("_base", off, arch.PtrSize, "string base")
+			add("_len", off+arch.PtrSize, arch.IntSize, "string len")
+			return
+		case u.Info()&types.IsComplex != 0:
+			composite()
+			add("_real", off, size/2, fmt.Sprintf("real(%s)", t))
+			add("_imag", off+size/2, size/2, fmt.Sprintf("imag(%s)", t))
+			return
+		}
+	case *types.Slice:
+		composite()
+		add("_base", off, arch.PtrSize, "slice base")
+		add("_len", off+arch.PtrSize, arch.IntSize, "slice len")
+		add("_cap", off+arch.PtrSize+arch.IntSize, arch.IntSize, "slice cap")
+		return
+	case *types.Interface:
+		composite()
+		if u.Empty() {
+			add("_type", off, arch.PtrSize, "interface type")
+		} else {
+			add("_itable", off, arch.PtrSize, "interface itable")
+		}
+		add("_data", off+arch.PtrSize, arch.PtrSize, "interface data")
+		return
+	case *types.Struct:
+		composite()
+		offs := arch.Sizes.Offsetsof(fieldsOf(u))
+		for i := 0; i < u.NumFields(); i++ {
+			f := u.Field(i)
+			l.components(arch, name+"_"+f.Name(), f.Type(), off+int(offs[i]), result)
+		}
+		return
+	case *types.Array:
+		composite()
+		es := int(arch.Sizes.Sizeof(u.Elem()))
+		for i := 0; i < int(u.Len()); i++ {
+			l.components(arch, name+"_"+strconv.Itoa(i), u.Elem(), off+i*es, result)
+		}
+		return
+	}
+	add("", off, size, t.String())
+}
+
+func fieldsOf(s *types.Struct) []*types.Var {
+	fs := make([]*types.Var, s.NumFields())
+	for i := range fs {
+		fs[i] = s.Field(i)
+	}
+	return fs
+}
@@ -0,0 +1,42 @@
+// abiinspect 编译包并注解函数在 ABIInternal 下的寄存器分配、ABI 包装函数和调用点
+//
+//	go install -C tools ./cmd/abiinspect
+//	abiinspect -func add_o ./type
+//	abiinspect -func stringParam .
+//	abiinspect -func Max ./data
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"yuhen/tools/abiinspect"
+)
+
+func main() {
+	fn := flag.String("func", "", "top-level function to inspect")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: abiinspect -func name [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("abiinspect: ")
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	if *fn == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	r, err := abiinspect.Inspect(dir, *fn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(r)
+}
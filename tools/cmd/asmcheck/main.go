@@ -0,0 +1,66 @@
+// asmcheck 对照 Go 声明检查汇编函数的参数大小、FP/SP 偏移和帧大小
+//
+//	go install -C tools ./cmd/asmcheck
+//	asmcheck ./type
+//	asmcheck -layout ./type
+//	asmcheck -goarch amd64 -tags purego ./data/simd
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+
+	"yuhen/tools/asmcheck"
+)
+
+func main() {
+	goos := flag.String("goos", runtime.GOOS, "target GOOS")
+	goarch := flag.String("goarch", "amd64", "target GOARCH")
+	tags := flag.String("tags", "", "comma-separated build tags")
+	layout := flag.Bool("layout", false, "print the argument layout of each assembly function")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: asmcheck [flags] [dir...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("asmcheck: ")
+
+	dirs := flag.Args()
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	var ts []string
+	if *tags != "" {
+		ts = strings.Split(*tags, ",")
+	}
+
+	failed := false
+	for _, dir := range dirs {
+		if *layout {
+			p, err := asmcheck.Load(dir, *goos, *goarch, ts...)
+			if err != nil {
+				log.Fatal(err)
+			}
+			for _, l := range p.SortedLayouts() {
+				fmt.Print(l)
+			}
+			continue
+		}
+		probs, err := asmcheck.CheckDir(dir, *goos, *goarch, ts...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, p := range probs {
+			fmt.Println(p)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,40 @@
+// defertrace 输出函数中 defer 的注册、参数求值以及返回值变化的时间线
+//
+//	go install -C tools ./cmd/defertrace
+//	defertrace -func bibao1 ./fun
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"yuhen/tools/defertrace"
+)
+
+func main() {
+	fn := flag.String("func", "", "function to trace (top-level, no parameters)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: defertrace -func name [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("defertrace: ")
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	if *fn == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	t, err := defertrace.Run(dir, *fn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(t)
+}
@@ -0,0 +1,84 @@
+// escapediff 比较两个 git 版本的逃逸分析结论，列出新增（+）和消失（-）的堆分配
+//
+//	go install -C tools ./cmd/escapediff
+//	escapediff HEAD~1 ./data        # HEAD~1 和工作区
+//	escapediff -new v2 v1 ./...
+//	escapediff HEAD~1 yuhen/data std
+//	escapediff -all -gcflags=-l HEAD ./data
+//
+// 每个版本检出到临时的 git worktree 中编译，结论按文件、函数和表达式比较，不受行号变化影响
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"yuhen/internal/escape"
+	"yuhen/internal/source"
+)
+
+func main() {
+	gcflags := flag.String("gcflags", "", "extra compiler flags, e.g. -l")
+	all := flag.Bool("all", false, "also report \"does not escape\" and leaking params")
+	newRev := flag.String("new", "", "new `revision`, default the working tree")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: escapediff [flags] old [packages]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("escapediff: ")
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	// 其余参数都是包，新版本只能用 -new 指定，避免把 std、yuhen/data 当成版本
+	oldRev := args[0]
+	args = args[1:]
+
+	root, err := source.ModuleRoot(".")
+	if err != nil {
+		log.Fatal(err)
+	}
+	a, err := report(root, oldRev, *gcflags, args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b, err := report(root, *newRev, *gcflags, args)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	removed, added := escape.Diff(a, b)
+	for _, d := range removed {
+		if *all || heap(d) {
+			fmt.Printf("- %s\t%s\n", d, d.Func)
+		}
+	}
+	for _, d := range added {
+		if *all || heap(d) {
+			fmt.Printf("+ %s\t%s\n", d, d.Func)
+		}
+	}
+}
+
+func heap(d escape.Diag) bool {
+	return d.Kind == escape.MovedToHeap || d.Kind == escape.Escapes
+}
+
+// report 编译版本 rev 的模块，rev 为空时使用工作区
+func report(root, rev, gcflags string, patterns []string) (*escape.Report, error) {
+	if rev == "" {
+		return escape.Run(root, gcflags, patterns...)
+	}
+	dir, cleanup, err := source.Checkout(root, rev)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	return escape.Run(dir, gcflags, patterns...)
+}
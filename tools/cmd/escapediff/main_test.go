@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"yuhen/internal/escape"
+)
+
+const (
+	before = "package p\n\nfunc F() int {\n\tx := 1\n\treturn x\n}\n"
+	after  = "package p\n\nvar sink *int\n\nfunc F() int {\n\tx := 1\n\tsink = &x\n\treturn x\n}\n"
+)
+
+// 模块放在仓库的子目录中，检出后要找回对应的模块目录
+func TestReportCheckout(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds with the go command")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip(err)
+	}
+	repo := t.TempDir()
+	root := filepath.Join(repo, "mod")
+	write(t, filepath.Join(root, "go.mod"), "module p\n\ngo 1.20\n")
+	write(t, filepath.Join(root, "p.go"), before)
+	run(t, repo, "git", "init", "-q")
+	run(t, repo, "git", "add", ".")
+	run(t, repo, "git", "-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "-m", "stack")
+	// 工作区中 x 逃逸，行号也变了
+	write(t, filepath.Join(root, "p.go"), after)
+
+	a, err := report(root, "HEAD", "", []string{"."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := report(root, "", "", []string{"."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	removed, added := escape.Diff(a, b)
+	if len(removed) != 0 {
+		t.Errorf("removed %v", removed)
+	}
+	var got []string
+	for _, d := range added {
+		if heap(d) {
+			got = append(got, d.Func+": "+d.String())
+		}
+	}
+	if len(got) != 1 || !strings.HasPrefix(got[0], "F: p.go:6:") || !strings.HasSuffix(got[0], "moved to heap: x") {
+		t.Errorf("added %v", got)
+	}
+
+	// 临时 worktree 已经删除
+	if out := run(t, repo, "git", "worktree", "list"); strings.Count(out, "\n") != 1 {
+		t.Errorf("worktrees left:\n%s", out)
+	}
+	if _, err := report(root, "nosuchrev", "", []string{"."}); err == nil {
+		t.Error("bad revision accepted")
+	}
+}
+
+func write(t *testing.T, name, s string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(s), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func run(t *testing.T, dir, name string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s %s: %v\n%s", name, strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
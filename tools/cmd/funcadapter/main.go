@@ -0,0 +1,50 @@
+// funcadapter 为接口生成函数适配器：单方法接口生成 XxxFunc 函数类型，多方法接口生成由函数字段组成的 XxxFuncs
+//
+//	go install -C tools ./cmd/funcadapter
+//
+// 在包中用 go generate 调用，工作目录即包目录：
+//
+//	//go:generate funcadapter -o adapter_gen.go Ner X1er fmt.Stringer
+//	//go:generate funcadapter -pkg fakes -struct Fake%s -o ../fakes/ner.go Ner
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"yuhen/tools/funcadapter"
+)
+
+func main() {
+	var cfg funcadapter.Config
+	out := flag.String("o", "", "output `file`, default stdout")
+	flag.StringVar(&cfg.Package, "pkg", "", "output package `name`, default the package in the current directory")
+	flag.StringVar(&cfg.Func, "func", "%sFunc", "type name `template` for single-method interfaces")
+	flag.StringVar(&cfg.Struct, "struct", "%sFuncs", "type name `template` for multi-method interfaces")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: funcadapter [flags] interface...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0) // 库返回的错误已经带有 funcadapter: 前缀
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg.Output = *out
+	src, err := funcadapter.Generate(".", cfg, flag.Args()...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatalf("funcadapter: %v", err)
+	}
+}
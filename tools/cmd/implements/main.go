@@ -0,0 +1,60 @@
+// implements 列出实现了接口的具名类型，以及只差一个方法或接收者不对的类型
+//
+//	go install -C tools ./cmd/implements
+//	implements Ner ./data
+//	implements fmt.Stringer ./...
+//	implements -gen ner_assert.go Ner ./data
+//
+// -gen 在每个有实现者的包中生成 var _ I = (*T)(nil) 形式的断言文件
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"yuhen/tools/implements"
+)
+
+func main() {
+	gen := flag.String("gen", "", "write assertions for the implementations to `file` in each package")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: implements [-gen file] interface [packages]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("implements: ")
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	patterns := flag.Args()[1:]
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	r, err := implements.Find(".", flag.Arg(0), patterns...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(r)
+
+	if *gen == "" {
+		return
+	}
+	for _, pkg := range r.Packages() {
+		src, err := r.Assertions(pkg, "implements")
+		if err != nil {
+			log.Fatal(err)
+		}
+		file := filepath.Join(r.Dir(pkg), *gen)
+		if err := os.WriteFile(file, src, 0o644); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("wrote", file)
+	}
+}
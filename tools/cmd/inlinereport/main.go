@@ -0,0 +1,90 @@
+// inlinereport 按包和函数列出内联决策（开销、原因）、内联的调用和剩余的边界检查
+//
+//	go install -C tools ./cmd/inlinereport
+//	inlinereport ./data
+//	inlinereport -func 'LoopQueue' ./data
+//	inlinereport -diff HEAD~1 ./data            # HEAD~1 和工作区
+//	inlinereport -diff v1 -new v2 ./...
+//
+// 比较模式按文件和函数对比，忽略行号变化；有差异时退出码为 1，可用于检查热点函数的退化
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+
+	"yuhen/internal/inline"
+	"yuhen/internal/source"
+)
+
+func main() {
+	gcflags := flag.String("gcflags", "", "extra compiler flags")
+	fn := flag.String("func", "", "only report top-level functions matching `regexp`")
+	oldRev := flag.String("diff", "", "compare against git `revision`")
+	newRev := flag.String("new", "", "with -diff, the newer git `revision` (default: working tree)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: inlinereport [flags] [packages]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("inlinereport: ")
+
+	re, err := regexp.Compile(*fn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	root, err := source.ModuleRoot(".")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	b, err := report(root, *newRev, *gcflags, flag.Args(), re)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *oldRev == "" {
+		fmt.Print(b)
+		return
+	}
+
+	a, err := report(root, *oldRev, *gcflags, flag.Args(), re)
+	if err != nil {
+		log.Fatal(err)
+	}
+	changes := inline.Diff(a, b)
+	for _, c := range changes {
+		fmt.Print(c)
+	}
+	if len(changes) > 0 {
+		os.Exit(1)
+	}
+}
+
+// report 编译版本 rev 的模块，rev 为空时使用工作区，只保留函数名匹配 re 的条目
+func report(root, rev, gcflags string, patterns []string, re *regexp.Regexp) (*inline.Report, error) {
+	dir := root
+	if rev != "" {
+		d, cleanup, err := source.Checkout(root, rev)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		dir = d
+	}
+	r, err := inline.Run(dir, gcflags, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	items := r.Items[:0]
+	for _, it := range r.Items {
+		if re.MatchString(it.Func) {
+			items = append(items, it)
+		}
+	}
+	r.Items = items
+	return r, nil
+}
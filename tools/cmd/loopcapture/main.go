@@ -0,0 +1,18 @@
+// loopcapture 检查循环中逃逸的闭包是否捕获了循环变量
+//
+//	go install -C tools ./cmd/loopcapture
+//	loopcapture ./...
+//	loopcapture -semantics=legacy -fix ./fun
+//
+// 也可以作为 vet 工具: go vet -vettool=$(which loopcapture) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"yuhen/tools/loopcapture"
+)
+
+func main() {
+	singlechecker.Main(loopcapture.Analyzer)
+}
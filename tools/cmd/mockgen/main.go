@@ -0,0 +1,48 @@
+// mockgen 为接口生成 mock：记录调用和参数，逐次预设返回值，按顺序期望，测试结束时检查没有满足的期望
+//
+//	go install -C tools ./cmd/mockgen
+//
+// 在包中用 go generate 调用，工作目录即包目录，生成的代码导入 testing，写到 _test.go 文件：
+//
+//	//go:generate mockgen -o mock_test.go Ner Mer
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"yuhen/tools/mockgen"
+)
+
+func main() {
+	var cfg mockgen.Config
+	out := flag.String("o", "", "output `file`, default stdout")
+	flag.StringVar(&cfg.Package, "pkg", "", "output package `name`, default the package in the current directory")
+	flag.StringVar(&cfg.Name, "name", "Mock%s", "mock type name `template`")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: mockgen [flags] interface...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	log.SetFlags(0) // 库返回的错误已经带有 mockgen: 前缀
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg.Output = *out
+	src, err := mockgen.Generate(".", cfg, flag.Args()...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatalf("mockgen: %v", err)
+	}
+}
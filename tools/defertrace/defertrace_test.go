@@ -0,0 +1,150 @@
+package defertrace
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func trace(t *testing.T, dir, fn string) string {
+	t.Helper()
+	tl, err := Run(dir, fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tl.String()
+}
+
+const lesson = "../../fun/defer_call.go"
+
+// line 返回 lesson 中函数 fn 里第一个包含 text 的行号，课程文件改动后不必改测试
+func line(t *testing.T, fn, text string) int {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, lesson, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd := funcDecl(f, fn)
+	if fd == nil {
+		t.Fatalf("%s not found in %s", fn, lesson)
+	}
+	src, err := os.ReadFile(lesson)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(src), "\n")
+	for n := fset.Position(fd.Pos()).Line; n <= fset.Position(fd.End()).Line; n++ {
+		if strings.Contains(lines[n-1], text) {
+			return n
+		}
+	}
+	t.Fatalf("%q not found in %s", text, fn)
+	return 0
+}
+
+func check(t *testing.T, got, want string) {
+	t.Helper()
+	if got != want {
+		t.Fatalf("timeline:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// bibao1 具名返回值 z，return 100 先写入 z，延迟调用再修改 z
+func TestBibao1(t *testing.T) {
+	check(t, trace(t, "../../fun", "bibao1"), fmt.Sprintf(`bibao1:
+  register #1 line %d: defer func() { z += 200 }()
+  return line %d: z=100
+  before #1: z=100
+  after #1: z=300
+  final: ~r0=300
+`, line(t, "bibao1", "defer func"), line(t, "bibao1", "return")))
+}
+
+// bibao2 的 z 是局部变量，返回值在 return 时已经确定
+func TestBibao2(t *testing.T) {
+	check(t, trace(t, "../../fun", "bibao2"), fmt.Sprintf(`bibao2:
+  register #1 line %d: defer func() { z += 200 }()
+  return line %d: ~r0=100
+  before #1: ~r0=100
+  after #1: ~r0=100
+  final: ~r0=100
+`, line(t, "bibao2", "defer func"), line(t, "bibao2", "return")))
+}
+
+// deferCopy 参数在注册时求值，之后 x++ 不影响延迟调用
+func TestDeferCopy(t *testing.T) {
+	got := trace(t, "../../fun", "deferCopy")
+	for _, want := range []string{
+		fmt.Sprintf(`register #1 line %d: defer fmt.Println("defer", x) args=[defer 100]`, line(t, "deferCopy", "defer fmt")),
+		"output: normal 101",
+		"output: defer 100",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("timeline missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestShadowAndRecover(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, src string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("go.mod", "module x\n\ngo 1.20\n")
+	write("x.go", `package x
+
+func f() (n int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			n = -1
+		}
+	}()
+	for i := 0; i < 2; i++ {
+		defer func(v int) { n += v }(i)
+	}
+	{
+		n := 10 // 遮蔽具名返回值
+		if n > 0 {
+			panic(n)
+		}
+		return n, nil
+	}
+}
+`)
+
+	check(t, trace(t, dir, "f"), `f:
+  register #1 line 4: defer func() { if r := recover(); r != nil { n = -1 } }()
+  register #2 line 10: defer func(v int) { n += v }(i) args=[0]
+  register #2 line 10: defer func(v int) { n += v }(i) args=[1]
+  before #2: n=0 err=<nil>
+  after #2: n=1 err=<nil>
+  before #2: n=1 err=<nil>
+  after #2: n=1 err=<nil>
+  before #1: n=1 err=<nil>
+  after #1: n=-1 err=<nil>
+  final: ~r0=-1 ~r1=<nil>
+`)
+}
+
+// 无类型的参数按形参类型求值，而不是默认类型
+func TestUntypedArgs(t *testing.T) {
+	got := trace(t, "testdata/untyped", "f")
+	for _, want := range []string{
+		"register #1 line 21: defer t.Sleep(1) args=[1ns]",
+		"register #2 line 22: defer show(2, nil, 3*t.Millisecond, nil, x < y, 4, 5) args=[L2 <nil> 3ms <nil> true L4 L5]",
+		"register #3 line 23: defer fmt.Println(\"done\", nil, 'a') args=[done <nil> 97]",
+		"output: done <nil> 97",
+		"output: L2 <nil> 3ms true true [L4 L5]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("timeline missing %q:\n%s", want, got)
+		}
+	}
+}
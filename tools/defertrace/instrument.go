@@ -0,0 +1,365 @@
+// Package defertrace 通过改写源码（go/ast）观察 defer 的执行过程，对应 fun/defer_call.go 里的几个例子
+//
+// 被观察的函数中每条 defer 语句会被改写为：先求值参数并记录（注册），
+// 再在原延迟调用的前后各注册一个记录返回值的 defer。
+// return 语句会先把值写入返回值再记录，最后由生成的 main 记录最终返回值。
+package defertrace
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// 生成代码中的标识符前缀，避免和用户代码冲突
+const prefix = "_dt_"
+
+type instrumenter struct {
+	fset    *token.FileSet
+	info    *types.Info
+	pkg     *types.Package
+	imports map[string]string // 导入路径到文件中的包名
+	names   []string          // 展示用的返回值名称，未命名的为 ~r0 ~r1
+	ptrs    []string          // 指向返回值的指针变量
+	ndefers int
+}
+
+// Instrument 改写 file 中名为 fn 的顶层函数，返回结果个数
+// info 和 pkg 为 file 所在包的类型检查结果，用于确定 defer 参数的类型，可以为 nil
+func Instrument(fset *token.FileSet, info *types.Info, pkg *types.Package, file *ast.File, fn string) (nresults int, err error) {
+	var decl *ast.FuncDecl
+	for _, d := range file.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil && fd.Name.Name == fn {
+			decl = fd
+		}
+	}
+	if decl == nil {
+		return 0, fmt.Errorf("defertrace: function %s not found in %s", fn, fset.Position(file.Package).Filename)
+	}
+	if decl.Body == nil {
+		return 0, fmt.Errorf("defertrace: function %s has no body", fn)
+	}
+	if decl.Type.TypeParams != nil && len(decl.Type.TypeParams.List) > 0 {
+		return 0, fmt.Errorf("defertrace: generic function %s not supported", fn)
+	}
+
+	in := &instrumenter{fset: fset, info: info, pkg: pkg, imports: map[string]string{}}
+	for _, im := range file.Imports {
+		path, _ := strconv.Unquote(im.Path.Value)
+		if im.Name != nil {
+			in.imports[path] = im.Name.Name
+		} else if pkg != nil {
+			for _, p := range pkg.Imports() {
+				if p.Path() == path {
+					in.imports[path] = p.Name()
+				}
+			}
+		}
+	}
+	in.nameResults(decl.Type.Results)
+
+	in.rewriteBlock(decl.Body)
+
+	// 函数开头取返回值地址，之后的记录都通过指针读取，不受内层作用域同名变量影响
+	var head strings.Builder
+	for i, p := range in.ptrs {
+		fmt.Fprintf(&head, "%s := &%s\n_ = %s\n", p, in.resultIdent(decl.Type.Results, i), p)
+	}
+	fmt.Fprintf(&head, "%snames := []string{%s}\n_ = %snames\n", prefix, quoteAll(in.names), prefix)
+	decl.Body.List = append(mustStmts(head.String()), decl.Body.List...)
+	return len(in.names), nil
+}
+
+// nameResults 未命名（或 _）的返回值改为具名，命名本身不改变语义
+func (in *instrumenter) nameResults(results *ast.FieldList) {
+	if results == nil {
+		return
+	}
+	n := 0
+	for _, f := range results.List {
+		if len(f.Names) == 0 {
+			f.Names = []*ast.Ident{ast.NewIdent(fmt.Sprintf("%sr%d", prefix, n))}
+			in.names = append(in.names, fmt.Sprintf("~r%d", n))
+			in.ptrs = append(in.ptrs, fmt.Sprintf("%sp%d", prefix, n))
+			n++
+			continue
+		}
+		for i, id := range f.Names {
+			if id.Name == "_" {
+				f.Names[i] = ast.NewIdent(fmt.Sprintf("%sr%d", prefix, n))
+				in.names = append(in.names, fmt.Sprintf("~r%d", n))
+			} else {
+				in.names = append(in.names, id.Name)
+			}
+			in.ptrs = append(in.ptrs, fmt.Sprintf("%sp%d", prefix, n))
+			n++
+		}
+	}
+}
+
+func (in *instrumenter) resultIdent(results *ast.FieldList, i int) string {
+	for _, f := range results.List {
+		if i < len(f.Names) {
+			return f.Names[i].Name
+		}
+		i -= len(f.Names)
+	}
+	panic("unreachable")
+}
+
+// snapshot 读取当前全部返回值的表达式
+func (in *instrumenter) snapshot() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%snames", prefix)
+	for _, p := range in.ptrs {
+		fmt.Fprintf(&b, ", *%s", p)
+	}
+	return b.String()
+}
+
+func (in *instrumenter) rewriteBlock(b *ast.BlockStmt) {
+	if b != nil {
+		b.List = in.rewriteList(b.List)
+	}
+}
+
+func (in *instrumenter) rewriteList(list []ast.Stmt) []ast.Stmt {
+	for i, s := range list {
+		list[i] = in.rewrite(s)
+	}
+	return list
+}
+
+// rewrite 递归处理语句，不进入闭包：闭包内的 defer 和 return 属于闭包自身
+func (in *instrumenter) rewrite(s ast.Stmt) ast.Stmt {
+	switch s := s.(type) {
+	case *ast.DeferStmt:
+		return in.rewriteDefer(s)
+	case *ast.ReturnStmt:
+		return in.rewriteReturn(s)
+	case *ast.BlockStmt:
+		in.rewriteBlock(s)
+	case *ast.IfStmt:
+		in.rewriteBlock(s.Body)
+		if s.Else != nil {
+			s.Else = in.rewrite(s.Else)
+		}
+	case *ast.ForStmt:
+		in.rewriteBlock(s.Body)
+	case *ast.RangeStmt:
+		in.rewriteBlock(s.Body)
+	case *ast.SwitchStmt:
+		in.rewriteBlock(s.Body)
+	case *ast.TypeSwitchStmt:
+		in.rewriteBlock(s.Body)
+	case *ast.SelectStmt:
+		in.rewriteBlock(s.Body)
+	case *ast.CaseClause:
+		s.Body = in.rewriteList(s.Body)
+	case *ast.CommClause:
+		s.Body = in.rewriteList(s.Body)
+	case *ast.LabeledStmt:
+		s.Stmt = in.rewrite(s.Stmt)
+	}
+	return s
+}
+
+func (in *instrumenter) rewriteDefer(s *ast.DeferStmt) ast.Stmt {
+	in.ndefers++
+	id := in.ndefers
+	line := in.fset.Position(s.Pos()).Line
+	src := strings.Join(strings.Fields(in.source(s.Call)), " ") // 时间线中单行展示
+
+	// 参数在注册时求值一次，既用于记录，也原样传给延迟调用
+	var b strings.Builder
+	b.WriteString("{\n")
+	args := make([]ast.Expr, len(s.Call.Args))
+	for i, a := range s.Call.Args {
+		name := fmt.Sprintf("%sa%d", prefix, i)
+		// 按形参类型声明：无类型的常量和 nil 用 := 会得到默认类型（或无法编译）
+		if t, ok := in.paramType(s.Call, i); ok {
+			fmt.Fprintf(&b, "var %s %s = %s\n", name, t, in.source(a))
+		} else if in.constant(a) {
+			// 写不出形参类型：常量和 nil 不受求值时机影响，原样传入
+			args[i] = a
+			continue
+		} else {
+			fmt.Fprintf(&b, "%s := %s\n", name, in.source(a))
+		}
+		args[i] = ast.NewIdent(name)
+	}
+	fmt.Fprintf(&b, "%sregister(%d, %d, %q", prefix, id, line, src)
+	for _, a := range args {
+		fmt.Fprintf(&b, ", %s", a)
+	}
+	b.WriteString(")\n")
+
+	// 不能把原调用包进闭包：recover 只有被延迟调用直接调用时才生效
+	// 改为前后各注册一个独立的 defer，LIFO 保证执行顺序为 before、原调用、after
+	fmt.Fprintf(&b, "defer func() { %safter(%d, %s) }()\n", prefix, id, in.snapshot())
+	b.WriteString("defer _()\n")
+	fmt.Fprintf(&b, "defer func() { %sbefore(%d, %s) }()\n", prefix, id, in.snapshot())
+	b.WriteString("}")
+
+	block := mustStmts(b.String())[0].(*ast.BlockStmt)
+	s.Call.Args = args
+	block.List[len(block.List)-2] = s
+	return block
+}
+
+// constant a 是常量或 nil
+func (in *instrumenter) constant(a ast.Expr) bool {
+	if in.info == nil {
+		return false
+	}
+	tv := in.info.Types[a]
+	return tv.Value != nil || tv.IsNil()
+}
+
+// paramType 参数 i 对应的形参类型在文件中的写法，没有类型信息或写不出时 ok 为 false
+func (in *instrumenter) paramType(call *ast.CallExpr, i int) (string, bool) {
+	sig, ok := in.signature(call.Fun)
+	if !ok || sig.Params().Len() == 0 {
+		return "", false
+	}
+	params := sig.Params()
+	var t types.Type
+	switch last := params.Len() - 1; {
+	case i < last || !sig.Variadic():
+		if i > last {
+			return "", false
+		}
+		t = params.At(i).Type()
+	case call.Ellipsis.IsValid():
+		t = params.At(last).Type() // f(a, s...) 中的 s 对应切片类型本身
+	default:
+		t = params.At(last).Type().(*types.Slice).Elem()
+	}
+	if n, ok := t.(*types.Named); ok && n.Obj().Pkg() != in.pkg && !n.Obj().Exported() {
+		return "", false // 其他包未导出的类型
+	}
+
+	missing := false
+	s := types.TypeString(t, func(p *types.Package) string {
+		if p == in.pkg {
+			return ""
+		}
+		switch name, ok := in.imports[p.Path()]; {
+		case !ok || name == "_":
+			missing = true // 类型所在的包没有被这个文件导入
+		case name == ".":
+			return ""
+		default:
+			return name
+		}
+		return p.Name()
+	})
+	return s, !missing
+}
+
+// signature 被调用的函数的签名，泛型函数取实例化后的签名
+func (in *instrumenter) signature(fun ast.Expr) (*types.Signature, bool) {
+	if in.info == nil {
+		return nil, false
+	}
+	id, _ := ast.Unparen(fun).(*ast.Ident)
+	if sel, ok := ast.Unparen(fun).(*ast.SelectorExpr); ok {
+		id = sel.Sel
+	}
+	if inst, ok := in.info.Instances[id]; id != nil && ok {
+		sig, ok := inst.Type.(*types.Signature)
+		return sig, ok
+	}
+	t := in.info.TypeOf(fun)
+	if t == nil {
+		return nil, false
+	}
+	sig, ok := t.Underlying().(*types.Signature)
+	return sig, ok
+}
+
+func (in *instrumenter) rewriteReturn(s *ast.ReturnStmt) ast.Stmt {
+	line := in.fset.Position(s.Pos()).Line
+	var b strings.Builder
+	b.WriteString("{\n")
+
+	if len(s.Results) > 0 {
+		derefs := make([]string, len(in.ptrs))
+		exprs := make([]string, len(s.Results))
+		for i, p := range in.ptrs {
+			derefs[i] = "*" + p
+		}
+		for i, e := range s.Results {
+			exprs[i] = in.source(e)
+		}
+		// 右侧先全部求值再赋值，兼容 return f() 这种多返回值的写法
+		fmt.Fprintf(&b, "%s = %s\n", strings.Join(derefs, ", "), strings.Join(exprs, ", "))
+	}
+	fmt.Fprintf(&b, "%sreturn(%d, %s)\n", prefix, line, in.snapshot())
+
+	// 不使用裸 return：内层作用域可能遮蔽了具名返回值
+	derefs := make([]string, len(in.ptrs))
+	for i, p := range in.ptrs {
+		derefs[i] = "*" + p
+	}
+	fmt.Fprintf(&b, "return %s\n}", strings.Join(derefs, ", "))
+	return mustStmts(b.String())[0]
+}
+
+func (in *instrumenter) source(n ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, in.fset, n); err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
+
+func mustStmts(src string) []ast.Stmt {
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package p\nfunc _() {\n"+src+"\n}", 0)
+	if err != nil {
+		panic(fmt.Sprintf("defertrace: generated invalid code: %v\n%s", err, src))
+	}
+	return f.Decls[0].(*ast.FuncDecl).Body.List
+}
+
+func quoteAll(ss []string) string {
+	q := make([]string, len(ss))
+	for i, s := range ss {
+		q[i] = strconv.Quote(s)
+	}
+	return strings.Join(q, ", ")
+}
+
+// printFile 输出改写后的文件，只保留 package 之前的注释（构建约束），
+// 新生成的节点没有位置信息，保留其余注释会让 printer 放错位置
+func printFile(fset *token.FileSet, f *ast.File) ([]byte, error) {
+	var keep []*ast.CommentGroup
+	for _, cg := range f.Comments {
+		if cg.End() < f.Package {
+			keep = append(keep, cg)
+		}
+	}
+	f.Comments = keep
+	f.Doc = nil
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			d.Doc = nil
+		case *ast.GenDecl:
+			d.Doc = nil
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
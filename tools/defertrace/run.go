@@ -0,0 +1,391 @@
+package defertrace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Value 某一时刻的一个返回值
+type Value struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Event 时间线上的一个事件
+//
+//	register 执行到 defer 语句，函数和参数完成求值
+//	return   执行到 return 语句，返回值已写入
+//	before   延迟调用开始前的返回值
+//	after    延迟调用结束后的返回值
+//	final    调用方拿到的返回值
+//	output   被观察函数自身的输出
+type Event struct {
+	Kind    string   `json:"kind"`
+	ID      int      `json:"id,omitempty"`
+	Line    int      `json:"line,omitempty"`
+	Call    string   `json:"call,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Results []Value  `json:"results,omitempty"`
+	Text    string   `json:"text,omitempty"`
+}
+
+func (e Event) String() string {
+	var b strings.Builder
+	switch e.Kind {
+	case "register":
+		fmt.Fprintf(&b, "register #%d line %d: defer %s", e.ID, e.Line, e.Call)
+		if len(e.Args) > 0 {
+			fmt.Fprintf(&b, " args=[%s]", strings.Join(e.Args, " "))
+		}
+		return b.String()
+	case "return":
+		fmt.Fprintf(&b, "return line %d:", e.Line)
+	case "before", "after":
+		fmt.Fprintf(&b, "%s #%d:", e.Kind, e.ID)
+	case "final":
+		b.WriteString("final:")
+	case "output":
+		return "output: " + e.Text
+	}
+	for _, v := range e.Results {
+		fmt.Fprintf(&b, " %s=%s", v.Name, v.Value)
+	}
+	return b.String()
+}
+
+// Timeline 一次调用的完整时间线
+type Timeline struct {
+	Func   string
+	Events []Event
+}
+
+func (t *Timeline) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:\n", t.Func)
+	for _, e := range t.Events {
+		fmt.Fprintf(&b, "  %s\n", e)
+	}
+	return b.String()
+}
+
+// 事件行的前缀，和函数自身的输出区分
+const marker = "\x1edefertrace\t"
+
+// Run 改写 dir 包中的函数 fn（必须无参数），在临时 main 包中执行并返回时间线
+// 改写后的文件写在系统临时目录，通过 -overlay 映射到 dir 所在模块根目录下的 _defertrace，
+// 这样可以正常导入模块内的其他包，并沿用模块的 go 版本（影响循环变量等语义），
+// 模块目录本身不会被写入，只读的模块也能使用
+func Run(dir, fn string) (*Timeline, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	root, err := moduleRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("defertrace: expected one package in %s, found %d", dir, len(pkgs))
+	}
+	var files map[string]*ast.File
+	for _, pkg := range pkgs {
+		files = pkg.Files
+	}
+	tpkg, info, err := typeCheck(fset, dir, files)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "defertrace")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	found, nresults := false, 0
+	for _, pkg := range pkgs {
+		for name, f := range pkg.Files {
+			changed := false
+			f.Name.Name = "main"
+
+			// 原来就是 main 包时，把原 main 改名，由生成的 main 调用目标函数
+			for _, d := range f.Decls {
+				if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil && fd.Name.Name == "main" {
+					fd.Name.Name = prefix + "main"
+					changed = true
+				}
+			}
+			if hasFunc(f, fn) {
+				if nresults, err = Instrument(fset, info, tpkg, f, fn); err != nil {
+					return nil, err
+				}
+				if params := funcDecl(f, fn).Type.Params; params.NumFields() > 0 {
+					return nil, fmt.Errorf("defertrace: function %s must not take parameters", fn)
+				}
+				found, changed = true, true
+			}
+
+			var src []byte
+			if changed {
+				src, err = printFile(fset, f)
+			} else {
+				src, err = os.ReadFile(name)
+				if err == nil {
+					src = renamePackage(src, fset, f)
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(filepath.Join(tmp, filepath.Base(name)), src, 0o644); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("defertrace: function %s not found in %s", fn, dir)
+	}
+
+	// 汇编等非 Go 文件原样复制
+	if err := copyAsm(dir, tmp); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "zz_defertrace.go"), []byte(runtimeSrc(fn, nresults)), 0o644); err != nil {
+		return nil, err
+	}
+
+	overlay, err := writeOverlay(tmp, filepath.Join(root, overlayDir))
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	cmd := exec.Command("go", "run", "-overlay="+overlay, "./"+overlayDir)
+	cmd.Dir = root
+	cmd.Stdout = &out
+	cmd.Stderr = &out // 同一个 writer，println 和 fmt 的输出顺序得以保留
+	runErr := cmd.Run()
+
+	t := &Timeline{Func: fn}
+	sc := bufio.NewScanner(bytes.NewReader(out.Bytes()))
+	for sc.Scan() {
+		line := sc.Text()
+		if !strings.HasPrefix(line, marker) {
+			t.Events = append(t.Events, Event{Kind: "output", Text: line})
+			continue
+		}
+		var e Event
+		if err := json.Unmarshal([]byte(line[len(marker):]), &e); err != nil {
+			return nil, err
+		}
+		t.Events = append(t.Events, e)
+	}
+	if runErr != nil {
+		return t, fmt.Errorf("defertrace: go run: %v\n%s", runErr, out.String())
+	}
+	return t, nil
+}
+
+// overlayDir 临时 main 包在模块中的位置，只存在于 overlay 中
+const overlayDir = "_defertrace"
+
+// writeOverlay 把 tmp 中的文件映射到 dir 下，返回 overlay 文件的路径
+func writeOverlay(tmp, dir string) (string, error) {
+	es, err := os.ReadDir(tmp)
+	if err != nil {
+		return "", err
+	}
+	replace := make(map[string]string)
+	for _, e := range es {
+		replace[filepath.Join(dir, e.Name())] = filepath.Join(tmp, e.Name())
+	}
+	b, err := json.Marshal(struct{ Replace map[string]string }{replace})
+	if err != nil {
+		return "", err
+	}
+	file := filepath.Join(tmp, "overlay.json")
+	return file, os.WriteFile(file, b, 0o644)
+}
+
+// typeCheck 类型检查 dir 中的包，复用已解析的语法树，改写 defer 时据此确定参数的类型
+func typeCheck(fset *token.FileSet, dir string, files map[string]*ast.File) (*types.Package, *types.Info, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir:  dir,
+		Fset: fset,
+		ParseFile: func(fset *token.FileSet, name string, src []byte) (*ast.File, error) {
+			if f, ok := files[name]; ok {
+				return f, nil
+			}
+			return parser.ParseFile(fset, name, src, parser.ParseComments)
+		},
+	}, ".")
+	if err != nil {
+		return nil, nil, fmt.Errorf("defertrace: %v", err)
+	}
+	if len(pkgs) != 1 {
+		return nil, nil, fmt.Errorf("defertrace: expected one package in %s, found %d", dir, len(pkgs))
+	}
+	if p := pkgs[0]; len(p.Errors) > 0 {
+		return nil, nil, fmt.Errorf("defertrace: %v", p.Errors[0])
+	}
+	return pkgs[0].Types, pkgs[0].TypesInfo, nil
+}
+
+func funcDecl(f *ast.File, fn string) *ast.FuncDecl {
+	for _, d := range f.Decls {
+		if fd, ok := d.(*ast.FuncDecl); ok && fd.Recv == nil && fd.Name.Name == fn {
+			return fd
+		}
+	}
+	return nil
+}
+
+func hasFunc(f *ast.File, fn string) bool {
+	return funcDecl(f, fn) != nil
+}
+
+// renamePackage 只替换 package 子句，保留原文件的其他内容（包括注释和构建约束）
+func renamePackage(src []byte, fset *token.FileSet, f *ast.File) []byte {
+	start := fset.Position(f.Name.Pos()).Offset
+	end := fset.Position(f.Name.End()).Offset
+	return append(append(append([]byte{}, src[:start]...), "main"...), src[end:]...)
+}
+
+func copyAsm(dir, tmp string) error {
+	es, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range es {
+		switch filepath.Ext(e.Name()) {
+		case ".s", ".h":
+			b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(tmp, e.Name()), b, 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func moduleRoot(dir string) (string, error) {
+	for d := dir; ; {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d, nil
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", fmt.Errorf("defertrace: no go.mod above %s", dir)
+		}
+		d = parent
+	}
+}
+
+// runtimeSrc 生成记录事件的辅助函数和调用目标函数的 main
+func runtimeSrc(fn string, nresults int) string {
+	rs := make([]string, nresults)
+	for i := range rs {
+		rs[i] = fmt.Sprintf("r%d", i)
+	}
+	call := fn + "()"
+	if nresults > 0 {
+		call = strings.Join(rs, ", ") + " := " + call
+	}
+	final := ""
+	if nresults > 0 {
+		final = ", " + strings.Join(rs, ", ")
+	}
+
+	return fmt.Sprintf(`package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type %[1]sevent struct {
+	Kind    string            `+"`json:\"kind\"`"+`
+	ID      int               `+"`json:\"id,omitempty\"`"+`
+	Line    int               `+"`json:\"line,omitempty\"`"+`
+	Call    string            `+"`json:\"call,omitempty\"`"+`
+	Args    []string          `+"`json:\"args,omitempty\"`"+`
+	Results []%[1]svalue       `+"`json:\"results,omitempty\"`"+`
+}
+
+type %[1]svalue struct {
+	Name  string `+"`json:\"name\"`"+`
+	Value string `+"`json:\"value\"`"+`
+}
+
+func %[1]semit(e %[1]sevent) {
+	b, _ := json.Marshal(e)
+	os.Stdout.Write(append(append([]byte(%[2]q), b...), '\n'))
+}
+
+func %[1]svalues(names []string, vs ...any) []%[1]svalue {
+	out := make([]%[1]svalue, len(vs))
+	for i, v := range vs {
+		out[i] = %[1]svalue{names[i], fmt.Sprint(v)}
+	}
+	return out
+}
+
+func %[1]sregister(id, line int, call string, args ...any) {
+	s := make([]string, len(args))
+	for i, a := range args {
+		s[i] = fmt.Sprint(a)
+	}
+	%[1]semit(%[1]sevent{Kind: "register", ID: id, Line: line, Call: call, Args: s})
+}
+
+func %[1]sreturn(line int, names []string, vs ...any) {
+	%[1]semit(%[1]sevent{Kind: "return", Line: line, Results: %[1]svalues(names, vs...)})
+}
+
+func %[1]sbefore(id int, names []string, vs ...any) {
+	%[1]semit(%[1]sevent{Kind: "before", ID: id, Results: %[1]svalues(names, vs...)})
+}
+
+func %[1]safter(id int, names []string, vs ...any) {
+	%[1]semit(%[1]sevent{Kind: "after", ID: id, Results: %[1]svalues(names, vs...)})
+}
+
+func main() {
+	%[3]s
+	%[1]semit(%[1]sevent{Kind: "final", Results: %[1]svalues(%[1]sfinalNames()%[4]s)})
+}
+`, prefix, marker, call, final) + finalNamesSrc(nresults)
+}
+
+// 最终返回值没有名字，沿用 ~r0 的写法
+func finalNamesSrc(n int) string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("~r%d", i)
+	}
+	return fmt.Sprintf("\nfunc %sfinalNames() []string { return []string{%s} }\n", prefix, quoteAll(names))
+}
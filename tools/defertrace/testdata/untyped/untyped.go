@@ -0,0 +1,24 @@
+package untyped
+
+import (
+	"fmt"
+	t "time"
+)
+
+type level int
+
+func (l level) String() string { return fmt.Sprintf("L%d", int(l)) }
+
+type flag bool
+
+func show(l level, err error, d t.Duration, p *int, f flag, rest ...level) {
+	fmt.Println(l, err, d, p == nil, f, rest)
+}
+
+// 参数为无类型的常量、nil 和比较结果，求值后的类型取决于形参
+func f() {
+	x, y := 1, 2
+	defer t.Sleep(1)
+	defer show(2, nil, 3*t.Millisecond, nil, x < y, 4, 5)
+	defer fmt.Println("done", nil, 'a')
+}
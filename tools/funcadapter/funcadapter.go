@@ -0,0 +1,179 @@
+// Package funcadapter 为接口生成函数适配器，推广 data/interface.go 中的 FuncString
+//
+// 单方法接口生成函数类型，和 http.HandlerFunc 一样：
+//
+//	type StringerFunc func() string
+//	func (f StringerFunc) String() string { return f() }
+//
+// 多方法接口生成由函数字段组成的结构体，每个方法调用对应的字段，适合在测试中只替换用到的方法：
+//
+//	type NerFuncs struct {
+//		AFunc func()
+//		BFunc func(int)
+//		...
+//	}
+//
+// 字段为 nil 时调用对应方法会 panic，并指出缺少哪个方法
+package funcadapter
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"strings"
+
+	"yuhen/tools/internal/gen"
+)
+
+// Config 生成选项
+type Config struct {
+	Package   string // 输出的包名，为空时输出到 dir 中的包；不同时引用该包的类型需要导入
+	Func      string // 单方法接口的类型名模板，%s 为接口名，默认 %sFunc
+	Struct    string // 多方法接口的类型名模板，默认 %sFuncs
+	Generator string // 写入 Code generated by 注释，默认 funcadapter
+	Output    string // 输出文件；在 dir 中时加载包会忽略它原有的内容，过时的适配器可能无法编译
+}
+
+func (c *Config) defaults() {
+	if c.Func == "" {
+		c.Func = "%sFunc"
+	}
+	if c.Struct == "" {
+		c.Struct = "%sFuncs"
+	}
+	if c.Generator == "" {
+		c.Generator = "funcadapter"
+	}
+}
+
+// Generate 为 dir 中的包生成 ifaces 的适配器，返回格式化后的源码
+// 接口名可以是 dir 中的 Ner，也可以是 fmt.Stringer 这样带包路径的名字
+func Generate(dir string, cfg Config, ifaces ...string) ([]byte, error) {
+	cfg.defaults()
+	if len(ifaces) == 0 {
+		return nil, fmt.Errorf("funcadapter: no interfaces")
+	}
+
+	overlay, err := gen.EmptyOutput(cfg.Output)
+	if err != nil {
+		return nil, fmt.Errorf("funcadapter: %w", err)
+	}
+	home, err := gen.Load(dir, ".", overlay)
+	if err != nil {
+		return nil, fmt.Errorf("funcadapter: %w", err)
+	}
+
+	out := home
+	if cfg.Package != "" && cfg.Package != home.Name() {
+		out = nil
+	}
+	g := &generator{cfg: cfg, File: gen.NewFile(out)}
+	var body bytes.Buffer
+	for _, in := range ifaces {
+		tn, err := gen.Lookup(dir, home, in)
+		if err != nil {
+			return nil, fmt.Errorf("funcadapter: %w", err)
+		}
+		if err := g.iface(&body, tn); err != nil {
+			return nil, err
+		}
+	}
+
+	name := cfg.Package
+	if name == "" {
+		name = home.Name()
+	}
+	src, err := g.Source(cfg.Generator, name, body.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("funcadapter: %w", err)
+	}
+	return src, nil
+}
+
+type generator struct {
+	cfg Config
+	*gen.File
+}
+
+func (g *generator) iface(b *bytes.Buffer, tn *types.TypeName) error {
+	named := tn.Type().(*types.Named)
+	if named.TypeParams().Len() > 0 {
+		return fmt.Errorf("funcadapter: generic interface %s is not supported", tn.Name())
+	}
+	it := named.Underlying().(*types.Interface)
+	if it.NumMethods() == 0 {
+		return fmt.Errorf("funcadapter: %s has no methods", tn.Name())
+	}
+	if tn.Pkg() != g.Out {
+		for i := 0; i < it.NumMethods(); i++ {
+			if m := it.Method(i); !m.Exported() {
+				return fmt.Errorf("funcadapter: %s has unexported method %s, generate it in package %s", tn.Name(), m.Name(), tn.Pkg().Name())
+			}
+		}
+	}
+
+	iface := g.TypeString(named)
+	if it.NumMethods() == 1 {
+		g.funcType(b, tn.Name(), iface, it.Method(0))
+		return nil
+	}
+	g.structType(b, tn.Name(), iface, it)
+	return nil
+}
+
+// funcType 单方法接口：函数类型本身实现该方法
+func (g *generator) funcType(b *bytes.Buffer, name, iface string, m *types.Func) {
+	typ := fmt.Sprintf(g.cfg.Func, name)
+	sig := m.Type().(*types.Signature)
+	params, args := g.params(sig)
+
+	fmt.Fprintf(b, "// %s 把函数适配为 %s\n", typ, iface)
+	fmt.Fprintf(b, "type %s %s\n\n", typ, g.Sig(sig))
+	fmt.Fprintf(b, "// %s 调用 f 本身\n", m.Name())
+	fmt.Fprintf(b, "func (f %s) %s(%s)%s {\n", typ, m.Name(), params, g.Results(sig))
+	fmt.Fprintf(b, "\t%sf(%s)\n}\n\n", ret(sig), args)
+	fmt.Fprintf(b, "var _ %s = %s(nil)\n\n", iface, typ)
+}
+
+// structType 多方法接口：每个方法对应一个函数字段
+func (g *generator) structType(b *bytes.Buffer, name, iface string, it *types.Interface) {
+	typ := fmt.Sprintf(g.cfg.Struct, name)
+	fmt.Fprintf(b, "// %s 由函数字段实现 %s，字段为 nil 的方法被调用时 panic\n", typ, iface)
+	fmt.Fprintf(b, "type %s struct {\n", typ)
+	for i := 0; i < it.NumMethods(); i++ {
+		m := it.Method(i)
+		fmt.Fprintf(b, "\t%sFunc %s\n", m.Name(), g.Sig(m.Type().(*types.Signature)))
+	}
+	b.WriteString("}\n\n")
+
+	for i := 0; i < it.NumMethods(); i++ {
+		m := it.Method(i)
+		sig := m.Type().(*types.Signature)
+		params, args := g.params(sig)
+		fmt.Fprintf(b, "// %s 调用 %sFunc\n", m.Name(), m.Name())
+		fmt.Fprintf(b, "func (s *%s) %s(%s)%s {\n", typ, m.Name(), params, g.Results(sig))
+		fmt.Fprintf(b, "\tif s.%sFunc == nil {\n\t\tpanic(%q)\n\t}\n", m.Name(), typ+"."+m.Name()+"Func is nil")
+		fmt.Fprintf(b, "\t%ss.%sFunc(%s)\n}\n\n", ret(sig), m.Name(), args)
+	}
+	fmt.Fprintf(b, "var _ %s = (*%s)(nil)\n\n", iface, typ)
+}
+
+// params 参数列表和调用时的实参
+func (g *generator) params(sig *types.Signature) (params, args string) {
+	ps := g.Params(sig, "f", "s")
+	list, as := make([]string, len(ps)), make([]string, len(ps))
+	for i, p := range ps {
+		list[i], as[i] = p.Name+" "+p.Type, p.Name
+		if p.Variadic {
+			list[i], as[i] = p.Name+" ..."+p.Type, p.Name+"..."
+		}
+	}
+	return strings.Join(list, ", "), strings.Join(as, ", ")
+}
+
+func ret(sig *types.Signature) string {
+	if sig.Results().Len() > 0 {
+		return "return "
+	}
+	return ""
+}
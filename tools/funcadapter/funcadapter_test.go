@@ -0,0 +1,131 @@
+package funcadapter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"yuhen/tools/internal/gen/gentest"
+)
+
+const dataDir = "../../data"
+
+func generate(t *testing.T, cfg Config, ifaces ...string) []byte {
+	t.Helper()
+	src, err := Generate(dataDir, cfg, ifaces...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func TestGolden(t *testing.T) {
+	data := gentest.Load(t, dataDir, ".")[0]
+
+	for _, c := range []struct {
+		name   string
+		cfg    Config
+		ifaces []string
+	}{
+		{"ner", Config{}, []string{"Ner"}},
+		{"x1er", Config{}, []string{"X1er"}}, // 嵌入的 Aer、Ber 和重复声明的 ToString 合并
+		{"stringer", Config{}, []string{"fmt.Stringer"}},
+		// 输出到其他包，自定义名字
+		{"fakes", Config{Package: "fakes", Func: "Fake%s", Struct: "Fake%s"}, []string{"Ner", "fmt.Stringer", "Mer"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			src := generate(t, c.cfg, c.ifaces...)
+			gentest.Golden(t, c.name, src)
+
+			// 同一个包时和 data 的源码一起检查
+			path := data.PkgPath
+			if c.cfg.Package != "" {
+				path = "yuhen/" + c.cfg.Package
+			}
+			if err := gentest.Check(path, src, data); err != nil {
+				t.Errorf("generated code does not type-check: %v\n%s", err, src)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, c := range []struct {
+		cfg    Config
+		ifaces []string
+		want   string
+	}{
+		{Config{}, []string{"Nope"}, "Nope not found"},
+		{Config{}, []string{"L"}, "L is not an interface"},
+		{Config{}, []string{"Empty"}, "not found"},
+		{Config{Package: "fakes"}, []string{"Yer"}, "unexported method test"},
+	} {
+		_, err := Generate(dataDir, c.cfg, c.ifaces...)
+		if err == nil || !strings.Contains(err.Error(), c.want) || strings.Count(err.Error(), "funcadapter:") != 1 {
+			t.Errorf("%v: err = %v, want %q", c.ifaces, err, c.want)
+		}
+	}
+}
+
+// 接口增加方法后，过时的输出文件无法编译，重新生成时忽略它
+func TestRegenerate(t *testing.T) {
+	dir := gentest.Module(t, map[string]string{"x.go": "package x\n\ntype I interface {\n\tA()\n\tB()\n}\n"})
+	out := filepath.Join(dir, "adapter_gen.go")
+	src, err := Generate(dir, Config{Output: out}, "I")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(out, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "x.go"), []byte("package x\n\ntype I interface {\n\tA()\n\tB()\n\tC()\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(dir, Config{}, "I"); err == nil {
+		t.Fatal("stale output loaded without errors")
+	}
+	if src, err = Generate(dir, Config{Output: out}, "I"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "CFunc func()") {
+		t.Errorf("C missing:\n%s", src)
+	}
+	if err := os.WriteFile(out, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gentest.Load(t, dir, ".")
+}
+
+// 生成的参数名 pN 不能和接口中的参数名重复
+func TestParamNames(t *testing.T) {
+	dir := gentest.Module(t, map[string]string{"x.go": `package x
+
+type P interface {
+	M(p1 int, _ string, p2 ...bool)
+}
+
+type Q interface {
+	A(_, p0 int, f func())
+	B(s, _ string) (p1 int)
+}
+`})
+	src, err := Generate(dir, Config{}, "P", "Q")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"func (f PFunc) M(p1 int, p2 string, p3 ...bool)", // 接口中的 p2 让给了前面生成的名字
+		"func (s *QFuncs) A(p0 int, p1 int, p2 func())",
+		"func (s *QFuncs) B(p0 string, p1 string) int",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("missing %q:\n%s", want, src)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "adapter_gen.go"), src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gentest.Load(t, dir, ".")
+}
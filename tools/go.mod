@@ -0,0 +1,16 @@
+module yuhen/tools
+
+go 1.26.0
+
+require (
+	golang.org/x/exp v0.0.0-20230515195305-f3d0a9c9a5cc
+	golang.org/x/tools v0.51.0
+	yuhen v0.0.0
+)
+
+require (
+	golang.org/x/mod v0.41.0 // indirect
+	golang.org/x/sync v0.23.0 // indirect
+)
+
+replace yuhen => ../
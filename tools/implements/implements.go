@@ -0,0 +1,285 @@
+// Package implements 查找实现了某个接口的具名类型，代替 skill() 中手写的 var _ fmt.Stringer = X(0)
+//
+// 除了实现接口的类型（区分 T 和只有 *T 实现），还列出“差一点”实现的类型：
+// 只差一个方法没有、签名不对或者接收者是指针，这类往往是拼错或者改了接口忘了改实现。
+// 可以为实现者生成编译期断言文件，接口改变时编译直接报错
+package implements
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"yuhen/internal/methodset"
+	"yuhen/tools/internal/gen"
+)
+
+// Match 一个具名类型和接口的关系
+type Match struct {
+	Pkg     *types.Package
+	Dir     string // 类型所在包的目录，生成断言文件时使用
+	Name    string
+	Pos     token.Position // 文件名相对于 Find 的 dir
+	Pointer bool           // 只有 *T 实现了接口
+	Why     []string       // T 没有实现的原因；Almost 中为 *T 没有实现的唯一原因
+}
+
+func (m *Match) String() string {
+	name := m.Pkg.Name() + "." + m.Name
+	if m.Pointer {
+		name = "*" + name
+	}
+	s := fmt.Sprintf("%s:%d: %s", m.Pos.Filename, m.Pos.Line, name)
+	if len(m.Why) > 0 {
+		s += " (" + strings.Join(m.Why, "; ") + ")"
+	}
+	return s
+}
+
+// Result 查找结果，按包和类型名排序
+type Result struct {
+	Interface  *types.TypeName
+	Implements []*Match
+	Almost     []*Match
+}
+
+// Find 在 dir 下加载 patterns 匹配的包，查找实现了接口 iface 的类型
+// iface 可以是 Ner（在匹配的包中查找）或者 fmt.Stringer、yuhen/data.Ner 这样带包路径的名字
+func Find(dir, iface string, patterns ...string) (*Result, error) {
+	ifacePath, ifaceName := "", iface
+	if i := strings.LastIndexByte(iface, '.'); i >= 0 {
+		ifacePath, ifaceName = iface[:i], iface[i+1:]
+	}
+
+	// 先只解析 patterns 得到要查找的包，接口所在的包可能只是为了查找接口才加载的
+	roots, err := packages.Load(&packages.Config{Mode: packages.NeedName, Dir: dir}, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	scan := make(map[string]bool)
+	for _, p := range roots {
+		scan[p.PkgPath] = true
+	}
+
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedTypes | packages.NeedImports,
+		Dir:  dir,
+	}
+	load := patterns
+	if ifacePath != "" {
+		// 和待查找的包一起加载，共享同一份 types.Package，接口方法签名中的类型才能比较
+		load = append(append([]string(nil), patterns...), ifacePath)
+	}
+	pkgs, err := packages.Load(cfg, load...)
+	if err != nil {
+		return nil, err
+	}
+	if n := packages.PrintErrors(pkgs); n > 0 {
+		return nil, fmt.Errorf("implements: %d errors loading packages", n)
+	}
+
+	obj, err := lookup(pkgs, ifacePath, ifaceName)
+	if err != nil {
+		return nil, err
+	}
+	it, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, fmt.Errorf("implements: %s is not an interface", iface)
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	r := &Result{Interface: obj}
+	for _, p := range pkgs {
+		if !scan[p.PkgPath] {
+			continue
+		}
+		dir := ""
+		if len(p.GoFiles) > 0 {
+			dir = filepath.Dir(p.GoFiles[0])
+		}
+		scope := p.Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() || tn == obj {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			// 泛型类型需要实例化才能判断，接口类型之间是包含关系，都不在查找范围内
+			if !ok || named.TypeParams().Len() > 0 || types.IsInterface(named) {
+				continue
+			}
+			m := &Match{Pkg: p.Types, Dir: dir, Name: name, Pos: p.Fset.Position(tn.Pos())}
+			if rel, err := filepath.Rel(abs, m.Pos.Filename); err == nil {
+				m.Pos.Filename = rel
+			}
+			qf := types.RelativeTo(p.Types)
+			ptr := methodset.Explain(types.NewPointer(named), it, qf)
+			switch {
+			case len(ptr) == 0:
+				m.Why = methodset.Explain(named, it, qf)
+				m.Pointer = len(m.Why) > 0
+				r.Implements = append(r.Implements, m)
+			case len(ptr) == 1 && shares(named, it):
+				m.Why = ptr
+				r.Almost = append(r.Almost, m)
+			}
+		}
+	}
+	sortMatches(r.Implements)
+	sortMatches(r.Almost)
+	return r, nil
+}
+
+func lookup(pkgs []*packages.Package, path, name string) (*types.TypeName, error) {
+	var found []*types.TypeName
+	seen := make(map[*types.Package]bool)
+	var visit func(p *types.Package)
+	visit = func(p *types.Package) {
+		if seen[p] {
+			return
+		}
+		seen[p] = true
+		if path == "" || p.Path() == path || p.Name() == path {
+			if tn, ok := p.Scope().Lookup(name).(*types.TypeName); ok {
+				found = append(found, tn)
+			}
+		}
+		if path != "" {
+			for _, imp := range p.Imports() {
+				visit(imp)
+			}
+		}
+	}
+	for _, p := range pkgs {
+		visit(p.Types)
+	}
+	switch {
+	case len(found) == 0:
+		return nil, fmt.Errorf("implements: interface %s not found", strings.TrimPrefix(path+"."+name, "."))
+	case len(found) > 1 && path == "":
+		return nil, fmt.Errorf("implements: %s is ambiguous, qualify it with the package path", name)
+	}
+	return found[0], nil
+}
+
+// shares 类型至少有一个和接口同名的方法，否则所有没有方法的类型都“只差一个方法”实现单方法接口
+func shares(t types.Type, it *types.Interface) bool {
+	ms := types.NewMethodSet(types.NewPointer(t))
+	for i := 0; i < it.NumMethods(); i++ {
+		m := it.Method(i)
+		if ms.Lookup(m.Pkg(), m.Name()) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func sortMatches(ms []*Match) {
+	sort.Slice(ms, func(i, j int) bool {
+		if ms[i].Pkg.Path() != ms[j].Pkg.Path() {
+			return ms[i].Pkg.Path() < ms[j].Pkg.Path()
+		}
+		return ms[i].Name < ms[j].Name
+	})
+}
+
+func (r *Result) String() string {
+	var b strings.Builder
+	iface := r.Interface.Pkg().Path() + "." + r.Interface.Name()
+	fmt.Fprintf(&b, "implements %s:\n", iface)
+	for _, m := range r.Implements {
+		fmt.Fprintf(&b, "\t%s\n", m)
+	}
+	if len(r.Almost) > 0 {
+		fmt.Fprintf(&b, "almost implements %s:\n", iface)
+		for _, m := range r.Almost {
+			fmt.Fprintf(&b, "\t%s\n", m)
+		}
+	}
+	return b.String()
+}
+
+// Packages 有实现者的包，按路径排序
+func (r *Result) Packages() []*types.Package {
+	var out []*types.Package
+	seen := make(map[*types.Package]bool)
+	for _, m := range r.Implements {
+		if !seen[m.Pkg] {
+			seen[m.Pkg] = true
+			out = append(out, m.Pkg)
+		}
+	}
+	return out
+}
+
+// Dir 包 pkg 的目录
+func (r *Result) Dir(pkg *types.Package) string {
+	for _, m := range r.Implements {
+		if m.Pkg == pkg {
+			return m.Dir
+		}
+	}
+	return ""
+}
+
+// Assertions 为包 pkg 中的实现者生成断言文件：
+//
+//	var _ fmt.Stringer = X(0)     // T 实现
+//	var _ Ner = (*L)(nil)         // 只有 *T 实现
+func (r *Result) Assertions(pkg *types.Package, generator string) ([]byte, error) {
+	ifacePkg := r.Interface.Pkg()
+	if ifacePkg != pkg && !r.Interface.Exported() {
+		return nil, fmt.Errorf("implements: %s is not exported from %s", r.Interface.Name(), ifacePkg.Path())
+	}
+	f := gen.NewFile(pkg)
+	iface := f.TypeString(r.Interface.Type())
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// %s 的实现者，接口或者实现改变时编译报错\nvar (\n", iface)
+	for _, m := range r.Implements {
+		if m.Pkg != pkg {
+			continue
+		}
+		if m.Pointer {
+			fmt.Fprintf(&b, "\t_ %s = (*%s)(nil)\n", iface, m.Name)
+		} else {
+			fmt.Fprintf(&b, "\t_ %s = %s\n", iface, zero(m))
+		}
+	}
+	b.WriteString(")\n")
+	src, err := f.Source(generator, pkg.Name(), b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("implements: %w", err)
+	}
+	return src, nil
+}
+
+// zero 类型的零值字面量，如 X(0)、T{}、F(nil)
+func zero(m *Match) string {
+	t := m.Pkg.Scope().Lookup(m.Name).Type()
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return m.Name + "(false)"
+		case u.Info()&types.IsString != 0:
+			return m.Name + `("")`
+		case u.Kind() == types.UnsafePointer:
+			return m.Name + "(nil)"
+		}
+		return m.Name + "(0)"
+	case *types.Struct, *types.Array:
+		return m.Name + "{}"
+	}
+	// 指针、切片、map、chan、函数
+	return m.Name + "(nil)"
+}
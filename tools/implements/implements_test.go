@@ -0,0 +1,110 @@
+package implements
+
+import (
+	"strings"
+	"testing"
+
+	"yuhen/tools/internal/gen/gentest"
+)
+
+const root = "../.."
+
+func find(t *testing.T, iface string, patterns ...string) *Result {
+	t.Helper()
+	r, err := Find(root, iface, patterns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func lines(ms []*Match) string {
+	var ss []string
+	for _, m := range ms {
+		s := m.String()
+		ss = append(ss, s[strings.Index(s, " ")+1:]) // 去掉行号，避免 data 中增删代码影响测试
+	}
+	return strings.Join(ss, "\n")
+}
+
+func check(t *testing.T, what, got, want string) {
+	t.Helper()
+	if got != strings.TrimSpace(want) {
+		t.Errorf("%s:\n%s\nwant:\n%s", what, got, strings.TrimSpace(want))
+	}
+}
+
+func TestNer(t *testing.T) {
+	r := find(t, "Ner", "./data")
+	check(t, "implements", lines(r.Implements), `
+*data.L (method B has pointer receiver; method C has pointer receiver)`)
+	if len(r.Almost) != 0 {
+		t.Errorf("almost:\n%s", lines(r.Almost))
+	}
+	if !strings.HasPrefix(r.Implements[0].Pos.Filename, "data/interface.go") {
+		t.Error(r.Implements[0].Pos)
+	}
+}
+
+func TestYer(t *testing.T) {
+	r := find(t, "yuhen/data.Yer", "./data")
+	check(t, "implements", lines(r.Implements), `
+data.N2
+data.U
+*data.Z2 (method test has pointer receiver)`)
+	// N5 的 toString 多了参数，其他的只差一个方法
+	check(t, "almost", lines(r.Almost), `
+data.E (missing method test)
+data.M (missing method toString)
+data.N5 (wrong type for method toString: have func(s string) string, want func() string)
+data.T (missing method test)
+data.Y1 (missing method test)
+data.Z3 (missing method toString)`)
+}
+
+func TestX2er(t *testing.T) {
+	r := find(t, "X2er", "./data")
+	check(t, "implements", lines(r.Implements), `
+*data.N5 (method test has pointer receiver)`)
+	if got := lines(r.Almost); !strings.Contains(got, "data.Z2 (wrong type for method toString: have func() string, want func(string) string)") {
+		t.Errorf("almost:\n%s", got)
+	}
+}
+
+// 接口在其他包中，只在 data 中查找，fmt 本身的类型不算
+func TestStringer(t *testing.T) {
+	r := find(t, "fmt.Stringer", "./data")
+	check(t, "implements", lines(r.Implements), `
+data.FuncString
+data.NGen`)
+
+	if _, err := Find(root, "Nope", "./data"); err == nil {
+		t.Error("unknown interface accepted")
+	}
+	if _, err := Find(root, "L", "./data"); err == nil {
+		t.Error("non-interface accepted")
+	}
+}
+
+// 生成的断言文件和 data 的源码一起能通过类型检查
+func TestAssertions(t *testing.T) {
+	pkg := gentest.Load(t, root, "./data")[0]
+
+	for _, c := range []struct{ iface, want string }{
+		{"Ner", "_ Ner = (*L)(nil)"},
+		{"Yer", "_ Yer = N2(0)"},
+		{"fmt.Stringer", "_ fmt.Stringer = FuncString(nil)"},
+	} {
+		r := find(t, c.iface, "./data")
+		src, err := r.Assertions(r.Packages()[0], "implements")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(src), c.want) || !strings.HasPrefix(string(src), "// Code generated by implements; DO NOT EDIT.") {
+			t.Errorf("%s:\n%s", c.iface, src)
+		}
+		if err := gentest.Check(pkg.PkgPath, src, pkg); err != nil {
+			t.Errorf("%s: %v\n%s", c.iface, err, src)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Package gen funcadapter、mockgen 共用的部分：加载包、查找接口、记录导入和输出格式化的源码
+//
+// 返回的错误不带前缀，由各个工具加上自己的名字
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Load 加载 dir 中匹配 pattern 的一个包，overlay 为替换的文件内容
+func Load(dir, pattern string, overlay map[string][]byte) (*types.Package, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode:    packages.NeedName | packages.NeedTypes | packages.NeedImports,
+		Dir:     dir,
+		Overlay: overlay,
+	}, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if n := packages.PrintErrors(pkgs); n > 0 {
+		return nil, fmt.Errorf("%d errors loading %s", n, pattern)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%s matches %d packages", pattern, len(pkgs))
+	}
+	return pkgs[0].Types, nil
+}
+
+// EmptyOutput 把已存在的输出文件替换为只有 package 子句的空文件，作为 Load 的 overlay：
+// 接口增加方法后，旧文件中的 var _ I = ... 无法编译，不替换就无法重新生成
+func EmptyOutput(file string) (map[string][]byte, error) {
+	if file == "" {
+		return nil, nil
+	}
+	src, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), file, src, parser.PackageClauseOnly)
+	if err != nil {
+		return nil, nil // 不是 Go 源码，照常加载
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{abs: []byte("package " + f.Name.Name + "\n")}, nil
+}
+
+// Lookup 在 home 中查找接口 name；带包路径（或 home 导入的包名）时单独加载该包，
+// home 的导入来自导出数据，只包含 home 用到的声明
+func Lookup(dir string, home *types.Package, name string) (*types.TypeName, error) {
+	pkg, short := home, name
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		path := name[:i]
+		short = name[i+1:]
+		for _, imp := range home.Imports() {
+			if imp.Name() == path {
+				path = imp.Path()
+				break
+			}
+		}
+		var err error
+		if pkg, err = Load(dir, path, nil); err != nil {
+			return nil, err
+		}
+	}
+	tn, ok := pkg.Scope().Lookup(short).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("%s not found", name)
+	}
+	if !types.IsInterface(tn.Type()) {
+		return nil, fmt.Errorf("%s is not an interface", name)
+	}
+	return tn, nil
+}
+
+// File 生成的文件，记录引用到的包
+type File struct {
+	Out     *types.Package    // 输出所在的包，和接口不在同一个包时为 nil
+	imports map[string]string // 导入路径 -> 包名
+}
+
+// NewFile 创建输出到 out 的文件，imports 为总是导入的包路径
+func NewFile(out *types.Package, imports ...string) *File {
+	f := &File{Out: out, imports: make(map[string]string)}
+	for _, p := range imports {
+		f.imports[p] = p[strings.LastIndexByte(p, '/')+1:]
+	}
+	return f
+}
+
+// Qualifier 输出包以外的类型带上包名，并记录导入
+func (f *File) Qualifier(p *types.Package) string {
+	if p == f.Out {
+		return ""
+	}
+	f.imports[p.Path()] = p.Name()
+	return p.Name()
+}
+
+func (f *File) TypeString(t types.Type) string {
+	return types.TypeString(t, f.Qualifier)
+}
+
+// Sig 不含接收者和参数名的函数类型，如 func(string) string
+func (f *File) Sig(sig *types.Signature) string {
+	return f.TypeString(types.NewSignatureType(nil, nil, nil, unnamed(sig.Params()), unnamed(sig.Results()), sig.Variadic()))
+}
+
+// Results 方法声明中的结果列表，带前导空格，如 " (int, error)"
+func (f *File) Results(sig *types.Signature) string {
+	rs := sig.Results()
+	switch rs.Len() {
+	case 0:
+		return ""
+	case 1:
+		return " " + f.TypeString(rs.At(0).Type())
+	}
+	ss := make([]string, rs.Len())
+	for i := range ss {
+		ss[i] = f.TypeString(rs.At(i).Type())
+	}
+	return " (" + strings.Join(ss, ", ") + ")"
+}
+
+// Param 生成的方法中的一个参数，Variadic 时 Type 为元素类型
+type Param struct {
+	Name, Type string
+	Variadic   bool
+}
+
+// Params 参数名沿用接口中的名字，没有名字、是 _ 或者和 reserved 及前面的参数重复时用 p0、p1
+func (f *File) Params(sig *types.Signature, reserved ...string) []Param {
+	used := make(map[string]bool)
+	for _, n := range reserved {
+		used[n] = true
+	}
+	ps := make([]Param, sig.Params().Len())
+	for i := range ps {
+		v := sig.Params().At(i)
+		name := v.Name()
+		for j := i; name == "" || name == "_" || used[name]; j++ {
+			name = fmt.Sprintf("p%d", j) // M(p1 int, _ string) 的第二个参数不能再用 p1
+		}
+		used[name] = true
+		ps[i] = Param{Name: name, Type: f.TypeString(v.Type())}
+		if sig.Variadic() && i == len(ps)-1 {
+			ps[i].Type = f.TypeString(v.Type().(*types.Slice).Elem())
+			ps[i].Variadic = true
+		}
+	}
+	return ps
+}
+
+// Source 加上文件头和导入，格式化 body
+func (f *File) Source(generator, pkg string, body []byte) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by %s; DO NOT EDIT.\n\npackage %s\n\n", generator, pkg)
+	if len(f.imports) > 0 {
+		paths := make([]string, 0, len(f.imports))
+		for p := range f.imports {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		b.WriteString("import (\n")
+		for _, p := range paths {
+			fmt.Fprintf(&b, "\t%q\n", p)
+		}
+		b.WriteString(")\n\n")
+	}
+	b.Write(body)
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%v\n%s", err, b.Bytes())
+	}
+	return src, nil
+}
+
+// Vars t 中的变量
+func Vars(t *types.Tuple) []*types.Var {
+	vs := make([]*types.Var, t.Len())
+	for i := range vs {
+		vs[i] = t.At(i)
+	}
+	return vs
+}
+
+func unnamed(t *types.Tuple) *types.Tuple {
+	vs := Vars(t)
+	for i, v := range vs {
+		vs[i] = types.NewParam(v.Pos(), v.Pkg(), "", v.Type())
+	}
+	return types.NewTuple(vs...)
+}
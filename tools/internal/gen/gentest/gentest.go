@@ -0,0 +1,111 @@
+// Package gentest 代码生成工具的测试辅助：对比 golden 文件，和源码一起类型检查生成的代码
+package gentest
+
+import (
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// Golden 对比生成结果和 testdata/name.golden，-update 时重写
+func Golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	file := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(file, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s differs from the golden file (go test -update to rewrite):\n%s", file, got)
+	}
+}
+
+// Load 加载 dir 中 patterns 匹配的包及其语法树，有错误时测试失败
+// packages.Load 不保证顺序，dir 本身的包排在最前面
+func Load(t *testing.T, dir string, patterns ...string) []*packages.Package {
+	t.Helper()
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedImports,
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 || len(pkgs) != len(patterns) {
+		t.Fatalf("loading %v: %d packages", patterns, len(pkgs))
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range pkgs {
+		if len(p.GoFiles) > 0 && filepath.Dir(p.GoFiles[0]) == abs {
+			pkgs[0], pkgs[i] = pkgs[i], pkgs[0]
+			break
+		}
+	}
+	return pkgs
+}
+
+// Module 在临时目录中创建模块 x，files 为文件名到内容
+func Module(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module x\n\ngo 1.20\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// Check 类型检查生成的 src：path 为 pkgs[0] 时和它的源码一起检查，否则单独成包
+// 导入从 pkgs 及其依赖中解析，和 pkgs 用的是同一份 types.Package
+func Check(path string, src []byte, pkgs ...*packages.Package) error {
+	home := pkgs[0]
+	f, err := parser.ParseFile(home.Fset, "generated.go", src, 0)
+	if err != nil {
+		return err
+	}
+	std := importer.Default()
+	conf := types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+		for _, p := range pkgs {
+			if path == p.PkgPath {
+				return p.Types, nil
+			}
+			if imp := p.Imports[path]; imp != nil {
+				return imp.Types, nil
+			}
+		}
+		return std.Import(path)
+	})}
+	files := []*ast.File{f}
+	if path == home.PkgPath {
+		files = append(home.Syntax[:len(home.Syntax):len(home.Syntax)], f)
+	}
+	_, err = conf.Check(path, home.Fset, files, nil)
+	return err
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
@@ -0,0 +1,382 @@
+// Package loopcapture 检查 fun.test 演示的问题：循环中创建的闭包捕获了循环变量，
+// 并且闭包逃出了本次迭代（append 到切片、go、defer、赋值给循环外的变量、发送到通道），
+// 闭包先赋值给循环体内的变量、再由变量逃出时同样报告
+//
+// Go 1.22 之前循环变量在整个循环中只有一个实例，闭包执行时看到的是最终值；
+// 1.22 开始每次迭代都是新变量。-semantics=auto 时按模块（或文件构建约束）的 go 版本判断
+package loopcapture
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"go/version"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `report closures that capture loop variables and outlive the iteration
+
+Before Go 1.22 a for loop declares its variables once; closures that are
+appended to a slice, started with go, deferred, stored outside the loop or
+sent on a channel observe the final value, including closures first bound
+to a variable declared in the loop body. The suggested fix introduces a
+per-iteration copy; it is omitted when the body of a three-clause loop
+assigns the variable, since the copy would change the iteration.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "loopcapture",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// 可选值: auto legacy go1.22
+var semantics = "auto"
+
+func init() {
+	Analyzer.Flags.StringVar(&semantics, "semantics", semantics,
+		"loop variable semantics: auto (from go version), legacy (shared per loop) or go1.22 (per iteration)")
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	switch semantics {
+	case "auto", "legacy", "go1.22":
+	default:
+		return nil, fmt.Errorf("loopcapture: invalid -semantics=%q", semantics)
+	}
+
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	filter := []ast.Node{(*ast.File)(nil), (*ast.ForStmt)(nil), (*ast.RangeStmt)(nil)}
+
+	perIter := false
+	ins.Preorder(filter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.File:
+			perIter = perIteration(pass, n)
+		case *ast.ForStmt:
+			if !perIter {
+				checkLoop(pass, forVars(n), n.Body, true)
+			}
+		case *ast.RangeStmt:
+			if !perIter {
+				checkLoop(pass, rangeVars(n), n.Body, false)
+			}
+		}
+	})
+	return nil, nil
+}
+
+// perIteration 当前文件是否采用 1.22 的每次迭代新变量语义
+func perIteration(pass *analysis.Pass, f *ast.File) bool {
+	switch semantics {
+	case "legacy":
+		return false
+	case "go1.22":
+		return true
+	}
+
+	// 文件级版本（//go:build go1.22）优先，其次是包（模块 go 指令）的版本
+	v := ""
+	if pass.TypesInfo.FileVersions != nil {
+		v = pass.TypesInfo.FileVersions[f]
+	}
+	if v == "" {
+		v = pass.Pkg.GoVersion()
+	}
+	// 版本未知时按旧语义处理，宁可多报；go1.22rc1 之类取语言版本再比较
+	return v != "" && version.Compare(version.Lang(v), "go1.22") >= 0
+}
+
+func forVars(n *ast.ForStmt) []*ast.Ident {
+	as, ok := n.Init.(*ast.AssignStmt)
+	if !ok || as.Tok != token.DEFINE {
+		return nil
+	}
+	var ids []*ast.Ident
+	for _, e := range as.Lhs {
+		if id, ok := e.(*ast.Ident); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func rangeVars(n *ast.RangeStmt) []*ast.Ident {
+	if n.Tok != token.DEFINE {
+		return nil
+	}
+	var ids []*ast.Ident
+	for _, e := range []ast.Expr{n.Key, n.Value} {
+		if id, ok := e.(*ast.Ident); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// checkLoop forLoop 为三段式 for，条件和后置语句在循环体之后读取循环变量
+func checkLoop(pass *analysis.Pass, ids []*ast.Ident, body *ast.BlockStmt, forLoop bool) {
+	vars := make(map[types.Object]bool)
+	for _, id := range ids {
+		if obj := pass.TypesInfo.Defs[id]; obj != nil && id.Name != "_" {
+			vars[obj] = true
+		}
+	}
+	if len(vars) == 0 || body == nil {
+		return
+	}
+
+	// 循环体内声明的变量，赋值给它们不算逃逸，但要记下赋给它们的闭包，变量逃出时闭包也逃出
+	locals := make(map[types.Object]bool)
+	bound := make(map[types.Object][]*ast.FuncLit)
+	bind := func(lhs, rhs ast.Expr) {
+		id, ok := unparen(lhs).(*ast.Ident)
+		lit, isLit := unparen(rhs).(*ast.FuncLit)
+		if !ok || !isLit {
+			return
+		}
+		// 本语句声明的变量，或者之前在循环体内声明的变量
+		if obj := pass.TypesInfo.Defs[id]; obj != nil {
+			bound[obj] = append(bound[obj], lit)
+		} else if obj := pass.TypesInfo.Uses[id]; locals[obj] {
+			bound[obj] = append(bound[obj], lit)
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			if obj := pass.TypesInfo.Defs[n]; obj != nil {
+				locals[obj] = true
+			}
+		case *ast.AssignStmt:
+			if len(n.Lhs) == len(n.Rhs) {
+				for i := range n.Lhs {
+					bind(n.Lhs[i], n.Rhs[i])
+				}
+			}
+		case *ast.ValueSpec:
+			if len(n.Names) == len(n.Values) {
+				for i, name := range n.Names {
+					bind(name, n.Values[i])
+				}
+			}
+		}
+		return true
+	})
+	// lits 闭包本身，或者循环体内绑定了闭包的变量
+	lits := func(e ast.Expr) []*ast.FuncLit {
+		switch e := unparen(e).(type) {
+		case *ast.FuncLit:
+			return []*ast.FuncLit{e}
+		case *ast.Ident:
+			return bound[pass.TypesInfo.Uses[e]]
+		}
+		return nil
+	}
+
+	// 先收集逃逸的闭包，再统一报告
+	// 同一循环的多个诊断使用同一个修复，避免重复插入 x := x
+	type escape struct {
+		lit      *ast.FuncLit
+		how      string
+		captured []types.Object
+	}
+	var escapes []escape
+	reported := make(map[*ast.FuncLit]bool) // 同一个闭包经变量多次逃出只报告一次
+	report := func(lit *ast.FuncLit, how string) {
+		if reported[lit] {
+			return
+		}
+		reported[lit] = true
+		if captured := capturedVars(pass, lit, vars); len(captured) > 0 {
+			escapes = append(escapes, escape{lit, how, captured})
+		}
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// 闭包内部的语句不属于本次迭代，嵌套循环由外层 Preorder 单独处理
+			return false
+		case *ast.GoStmt:
+			for _, lit := range callLits(n.Call, lits) {
+				report(lit, "started with go")
+			}
+		case *ast.DeferStmt:
+			for _, lit := range callLits(n.Call, lits) {
+				report(lit, "deferred in loop")
+			}
+		case *ast.SendStmt:
+			for _, lit := range lits(n.Value) {
+				report(lit, "sent on channel")
+			}
+		case *ast.CallExpr:
+			if isBuiltin(pass, n.Fun, "append") {
+				for _, arg := range n.Args[1:] {
+					for _, lit := range lits(arg) {
+						report(lit, "appended to slice")
+					}
+				}
+			}
+		case *ast.AssignStmt:
+			if n.Tok != token.ASSIGN || len(n.Lhs) != len(n.Rhs) {
+				break
+			}
+			for i, rhs := range n.Rhs {
+				if assignsLocal(pass, n.Lhs[i], locals) {
+					continue
+				}
+				for _, lit := range lits(rhs) {
+					report(lit, "stored outside loop")
+				}
+			}
+		}
+		return true
+	})
+	if len(escapes) == 0 {
+		return
+	}
+
+	// 修复按循环变量的声明顺序复制所有被捕获的变量
+	need := make(map[types.Object]bool)
+	for _, e := range escapes {
+		for _, v := range e.captured {
+			need[v] = true
+		}
+	}
+	var copies []types.Object
+	for _, id := range ids {
+		if obj := pass.TypesInfo.Defs[id]; need[obj] {
+			copies = append(copies, obj)
+		}
+	}
+	// 三段式 for 的循环体写入复制出的变量后，条件和后置语句看不到这次写入，
+	// 复制会改变循环的行为，只报告不修复；range 每次迭代重新赋值，不受影响
+	var fixes []analysis.SuggestedFix
+	if !forLoop || !writes(pass, body, copies) {
+		fixes = append(fixes, analysis.SuggestedFix{
+			Message:   "copy loop variable per iteration",
+			TextEdits: []analysis.TextEdit{copyEdit(pass, body, copies)},
+		})
+	}
+
+	for _, e := range escapes {
+		names := make([]string, len(e.captured))
+		for i, v := range e.captured {
+			names[i] = v.Name()
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:            e.lit.Pos(),
+			End:            e.lit.End(),
+			Message:        fmt.Sprintf("closure %s captures loop variable %s", e.how, strings.Join(names, ", ")),
+			SuggestedFixes: fixes,
+		})
+	}
+}
+
+// callLits go/defer 语句中的闭包：被调用的闭包本身，以及作为参数传入的闭包
+func callLits(call *ast.CallExpr, lits func(ast.Expr) []*ast.FuncLit) []*ast.FuncLit {
+	out := lits(call.Fun)
+	for _, arg := range call.Args {
+		out = append(out, lits(arg)...)
+	}
+	return out
+}
+
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
+
+func isBuiltin(pass *analysis.Pass, fun ast.Expr, name string) bool {
+	id, ok := unparen(fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	b, ok := pass.TypesInfo.Uses[id].(*types.Builtin)
+	return ok && b.Name() == name
+}
+
+// assignsLocal 赋值目标是否为循环体内声明的普通变量
+func assignsLocal(pass *analysis.Pass, lhs ast.Expr, locals map[types.Object]bool) bool {
+	id, ok := unparen(lhs).(*ast.Ident)
+	if !ok {
+		return false // s[i] = ... / p.f = ... 都会逃出本次迭代
+	}
+	return id.Name == "_" || locals[pass.TypesInfo.Uses[id]]
+}
+
+// writes 循环体（包括其中的闭包）是否给 vars 中的变量赋值、自增自减或取地址
+func writes(pass *analysis.Pass, body *ast.BlockStmt, vars []types.Object) bool {
+	set := make(map[types.Object]bool)
+	for _, v := range vars {
+		set[v] = true
+	}
+	is := func(e ast.Expr) bool {
+		id, ok := unparen(e).(*ast.Ident)
+		return ok && set[pass.TypesInfo.Uses[id]]
+	}
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				found = found || is(lhs)
+			}
+		case *ast.IncDecStmt:
+			found = found || is(n.X)
+		case *ast.UnaryExpr:
+			found = found || (n.Op == token.AND && is(n.X))
+		}
+		return !found
+	})
+	return found
+}
+
+// capturedVars 闭包引用到的循环变量，按首次出现顺序
+func capturedVars(pass *analysis.Pass, lit *ast.FuncLit, vars map[types.Object]bool) []types.Object {
+	var out []types.Object
+	seen := make(map[types.Object]bool)
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		obj := pass.TypesInfo.Uses[id]
+		if vars[obj] && !seen[obj] {
+			seen[obj] = true
+			out = append(out, obj)
+		}
+		return true
+	})
+	return out
+}
+
+// copyEdit 在循环体开头插入 x := x，和 fun.test1 的写法一致
+func copyEdit(pass *analysis.Pass, body *ast.BlockStmt, vars []types.Object) analysis.TextEdit {
+	var b strings.Builder
+	if len(body.List) == 0 {
+		for _, v := range vars {
+			fmt.Fprintf(&b, "\n%s := %s", v.Name(), v.Name())
+		}
+		return analysis.TextEdit{Pos: body.Lbrace + 1, End: body.Lbrace + 1, NewText: []byte(b.String())}
+	}
+
+	// gofmt 之后的代码用 tab 缩进，列号减一即缩进的 tab 数
+	first := body.List[0].Pos()
+	indent := strings.Repeat("\t", pass.Fset.Position(first).Column-1)
+	for _, v := range vars {
+		fmt.Fprintf(&b, "%s := %s\n%s", v.Name(), v.Name(), indent)
+	}
+	return analysis.TextEdit{Pos: first, End: first, NewText: []byte(b.String())}
+}
@@ -0,0 +1,37 @@
+package loopcapture
+
+import (
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func withSemantics(t *testing.T, mode string) {
+	old := semantics
+	semantics = mode
+	t.Cleanup(func() { semantics = old })
+}
+
+func TestLegacy(t *testing.T) {
+	withSemantics(t, "legacy")
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), Analyzer, "a")
+}
+
+// b.go 的构建约束为 go1.22，auto 模式下不报告
+func TestAutoFileVersion(t *testing.T) {
+	withSemantics(t, "auto")
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "b")
+}
+
+// 没有文件级版本时取模块 go 指令的版本
+func TestAutoModuleVersion(t *testing.T) {
+	withSemantics(t, "auto")
+	analysistest.Run(t, filepath.Join(analysistest.TestData(), "go121"), Analyzer, ".")
+	analysistest.Run(t, filepath.Join(analysistest.TestData(), "go122"), Analyzer, ".")
+}
+
+func TestPerIteration(t *testing.T) {
+	withSemantics(t, "go1.22")
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "c")
+}
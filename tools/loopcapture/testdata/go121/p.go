@@ -0,0 +1,11 @@
+package p
+
+// 模块的 go 指令为 1.21，循环变量在所有迭代间共享
+func appended() (s []func()) {
+	for i := 0; i < 2; i++ {
+		s = append(s, func() { // want `closure appended to slice captures loop variable i`
+			println(i)
+		})
+	}
+	return
+}
@@ -0,0 +1,11 @@
+package p
+
+// 模块的 go 指令为 1.22，文件没有构建约束也按每次迭代新变量处理
+func appended() (s []func()) {
+	for i := 0; i < 2; i++ {
+		s = append(s, func() {
+			println(i)
+		})
+	}
+	return
+}
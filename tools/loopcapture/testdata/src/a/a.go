@@ -0,0 +1,92 @@
+package a
+
+func appended() (s []func()) {
+	for i := 0; i < 2; i++ {
+		s = append(s, func() { // want `closure appended to slice captures loop variable i`
+			println(i)
+		})
+	}
+	return
+}
+
+func copied() (s []func()) {
+	for i := 0; i < 2; i++ {
+		x := i
+		s = append(s, func() {
+			println(x)
+		})
+	}
+	return
+}
+
+func goAndDefer(xs []int) {
+	for k, v := range xs {
+		go func() { // want `closure started with go captures loop variable k, v`
+			println(k, v)
+		}()
+		defer func() { // want `closure deferred in loop captures loop variable v`
+			println(v)
+		}()
+	}
+}
+
+func stored(xs []string) {
+	var last func()
+	m := map[string]func(){}
+	ch := make(chan func(), len(xs))
+	for _, x := range xs {
+		last = func() { println(x) } // want `closure stored outside loop captures loop variable x`
+		m[x] = func() { println(x) } // want `closure stored outside loop captures loop variable x`
+		ch <- func() { println(x) }  // want `closure sent on channel captures loop variable x`
+		f := func() { println(x) }
+		f()
+	}
+	last()
+}
+
+func args(xs []int) {
+	for _, x := range xs {
+		go run(func() { println(x) }) // want `closure started with go captures loop variable x`
+		func() { println(x) }()
+	}
+}
+
+func run(f func()) { f() }
+
+// 闭包先赋值给循环体内的变量，再由变量逃出
+func named(xs []int) (s []func()) {
+	var last func()
+	for _, x := range xs {
+		f := func() { println(x) } // want `closure appended to slice captures loop variable x`
+		s = append(s, f, f)
+		var g = func() { println(x) } // want `closure started with go captures loop variable x`
+		go g()
+		var h func()
+		h = func() { println(x) } // want `closure stored outside loop captures loop variable x`
+		last = h
+		call := func() { println(x) }
+		call()
+	}
+	last()
+	return
+}
+
+// 循环体修改了 i，复制后 i++ 写入的是副本，循环不再跳过元素，所以不提供修复
+func written(xs []int) (s []func()) {
+	for i := 0; i < len(xs); i++ {
+		s = append(s, func() { println(i) }) // want `closure appended to slice captures loop variable i`
+		if xs[i] == 0 {
+			i++
+		}
+	}
+	return
+}
+
+// range 每次迭代重新赋值，写入副本不影响迭代，照常修复
+func rangeWritten(xs []int) (s []func()) {
+	for _, x := range xs {
+		x *= 2
+		s = append(s, func() { println(x) }) // want `closure appended to slice captures loop variable x`
+	}
+	return
+}
@@ -0,0 +1,13 @@
+//go:build go1.22
+
+package b
+
+// 文件声明了 go1.22，循环变量每次迭代都是新的，不需要报告
+func appended() (s []func()) {
+	for i := 0; i < 2; i++ {
+		s = append(s, func() {
+			println(i)
+		})
+	}
+	return
+}
@@ -0,0 +1,11 @@
+package c
+
+// -semantics=go1.22 时即使文件没有声明版本也不报告
+func appended() (s []func()) {
+	for i := 0; i < 2; i++ {
+		s = append(s, func() {
+			println(i)
+		})
+	}
+	return
+}
@@ -0,0 +1,263 @@
+// Package mockgen 为接口生成 mock，运行时在 yuhen/internal/mock
+//
+// 对接口 Ner 生成：
+//
+//	type MockNer struct{ ... }                  // 实现 Ner，记录每次调用
+//	func NewMockNer(tb testing.TB) *MockNer      // tb.Cleanup 时检查没有满足的期望
+//	func (m *MockNer) ExpectC(p0 any) MockNerCCall
+//	func (c MockNerCCall) Return(r0 string) MockNerCCall
+//
+// Expect 的参数是 any，可以传 mock.Any 等 Matcher；Return、Do 是有类型的，写错类型编译报错。
+// 泛型接口生成泛型 mock；Tester 这样带类型集合的约束接口只能用作约束，没有 mock 能实现它
+package mockgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"strings"
+
+	"yuhen/tools/internal/gen"
+)
+
+// Config 生成选项
+type Config struct {
+	Package   string // 输出的包名，为空时输出到 dir 中的包
+	Name      string // mock 类型名模板，%s 为接口名，默认 Mock%s
+	Generator string // 写入 Code generated by 注释，默认 mockgen
+	Output    string // 输出文件；在 dir 中时加载包会忽略它原有的内容，过时的 mock 可能无法编译
+}
+
+func (c *Config) defaults() {
+	if c.Name == "" {
+		c.Name = "Mock%s"
+	}
+	if c.Generator == "" {
+		c.Generator = "mockgen"
+	}
+}
+
+// Generate 为 dir 中的包生成 ifaces 的 mock，返回格式化后的源码
+// 生成的代码导入 testing，应该写到 _test.go 文件或者专门的测试辅助包中
+func Generate(dir string, cfg Config, ifaces ...string) ([]byte, error) {
+	cfg.defaults()
+	if len(ifaces) == 0 {
+		return nil, fmt.Errorf("mockgen: no interfaces")
+	}
+
+	overlay, err := gen.EmptyOutput(cfg.Output)
+	if err != nil {
+		return nil, fmt.Errorf("mockgen: %w", err)
+	}
+	home, err := gen.Load(dir, ".", overlay)
+	if err != nil {
+		return nil, fmt.Errorf("mockgen: %w", err)
+	}
+
+	out := home
+	if cfg.Package != "" && cfg.Package != home.Name() {
+		out = nil
+	}
+	g := &generator{cfg: cfg, File: gen.NewFile(out, "testing", "yuhen/internal/mock")}
+	var body bytes.Buffer
+	for _, in := range ifaces {
+		tn, err := gen.Lookup(dir, home, in)
+		if err != nil {
+			return nil, fmt.Errorf("mockgen: %w", err)
+		}
+		if err := g.mock(&body, tn); err != nil {
+			return nil, err
+		}
+	}
+
+	name := cfg.Package
+	if name == "" {
+		name = home.Name()
+	}
+	src, err := g.Source(cfg.Generator, name, body.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("mockgen: %w", err)
+	}
+	return src, nil
+}
+
+type generator struct {
+	cfg Config
+	*gen.File
+}
+
+// mock 为一个接口生成 mock 类型、构造函数、方法以及每个方法的期望类型
+func (g *generator) mock(b *bytes.Buffer, tn *types.TypeName) error {
+	named := tn.Type().(*types.Named)
+	it := named.Underlying().(*types.Interface)
+	if !it.IsMethodSet() {
+		return fmt.Errorf("mockgen: %s is a type constraint (%s), no mock can be in its type set", tn.Name(), g.TypeString(it))
+	}
+	if it.NumMethods() == 0 {
+		return fmt.Errorf("mockgen: %s has no methods", tn.Name())
+	}
+	typ := fmt.Sprintf(g.cfg.Name, tn.Name())
+	reserved := map[string]bool{"Calls": true}
+	for i := 0; i < it.NumMethods(); i++ {
+		reserved["Expect"+it.Method(i).Name()] = true
+	}
+	for i := 0; i < it.NumMethods(); i++ {
+		m := it.Method(i)
+		if tn.Pkg() != g.Out && !m.Exported() {
+			return fmt.Errorf("mockgen: %s has unexported method %s, generate it in package %s", tn.Name(), m.Name(), tn.Pkg().Name())
+		}
+		if reserved[m.Name()] {
+			return fmt.Errorf("mockgen: method %s.%s conflicts with the generated %s.%s", tn.Name(), m.Name(), typ, m.Name())
+		}
+	}
+
+	// 泛型接口：[K comparable, V any] 用于声明，[K, V] 用于引用
+	var decl, use string
+	tparams := map[string]bool{}
+	if tps := named.TypeParams(); tps.Len() > 0 {
+		ds, us := make([]string, tps.Len()), make([]string, tps.Len())
+		for i := range ds {
+			tp := tps.At(i)
+			ds[i] = tp.Obj().Name() + " " + g.TypeString(tp.Constraint())
+			us[i] = tp.Obj().Name()
+			tparams[us[i]] = true
+		}
+		decl, use = "["+strings.Join(ds, ", ")+"]", "["+strings.Join(us, ", ")+"]"
+	}
+	iface := tn.Name() + use
+	if q := g.Qualifier(tn.Pkg()); q != "" {
+		iface = q + "." + iface
+	}
+
+	fmt.Fprintf(b, "// %s %s 的 mock\n", typ, iface)
+	fmt.Fprintf(b, "type %s%s struct {\n\tmock *mock.Mock\n}\n\n", typ, decl)
+	fmt.Fprintf(b, "// New%s 创建 %s，测试结束时检查没有满足的期望\n", typ, typ)
+	fmt.Fprintf(b, "func New%s%s(tb testing.TB) *%s%s {\n\treturn &%s%s{mock.New(tb, %q)}\n}\n\n", typ, decl, typ, use, typ, use, typ)
+	fmt.Fprintf(b, "// Calls 方法 method 的调用记录，method 为空时返回全部\n")
+	fmt.Fprintf(b, "func (m *%s%s) Calls(method string) []mock.Call {\n\treturn m.mock.Calls(method)\n}\n\n", typ, use)
+
+	for i := 0; i < it.NumMethods(); i++ {
+		g.method(b, typ, decl, use, it.Method(i), tparams)
+	}
+	if decl == "" {
+		fmt.Fprintf(b, "var _ %s = (*%s)(nil)\n\n", iface, typ)
+		return nil
+	}
+	// 泛型没法在包级别断言，用泛型函数对所有类型实参检查
+	fmt.Fprintf(b, "func _%s() {\n\tvar _ %s = (*%s%s)(nil)\n}\n\n", decl, iface, typ, use)
+	return nil
+}
+
+func (g *generator) method(b *bytes.Buffer, typ, decl, use string, m *types.Func, tparams map[string]bool) {
+	sig := m.Type().(*types.Signature)
+	// 参数名不能和生成的代码中的名字、类型参数冲突
+	reserved := []string{"m", "c", "f", "rets", "args", "mock", "testing"}
+	for n := range tparams {
+		reserved = append(reserved, n)
+	}
+	ps := g.Params(sig, reserved...)
+	call := fmt.Sprintf("%s%sCall", typ, m.Name())
+
+	// 实现接口的方法
+	var list, args []string
+	fixed := []string{fmt.Sprintf("%q", m.Name())}
+	rest := ""
+	for _, p := range ps {
+		if p.Variadic {
+			list = append(list, p.Name+" ..."+p.Type)
+			rest = p.Name
+			continue
+		}
+		list = append(list, p.Name+" "+p.Type)
+		args = append(args, p.Name)
+	}
+	callArgs := strings.Join(append(fixed, args...), ", ")
+	if rest != "" {
+		callArgs = fmt.Sprintf("%q, mock.Append([]any{%s}, %s)...", m.Name(), strings.Join(args, ", "), rest)
+	}
+	results := g.Results(sig)
+	fmt.Fprintf(b, "// %s 记录调用，返回匹配的期望设置的值\n", m.Name())
+	fmt.Fprintf(b, "func (m *%s%s) %s(%s)%s {\n", typ, use, m.Name(), strings.Join(list, ", "), results)
+	if sig.Results().Len() == 0 {
+		fmt.Fprintf(b, "\tm.mock.Call(%s)\n}\n\n", callArgs)
+	} else {
+		fmt.Fprintf(b, "\trets := m.mock.Call(%s)\n\treturn %s\n}\n\n", callArgs, g.ats(sig.Results(), "rets"))
+	}
+
+	// Expect：参数为 any，可以是 Matcher
+	var eps, eargs []string
+	for _, p := range ps {
+		if p.Variadic {
+			eps = append(eps, p.Name+" ...any")
+		} else {
+			eps = append(eps, p.Name+" any")
+			eargs = append(eargs, p.Name)
+		}
+	}
+	expectArgs := strings.Join(append(fixed, eargs...), ", ")
+	if rest != "" {
+		expectArgs = fmt.Sprintf("%q, append([]any{%s}, %s...)...", m.Name(), strings.Join(eargs, ", "), rest)
+		if len(eargs) == 0 {
+			expectArgs = fmt.Sprintf("%q, %s...", m.Name(), rest)
+		}
+	}
+	fmt.Fprintf(b, "// Expect%s 期望调用 %s，参数可以是值或者 mock.Matcher\n", m.Name(), m.Name())
+	fmt.Fprintf(b, "func (m *%s%s) Expect%s(%s) %s%s {\n\treturn %s%s{m.mock.Expect(%s)}\n}\n\n",
+		typ, use, m.Name(), strings.Join(eps, ", "), call, use, call, use, expectArgs)
+
+	// 期望类型
+	fmt.Fprintf(b, "// %s %s 的期望\n", call, m.Name())
+	fmt.Fprintf(b, "type %s%s struct {\n\t*mock.Expect\n}\n\n", call, decl)
+
+	if rs := sig.Results(); rs.Len() > 0 {
+		rps, rvs := make([]string, rs.Len()), make([]string, rs.Len())
+		for i := range rps {
+			rvs[i] = fmt.Sprintf("r%d", i)
+			rps[i] = rvs[i] + " " + g.TypeString(rs.At(i).Type())
+		}
+		fmt.Fprintf(b, "// Return 设置返回值\n")
+		fmt.Fprintf(b, "func (c %s%s) Return(%s) %s%s {\n\tc.Expect.Return(%s)\n\treturn c\n}\n\n",
+			call, use, strings.Join(rps, ", "), call, use, strings.Join(rvs, ", "))
+	}
+
+	fargs := g.ats(sig.Params(), "args")
+	if rest != "" {
+		last := sig.Params().Len() - 1
+		fargs = g.ats(types.NewTuple(gen.Vars(sig.Params())[:last]...), "args")
+		if fargs != "" {
+			fargs += ", "
+		}
+		fargs += fmt.Sprintf("mock.Rest[%s](args, %d)...", ps[last].Type, last)
+	}
+	fmt.Fprintf(b, "// Do 调用时执行 f，以 f 的结果作为返回值\n")
+	fmt.Fprintf(b, "func (c %s%s) Do(f %s) %s%s {\n", call, use, g.Sig(sig), call, use)
+	if sig.Results().Len() == 0 {
+		fmt.Fprintf(b, "\tc.Expect.Do(func(args []any) []any {\n\t\tf(%s)\n\t\treturn nil\n\t})\n", fargs)
+	} else {
+		fmt.Fprintf(b, "\tc.Expect.Do(func(args []any) []any {\n\t\t%s := f(%s)\n\t\treturn []any{%s}\n\t})\n",
+			strings.Join(resultVars(sig), ", "), fargs, strings.Join(resultVars(sig), ", "))
+	}
+	fmt.Fprintf(b, "\treturn c\n}\n\n")
+
+	fmt.Fprintf(b, "// Times 恰好调用 n 次\n")
+	fmt.Fprintf(b, "func (c %s%s) Times(n int) %s%s {\n\tc.Expect.Times(n)\n\treturn c\n}\n\n", call, use, call, use)
+	fmt.Fprintf(b, "// AnyTimes 调用任意次\n")
+	fmt.Fprintf(b, "func (c %s%s) AnyTimes() %s%s {\n\tc.Expect.AnyTimes()\n\treturn c\n}\n\n", call, use, call, use)
+}
+
+// ats mock.At[T0](vs, 0), mock.At[T1](vs, 1), ...
+func (g *generator) ats(t *types.Tuple, vs string) string {
+	ss := make([]string, t.Len())
+	for i := range ss {
+		ss[i] = fmt.Sprintf("mock.At[%s](%s, %d)", g.TypeString(t.At(i).Type()), vs, i)
+	}
+	return strings.Join(ss, ", ")
+}
+
+func resultVars(sig *types.Signature) []string {
+	vs := make([]string, sig.Results().Len())
+	for i := range vs {
+		vs[i] = fmt.Sprintf("r%d", i)
+	}
+	return vs
+}
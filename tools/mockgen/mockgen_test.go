@@ -0,0 +1,150 @@
+package mockgen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"yuhen/internal/mock"
+	"yuhen/tools/internal/gen/gentest"
+	"yuhen/tools/mockgen/testdata/container"
+	"yuhen/tools/mockgen/testdata/container/mocks"
+)
+
+const (
+	dataDir      = "../../data"
+	containerDir = "testdata/container"
+)
+
+func generate(t *testing.T, dir string, cfg Config, ifaces ...string) []byte {
+	t.Helper()
+	src, err := Generate(dir, cfg, ifaces...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+func TestGolden(t *testing.T) {
+	// mock 运行时和 data 一起加载，生成的代码和运行时用同一份 testing
+	data := gentest.Load(t, dataDir, ".", "yuhen/internal/mock")
+	generic := gentest.Load(t, containerDir, ".", "yuhen/internal/mock")
+
+	for _, c := range []struct {
+		name   string
+		dir    string
+		cfg    Config
+		ifaces []string
+	}{
+		{"ner", dataDir, Config{}, []string{"Ner"}},                  // 嵌入的 Mer.A
+		{"container", containerDir, Config{}, []string{"Container"}}, // 带约束的类型参数，可变参数
+		{"yer", dataDir, Config{}, []string{"Yer"}},                  // 同一个包中可以实现未导出的方法
+		// 输出到其他包，自定义名字；fmt.State 有多个返回值
+		{"mocks", dataDir, Config{Package: "mocks", Name: "Fake%s"}, []string{"Ner", "fmt.State"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			src := generate(t, c.dir, c.cfg, c.ifaces...)
+			gentest.Golden(t, c.name, src)
+
+			// 同一个包时和接口所在包的源码一起检查
+			pkgs := data
+			if c.dir == containerDir {
+				pkgs = generic
+			}
+			path := pkgs[0].PkgPath
+			if c.cfg.Package != "" {
+				path = "yuhen/" + c.cfg.Package
+			}
+			if err := gentest.Check(path, src, pkgs...); err != nil {
+				t.Errorf("generated code does not type-check: %v\n%s", err, src)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, c := range []struct {
+		cfg    Config
+		ifaces []string
+		want   string
+	}{
+		{Config{}, []string{"Nope"}, "Nope not found"},
+		{Config{}, []string{"L"}, "L is not an interface"},
+		{Config{}, []string{"Tester"}, "Tester is a type constraint"},
+		{Config{Package: "mocks"}, []string{"Yer"}, "unexported method test"},
+	} {
+		_, err := Generate(dataDir, c.cfg, c.ifaces...)
+		if err == nil || !strings.Contains(err.Error(), c.want) || strings.Count(err.Error(), "mockgen:") != 1 {
+			t.Errorf("%v: err = %v, want %q", c.ifaces, err, c.want)
+		}
+	}
+}
+
+// go:generate 生成的 mock 没有过期
+func TestGeneratedMocks(t *testing.T) {
+	for _, c := range []struct {
+		dir, file string
+		ifaces    []string
+	}{
+		{dataDir, "mock_test.go", []string{"Ner", "Mer"}},
+		{containerDir, "mocks/mock.go", []string{"Container"}},
+	} {
+		cfg := Config{}
+		if d := filepath.Dir(c.file); d != "." {
+			cfg.Package = d
+		}
+		want := generate(t, c.dir, cfg, c.ifaces...)
+		got, err := os.ReadFile(filepath.Join(c.dir, c.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s/%s is stale, run go generate", c.dir, c.file)
+		}
+	}
+}
+
+func TestDrainMock(t *testing.T) {
+	c := mocks.NewMockContainer[int](t)
+	// 每次调用消耗一个期望，依次返回不同的值，InOrder 要求严格交替
+	mock.InOrder(
+		c.ExpectLen().Return(2),
+		c.ExpectPop().Return(3, true),
+		c.ExpectLen().Return(1),
+		c.ExpectPop().Return(1, true),
+		c.ExpectLen().Return(0),
+	)
+
+	if got := container.Drain[int](c); !reflect.DeepEqual(got, []int{3, 1}) {
+		t.Errorf("Drain = %v", got)
+	}
+	if n := len(c.Calls("Len")); n != 3 {
+		t.Errorf("Len called %d times", n)
+	}
+
+	// 可变参数展开后逐个匹配
+	var pushed []int
+	c.ExpectPush(1, mock.Any).Do(func(vs ...int) { pushed = vs })
+	c.Push(1, 5)
+	if !reflect.DeepEqual(pushed, []int{1, 5}) {
+		t.Errorf("pushed = %v", pushed)
+	}
+}
+
+// 生成的参数名 pN 不能和接口中的参数名、生成代码用到的名字重复
+func TestParamNames(t *testing.T) {
+	const dir = "testdata/params"
+	src, err := Generate(dir, Config{}, "P")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "func (m *MockP) M(p1 int, p2 string, p3 bool, p4 ...int) error"; !strings.Contains(string(src), want) {
+		t.Errorf("missing %q:\n%s", want, src)
+	}
+	pkgs := gentest.Load(t, dir, ".", "yuhen/internal/mock")
+	if err := gentest.Check(pkgs[0].PkgPath, src, pkgs...); err != nil {
+		t.Errorf("generated code does not type-check: %v\n%s", err, src)
+	}
+}
@@ -0,0 +1,28 @@
+// Package container 泛型接口的 mock：类型参数带约束，方法有可变参数
+//
+// 接口本身也可以有类型参数，实例化后是普通的方法集合，实现者随意（包括 mock）；
+// data.Tester 这样带类型集合的约束则只有集合中的类型能满足，mock 不了
+package container
+
+import "golang.org/x/exp/constraints"
+
+//go:generate mockgen -pkg mocks -o mocks/mock.go Container
+
+type Container[T constraints.Ordered] interface {
+	Push(vs ...T)
+	Pop() (T, bool)
+	Len() int
+}
+
+// Drain 依次弹出所有元素
+func Drain[T constraints.Ordered](c Container[T]) []T {
+	var out []T
+	for c.Len() > 0 {
+		v, ok := c.Pop()
+		if !ok {
+			break
+		}
+		out = append(out, v)
+	}
+	return out
+}
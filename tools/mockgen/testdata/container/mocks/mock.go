@@ -0,0 +1,151 @@
+// Code generated by mockgen; DO NOT EDIT.
+
+package mocks
+
+import (
+	"golang.org/x/exp/constraints"
+	"testing"
+	"yuhen/internal/mock"
+	"yuhen/tools/mockgen/testdata/container"
+)
+
+// MockContainer container.Container[T] 的 mock
+type MockContainer[T constraints.Ordered] struct {
+	mock *mock.Mock
+}
+
+// NewMockContainer 创建 MockContainer，测试结束时检查没有满足的期望
+func NewMockContainer[T constraints.Ordered](tb testing.TB) *MockContainer[T] {
+	return &MockContainer[T]{mock.New(tb, "MockContainer")}
+}
+
+// Calls 方法 method 的调用记录，method 为空时返回全部
+func (m *MockContainer[T]) Calls(method string) []mock.Call {
+	return m.mock.Calls(method)
+}
+
+// Len 记录调用，返回匹配的期望设置的值
+func (m *MockContainer[T]) Len() int {
+	rets := m.mock.Call("Len")
+	return mock.At[int](rets, 0)
+}
+
+// ExpectLen 期望调用 Len，参数可以是值或者 mock.Matcher
+func (m *MockContainer[T]) ExpectLen() MockContainerLenCall[T] {
+	return MockContainerLenCall[T]{m.mock.Expect("Len")}
+}
+
+// MockContainerLenCall Len 的期望
+type MockContainerLenCall[T constraints.Ordered] struct {
+	*mock.Expect
+}
+
+// Return 设置返回值
+func (c MockContainerLenCall[T]) Return(r0 int) MockContainerLenCall[T] {
+	c.Expect.Return(r0)
+	return c
+}
+
+// Do 调用时执行 f，以 f 的结果作为返回值
+func (c MockContainerLenCall[T]) Do(f func() int) MockContainerLenCall[T] {
+	c.Expect.Do(func(args []any) []any {
+		r0 := f()
+		return []any{r0}
+	})
+	return c
+}
+
+// Times 恰好调用 n 次
+func (c MockContainerLenCall[T]) Times(n int) MockContainerLenCall[T] {
+	c.Expect.Times(n)
+	return c
+}
+
+// AnyTimes 调用任意次
+func (c MockContainerLenCall[T]) AnyTimes() MockContainerLenCall[T] {
+	c.Expect.AnyTimes()
+	return c
+}
+
+// Pop 记录调用，返回匹配的期望设置的值
+func (m *MockContainer[T]) Pop() (T, bool) {
+	rets := m.mock.Call("Pop")
+	return mock.At[T](rets, 0), mock.At[bool](rets, 1)
+}
+
+// ExpectPop 期望调用 Pop，参数可以是值或者 mock.Matcher
+func (m *MockContainer[T]) ExpectPop() MockContainerPopCall[T] {
+	return MockContainerPopCall[T]{m.mock.Expect("Pop")}
+}
+
+// MockContainerPopCall Pop 的期望
+type MockContainerPopCall[T constraints.Ordered] struct {
+	*mock.Expect
+}
+
+// Return 设置返回值
+func (c MockContainerPopCall[T]) Return(r0 T, r1 bool) MockContainerPopCall[T] {
+	c.Expect.Return(r0, r1)
+	return c
+}
+
+// Do 调用时执行 f，以 f 的结果作为返回值
+func (c MockContainerPopCall[T]) Do(f func() (T, bool)) MockContainerPopCall[T] {
+	c.Expect.Do(func(args []any) []any {
+		r0, r1 := f()
+		return []any{r0, r1}
+	})
+	return c
+}
+
+// Times 恰好调用 n 次
+func (c MockContainerPopCall[T]) Times(n int) MockContainerPopCall[T] {
+	c.Expect.Times(n)
+	return c
+}
+
+// AnyTimes 调用任意次
+func (c MockContainerPopCall[T]) AnyTimes() MockContainerPopCall[T] {
+	c.Expect.AnyTimes()
+	return c
+}
+
+// Push 记录调用，返回匹配的期望设置的值
+func (m *MockContainer[T]) Push(vs ...T) {
+	m.mock.Call("Push", mock.Append([]any{}, vs)...)
+}
+
+// ExpectPush 期望调用 Push，参数可以是值或者 mock.Matcher
+func (m *MockContainer[T]) ExpectPush(vs ...any) MockContainerPushCall[T] {
+	return MockContainerPushCall[T]{m.mock.Expect("Push", vs...)}
+}
+
+// MockContainerPushCall Push 的期望
+type MockContainerPushCall[T constraints.Ordered] struct {
+	*mock.Expect
+}
+
+// Do 调用时执行 f，以 f 的结果作为返回值
+func (c MockContainerPushCall[T]) Do(f func(...T)) MockContainerPushCall[T] {
+	c.Expect.Do(func(args []any) []any {
+		f(mock.Rest[T](args, 0)...)
+		return nil
+	})
+	return c
+}
+
+// Times 恰好调用 n 次
+func (c MockContainerPushCall[T]) Times(n int) MockContainerPushCall[T] {
+	c.Expect.Times(n)
+	return c
+}
+
+// AnyTimes 调用任意次
+func (c MockContainerPushCall[T]) AnyTimes() MockContainerPushCall[T] {
+	c.Expect.AnyTimes()
+	return c
+}
+
+func _[T constraints.Ordered]() {
+	var _ container.Container[T] = (*MockContainer[T])(nil)
+}
@@ -0,0 +1,6 @@
+// Package params 参数名和生成的名字冲突的接口
+package params
+
+type P interface {
+	M(p1 int, _ string, m bool, args ...int) (p0 error)
+}
@@ -0,0 +1,11 @@
+//go:build !purego
+
+package _type
+
+func add(a, b int) int // 汇编函数声明
+func sub(a, b int) int // 汇编函数声明
+func mul(a, b int) int // 汇编函数声明
+
+func output_o(a, b int) int
+
+func output(int) (int, int, int)
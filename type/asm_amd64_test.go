@@ -0,0 +1,98 @@
+//go:build !purego
+
+package _type
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// 边界值：0 ±1 以及最大最小值，覆盖加减乘的溢出回绕
+var edges = []int{0, 1, -1, 2, -2, math.MaxInt64, math.MinInt64, math.MaxInt64 - 1, math.MinInt64 + 1, math.MaxInt32, math.MinInt32}
+
+func pairs(n int) [][2]int {
+	var ps [][2]int
+	for _, a := range edges {
+		for _, b := range edges {
+			ps = append(ps, [2]int{a, b})
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < n; i++ {
+		ps = append(ps, [2]int{int(r.Uint64()), int(r.Uint64())})
+	}
+	return ps
+}
+
+func TestAsmMatchesGo(t *testing.T) {
+	for _, p := range pairs(10000) {
+		a, b := p[0], p[1]
+		if got, want := add(a, b), addGo(a, b); got != want {
+			t.Fatalf("add(%d, %d) = %d, want %d", a, b, got, want)
+		}
+		if got, want := sub(a, b), subGo(a, b); got != want {
+			t.Fatalf("sub(%d, %d) = %d, want %d", a, b, got, want)
+		}
+		if got, want := mul(a, b), mulGo(a, b); got != want {
+			t.Fatalf("mul(%d, %d) = %d, want %d", a, b, got, want)
+		}
+		if got, want := output_o(a, b), outputOGo(a, b); got != want {
+			t.Fatalf("output_o(%d, %d) = %d, want %d", a, b, got, want)
+		}
+
+		x1, y1, z1 := output(a)
+		x2, y2, z2 := outputGo(a)
+		if x1 != x2 || y1 != y2 || z1 != z2 {
+			t.Fatalf("output(%d) = %d %d %d, want %d %d %d", a, x1, y1, z1, x2, y2, z2)
+		}
+	}
+}
+
+var sink int
+
+//go:noinline
+func addNoinline(a, b int) int { return a + b }
+
+// 汇编函数不能内联，每次都是真实的调用；纯 Go 版本会被内联
+func BenchmarkAdd(b *testing.B) {
+	b.Run("asm", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sink = add(i, sink)
+		}
+	})
+	b.Run("go", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sink = addGo(i, sink)
+		}
+	})
+	b.Run("go-noinline", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sink = addNoinline(i, sink)
+		}
+	})
+}
+
+// output_o 内部再调用 add_o，多一次 ABI0 -> ABIInternal 的转换
+func BenchmarkOutputO(b *testing.B) {
+	b.Run("asm", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sink = output_o(i, sink)
+		}
+	})
+	b.Run("go", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sink = outputOGo(i, sink)
+		}
+	})
+}
+
+// go test -run XXX -bench . ./type/
+/*
+cpu: Intel(R) Xeon(R) Processor
+BenchmarkAdd/asm                339212322                3.346 ns/op
+BenchmarkAdd/go                 383094408                2.953 ns/op
+BenchmarkAdd/go-noinline        349018486                2.999 ns/op
+BenchmarkOutputO/asm            205355374                6.761 ns/op
+BenchmarkOutputO/go             396440331                2.918 ns/op
+*/
@@ -0,0 +1,13 @@
+//go:build !amd64 || purego
+
+package _type
+
+// 没有汇编实现的平台，直接转调纯 Go 版本
+
+func add(a, b int) int { return addGo(a, b) }
+func sub(a, b int) int { return subGo(a, b) }
+func mul(a, b int) int { return mulGo(a, b) }
+
+func output_o(a, b int) int { return outputOGo(a, b) }
+
+func output(x int) (int, int, int) { return outputGo(x) }
@@ -0,0 +1,32 @@
+//go:build matrix
+
+package _type
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// 构建矩阵：各平台以及 purego 标签下都应该能编译通过，并且 go vet 的 asmdecl 只检查实际参与构建的汇编
+// 要把整个模块交叉编译五遍，默认不运行：go test -tags matrix ./type
+func TestBuildMatrix(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+
+	for _, c := range []struct{ goarch, tags string }{
+		{"amd64", ""},
+		{"amd64", "purego"},
+		{"arm64", ""},
+		{"386", ""},
+		{"riscv64", ""},
+	} {
+		cmd := exec.Command("go", "build", "-tags", c.tags, "./...")
+		cmd.Dir = ".."
+		cmd.Env = append(os.Environ(), "GOOS=linux", "GOARCH="+c.goarch, "CGO_ENABLED=0")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("GOARCH=%s tags=%q: %v\n%s", c.goarch, c.tags, err, out)
+		}
+	}
+}
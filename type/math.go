@@ -2,9 +2,8 @@ package _type
 
 import "fmt"
 
-func add(a, b int) int // 汇编函数声明
-func sub(a, b int) int // 汇编函数声明
-func mul(a, b int) int // 汇编函数声明
+// add sub mul 在 amd64 上由 math.s 实现，其他平台或 purego 构建使用 purego.go 中的纯 Go 版本
+// 声明见 asm_amd64.go / asm_other.go
 
 func Main_1() {
 	fmt.Println(add(10, 11))
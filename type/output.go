@@ -6,8 +6,6 @@ func add_o(x, y int) int {
 	return x + y
 }
 
-func output_o(a, b int) int
-
 func Main_output() {
 	s := output_o(10, 13)
 	fmt.Println(s)
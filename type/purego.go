@@ -0,0 +1,15 @@
+package _type
+
+// 汇编函数的纯 Go 实现，任何平台都会编译，差分测试用它和汇编版本对比
+// 溢出行为一致：ADDQ SUBQ IMULQ 取低 64 位，和 Go 的整数回绕相同
+
+func addGo(a, b int) int { return a + b }
+func subGo(a, b int) int { return a - b }
+func mulGo(a, b int) int { return a * b }
+
+// outputOGo 对应 output.s：把参数搬到栈顶后调用 add_o
+func outputOGo(a, b int) int { return add_o(a, b) }
+
+// outputGo 对应 stack_te.s：三个返回值都取自同一个参数
+// 24(SP) 和 perhapsArg1+16(SP) 最终都指向 arg+0(FP)
+func outputGo(x int) (int, int, int) { return x, x, x }